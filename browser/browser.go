@@ -2,20 +2,22 @@ package browser
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"math/rand/v2"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/geo"
 	"github.com/dancnb/sonicradio/model"
 )
 
@@ -24,158 +26,242 @@ const (
 	backupServer      = "https://de1.api.radio-browser.info/json/servers"
 	serverMaxRetry    = 5
 	serverRetryMillis = 200
-	voteTimeout       = 10 * time.Minute
 )
 
 var ErrServerMsg = errors.New("server response not available")
 
+// NewAPI returns an API in offline mode if no server is reachable,
+// or if started with the -offline flag.
 func NewAPI(ctx context.Context, cfg *config.Value) (*API, error) {
-	api := API{
-		cfg:           cfg,
-		stationsCache: make(map[string][]model.Station),
-		stationVotes:  make(map[string]time.Time),
-	}
-	res, err := api.getServers(ctx, HOST)
+	configured, err := cfg.GetAPIServers()
 	if err != nil {
-		msg := fmt.Errorf("could not perform DNS lookup for %q: %w", HOST, err)
-		slog.Error(msg.Error())
-		res, err = api.getServerMirrors()
+		return nil, err
+	}
+	api := &API{
+		ctx:               ctx,
+		cfg:               cfg,
+		client:            cfg.NewHTTPClient(),
+		configured:        configured,
+		servers:           newServerPool(nil),
+		statusCh:          make(chan bool, 1),
+		reconnectInterval: reconnectInterval,
+	}
+	api.cache = newAPICache(cfg.Cache)
+
+	if config.Offline() {
+		slog.Info("browser: offline mode")
+		api.offline.Store(true)
+		return api, nil
+	}
+	if !api.connect(ctx) {
+		api.setOffline()
+	}
+	return api, nil
+}
+
+// connect discovers and probes the servers, reporting whether any is reachable.
+func (a *API) connect(ctx context.Context) bool {
+	var servers []*server
+	if len(a.configured) > 0 {
+		slog.Info("browser configured servers: " + strings.Join(a.configured, "; "))
+		for _, u := range a.configured {
+			servers = append(servers, &server{url: u})
+		}
+	} else {
+		res, err := a.getServers(ctx, HOST)
 		if err != nil {
-			msg := fmt.Errorf("could not retrieve %s servers: %w", HOST, err)
+			msg := fmt.Errorf("could not perform DNS lookup for %q: %w", HOST, err)
 			slog.Error(msg.Error())
+			res, err = a.getServerMirrors(ctx)
+			if err != nil {
+				msg := fmt.Errorf("could not retrieve %s servers: %w", HOST, err)
+				slog.Error(msg.Error())
+			}
+		}
+		for _, host := range res {
+			servers = append(servers, discoveredServer(host))
 		}
 	}
-	slog.Info("browser servers: " + strings.Join(res, "; "))
-	api.servers = append(api.servers, res...)
-
-	if len(api.servers) == 0 {
-		return nil, ErrServerMsg
+	if len(servers) == 0 {
+		return false
 	}
-	return &api, nil
+
+	a.servers.set(servers)
+	a.servers.probe(ctx, a.probeServer)
+	slog.Info("browser servers: " + strings.Join(a.servers.urls(), "; "))
+	return a.servers.healthy()
 }
 
 type API struct {
-	cfg       *config.Value
-	servers   []string
+	ctx        context.Context
+	cfg        *config.Value
+	client     *http.Client
+	configured []string
+	servers    *serverPool
+
+	offline           atomic.Bool
+	statusCh          chan bool
+	reconnectInterval time.Duration
+
 	countries []model.Country
 	langs     []model.Language
 
-	stationsMtx   sync.Mutex
-	stationsCache map[string][]model.Station
-
-	stationVotes map[string]time.Time
+	cache *diskCache
 }
 
-func (a *API) GetLanguages() ([]model.Language, error) {
+func (a *API) GetLanguages(ctx context.Context) ([]model.Language, error) {
 	if len(a.langs) > 0 {
 		return a.langs, nil
 	}
 	log := slog.With("method", "Api.GetLanguages")
-	for range serverMaxRetry {
-		res, err := a.doServerRequest(http.MethodGet, urlLangs, nil)
-		if err != nil {
-			log.Error("", "request error", err)
-			time.Sleep(serverRetryMillis * time.Millisecond)
-			continue
-		}
-		var languages []model.Language
-		err = json.Unmarshal(res, &languages)
-		if err != nil {
-			log.Error("", "unmarshal error", err)
-			log.Error("", "response", string(res))
-			time.Sleep(serverRetryMillis * time.Millisecond)
-			continue
-		}
-		log.Info("", "length", len(languages))
-		a.langs = languages
-		return languages, nil
+	var languages []model.Language
+	err := a.cachedRequest(ctx, cacheLanguages, urlLangs, http.MethodGet, urlLangs, nil, &languages)
+	if err != nil {
+		return nil, fmt.Errorf("Get languages: %w", err)
 	}
-	log.Warn("exceeded max retries")
-	return nil, fmt.Errorf("Get languages: %w", ErrServerMsg)
+	log.Info("", "length", len(languages))
+	a.langs = languages
+	return languages, nil
 }
 
-func (a *API) GetCountries() ([]model.Country, error) {
+func (a *API) GetCountries(ctx context.Context) ([]model.Country, error) {
 	if len(a.countries) > 0 {
 		return a.countries, nil
 	}
 	log := slog.With("method", "Api.GetCountries")
-	for range serverMaxRetry {
-		res, err := a.doServerRequest(http.MethodGet, urlCountries, nil)
+	var countries []model.Country
+	err := a.cachedRequest(ctx, cacheCountries, urlCountries, http.MethodGet, urlCountries, nil, &countries)
+	if err != nil {
+		return nil, fmt.Errorf("Get countries: %w", err)
+	}
+	log.Info("", "length", len(countries))
+	a.countries = countries
+	return countries, nil
+}
+
+// GetTags returns the tags ordered by station count.
+func (a *API) GetTags(ctx context.Context) ([]model.StationTag, error) {
+	log := slog.With("method", "Api.GetTags")
+	var tags []model.StationTag
+	err := a.cachedRequest(ctx, cacheTags, urlTags, http.MethodPost, urlTags, []byte(tagsFormData), &tags)
+	if err != nil {
+		return nil, fmt.Errorf("Get tags: %w", err)
+	}
+	log.Info("", "length", len(tags))
+	return tags, nil
+}
+
+// GetStates returns the states ordered by station count.
+func (a *API) GetStates(ctx context.Context) ([]model.State, error) {
+	log := slog.With("method", "Api.GetStates")
+	var states []model.State
+	err := a.cachedRequest(ctx, cacheStates, urlStates, http.MethodPost, urlStates, []byte(statesFormData), &states)
+	if err != nil {
+		return nil, fmt.Errorf("Get states: %w", err)
+	}
+	log.Info("", "length", len(states))
+	return states, nil
+}
+
+// GetCodecs returns the codecs ordered by station count.
+func (a *API) GetCodecs(ctx context.Context) ([]model.Codec, error) {
+	log := slog.With("method", "Api.GetCodecs")
+	var codecs []model.Codec
+	err := a.cachedRequest(ctx, cacheCodecs, urlCodecs, http.MethodPost, urlCodecs, []byte(codecsFormData), &codecs)
+	if err != nil {
+		return nil, fmt.Errorf("Get codecs: %w", err)
+	}
+	log.Info("", "length", len(codecs))
+	return codecs, nil
+}
+
+// Search returns the stations matching s. A list of codecs is searched one
+// codec at a time and the results are merged, up to limit stations for each
+// codec; such results are not pageable.
+func (a *API) Search(ctx context.Context, s SearchParams) ([]model.Station, error) {
+	codecs := s.codecs()
+	if len(codecs) <= 1 {
+		return a.stationSearch(ctx, s)
+	}
+
+	var res []model.Station
+	seen := make(map[string]bool)
+	for _, c := range codecs {
+		p := s
+		p.Codec = c
+		stations, err := a.stationSearch(ctx, p)
 		if err != nil {
-			log.Error("", "request error", err)
-			time.Sleep(serverRetryMillis * time.Millisecond)
-			continue
+			return nil, err
 		}
-		var countries []model.Country
-		err = json.Unmarshal(res, &countries)
-		if err != nil {
-			log.Error("", "unmarshal error", err)
-			log.Error("", "response", string(res))
-			time.Sleep(serverRetryMillis * time.Millisecond)
-			continue
+		for _, st := range stations {
+			if !seen[st.Stationuuid] {
+				seen[st.Stationuuid] = true
+				res = append(res, st)
+			}
 		}
-		log.Info("", "length", len(countries))
-		a.countries = countries
-		return countries, nil
 	}
-	log.Warn("exceeded max retries")
-	return nil, fmt.Errorf("Get countries: %w", ErrServerMsg)
+	sortStations(res, s.Order, s.Reverse)
+	return res, nil
 }
 
-func (a *API) Search(s SearchParams) ([]model.Station, error) {
-	return a.stationSearch(s)
+// NearbyStations returns the stations within radiusKm of p, closest first.
+func (a *API) NearbyStations(ctx context.Context, p geo.Point, radiusKm int) ([]model.Station, error) {
+	s := DefaultSearchParams()
+	s.Near = &p
+	s.NearRadiusKm = radiusKm
+	s.Limit = nearbyLimit
+	stations, err := a.stationSearch(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stations {
+		lat, long, ok := stations[i].GeoPoint()
+		if !ok {
+			continue
+		}
+		d := geo.DistanceKm(p, geo.Point{Lat: lat, Long: long})
+		stations[i].DistanceKm = &d
+	}
+	slices.SortStableFunc(stations, func(a, b model.Station) int {
+		switch {
+		case a.DistanceKm == nil && b.DistanceKm == nil:
+			return 0
+		case a.DistanceKm == nil:
+			return 1
+		case b.DistanceKm == nil:
+			return -1
+		}
+		return cmp.Compare(*a.DistanceKm, *b.DistanceKm)
+	})
+	return stations, nil
 }
 
-func (a *API) TopStations() ([]model.Station, error) {
+func (a *API) TopStations(ctx context.Context) ([]model.Station, error) {
 	s := DefaultSearchParams()
-	return a.stationSearch(s)
+	return a.stationSearch(ctx, s)
 }
 
-func (a *API) stationSearch(s SearchParams) ([]model.Station, error) {
+func (a *API) stationSearch(ctx context.Context, s SearchParams) ([]model.Station, error) {
 	body := s.toFormData()
 	log := slog.With("method", "Api.stationSearch")
 	log.Info("", "request", body)
 
-	a.stationsMtx.Lock()
-	if v, ok := a.stationsCache[body]; ok && len(v) > 0 {
-		a.stationsMtx.Unlock()
-		log.Info("stations cache hit", "len", len(v))
-		return v, nil
-	}
-	a.stationsMtx.Unlock()
-	log.Info("stations cache miss")
-
+	var stations []model.Station
 	var err error
-	for range serverMaxRetry {
-		var res []byte
-		res, err = a.doServerRequest(http.MethodPost, urlStations, []byte(body))
-		if err != nil {
-			log.Error("", "request error", err)
-			time.Sleep(serverRetryMillis * time.Millisecond)
-			continue
-		}
-		var stations []model.Station
-		err = json.Unmarshal(res, &stations)
-		if err != nil {
-			log.Error("", "unmarshal error", err)
-			log.Error("", "response", string(res))
-			time.Sleep(serverRetryMillis * time.Millisecond)
-			continue
-		}
-		log.Info("", "length", len(stations))
-		if len(stations) > 0 {
-			a.stationsMtx.Lock()
-			a.stationsCache[body] = stations
-			a.stationsMtx.Unlock()
-			log.Info("stations cache set")
-		}
-		return stations, nil
+	if s.Order == Random {
+		// random results are reshuffled on every request
+		err = a.uncachedRequest(ctx, http.MethodPost, urlStations, []byte(body), &stations)
+	} else {
+		err = a.cachedRequest(ctx, cacheSearch, body, http.MethodPost, urlStations, []byte(body), &stations)
 	}
-	log.Warn("exceeded max retries")
-	return nil, fmt.Errorf("Get stations: %w", ErrServerMsg)
+	if err != nil {
+		return nil, fmt.Errorf("Get stations: %w", err)
+	}
+	log.Info("", "length", len(stations))
+	return stations, nil
 }
 
-func (a *API) GetStations(uuids []string) ([]model.Station, error) {
+func (a *API) GetStations(ctx context.Context, uuids []string) ([]model.Station, error) {
 	if len(uuids) == 0 {
 		return nil, nil
 	}
@@ -189,33 +275,90 @@ func (a *API) GetStations(uuids []string) ([]model.Station, error) {
 		}
 	}
 	x := reqBody.String()
+	var stations []model.Station
+	err := a.cachedRequest(ctx, cacheStations, x, http.MethodPost, urlStationsByUUID, []byte(x), &stations)
+	if err != nil {
+		return nil, fmt.Errorf("Get stations: %w", err)
+	}
+	log.Info("", "length", len(stations))
+	return stations, nil
+}
+
+// cachedRequest unmarshals into v the cached response for key if it is
+// still fresh, or else the server response. A stale cached response is
+// used when the servers are unreachable.
+func (a *API) cachedRequest(ctx context.Context, kind cacheKind, key string, method string, path string, body []byte, v any) error {
+	log := slog.With("method", "Api.cachedRequest", "kind", kind)
+
+	cached, fresh, ok := a.cache.get(kind, key)
+	if ok && (fresh || !a.Online()) {
+		if err := json.Unmarshal(cached, v); err == nil {
+			log.Info("cache hit")
+			return nil
+		}
+	}
+
+	if !a.Online() {
+		return ErrOffline
+	}
+	res, err := a.serverRequest(ctx, method, path, body, v)
+	if err == nil {
+		a.cache.put(kind, key, res)
+		return nil
+	}
+	if ok && ctx.Err() == nil {
+		if uErr := json.Unmarshal(cached, v); uErr == nil {
+			log.Warn("serving stale cache entry", "error", err)
+			return nil
+		}
+	}
+	return err
+}
+
+// uncachedRequest unmarshals into v the server response without using the cache.
+func (a *API) uncachedRequest(ctx context.Context, method string, path string, body []byte, v any) error {
+	if !a.Online() {
+		return ErrOffline
+	}
+	_, err := a.serverRequest(ctx, method, path, body, v)
+	return err
+}
+
+// serverRequest unmarshals into v the server response, retrying on errors,
+// and returns the raw response.
+func (a *API) serverRequest(ctx context.Context, method string, path string, body []byte, v any) ([]byte, error) {
+	log := slog.With("method", "Api.serverRequest")
 	for range serverMaxRetry {
-		res, err := a.doServerRequest(http.MethodPost, urlStationsByUUID, []byte(x))
+		res, err := a.doServerRequest(ctx, method, path, body)
 		if err != nil {
 			log.Error("", "request error", err)
-			time.Sleep(serverRetryMillis * time.Millisecond)
+			if err := a.retryWait(ctx); err != nil {
+				return nil, err
+			}
 			continue
 		}
-		var stations []model.Station
-		err = json.Unmarshal(res, &stations)
+		err = json.Unmarshal(res, v)
 		if err != nil {
 			log.Error("", "unmarshal error", err)
 			log.Error("", "response", string(res))
-			time.Sleep(serverRetryMillis * time.Millisecond)
+			if err := a.retryWait(ctx); err != nil {
+				return nil, err
+			}
 			continue
 		}
-		log.Info("", "length", len(stations))
-		return stations, nil
+		return res, nil
 	}
-
 	log.Warn("exceeded max retries")
-	return nil, fmt.Errorf("Get stations: %w", ErrServerMsg)
+	if ctx.Err() == nil && !a.servers.healthy() {
+		a.setOffline()
+	}
+	return nil, ErrServerMsg
 }
 
-func (a *API) StationCounter(uuid string) error {
+func (a *API) StationCounter(ctx context.Context, uuid string) error {
 	log := slog.With("method", "Api.StationCounter")
 	url := urlClickCount + uuid
-	res, err := a.doServerRequest(http.MethodPost, url, nil)
+	res, err := a.doServerRequest(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		log.Error("", "request error", err)
 		return err
@@ -230,19 +373,26 @@ var (
 	errVoteOften   = errors.New("You are voting for the same station too often")
 )
 
-func (a *API) StationVote(uuid string) error {
+func (a *API) StationVote(ctx context.Context, uuid string) error {
 	log := slog.With("method", "Api.StationVote")
+	if !a.Online() {
+		return ErrOffline
+	}
 
-	if voteTime, ok := a.stationVotes[uuid]; ok && time.Now().Before(voteTime.Add(voteTimeout)) {
+	// the cooldown is reserved before the request, so that a second vote
+	// meanwhile is not sent
+	cancelVote, ok := a.cfg.ReserveVote(uuid, time.Now())
+	if !ok {
+		voteTime, _ := a.cfg.LastVote(uuid)
 		log.Info(fmt.Sprintf("already voted %s at %v", uuid, voteTime))
 		return errVoteTimeout
 	}
-	a.stationVotes[uuid] = time.Now()
 
 	url := urlVote + uuid
-	res, err := a.doServerRequest(http.MethodPost, url, nil)
+	res, err := a.doServerRequest(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		log.Error("", "request error", err)
+		cancelVote()
 		return errVoteReq
 	}
 	log.Info(string(res))
@@ -252,18 +402,54 @@ func (a *API) StationVote(uuid string) error {
 	}
 	err = json.Unmarshal(res, &voteRes)
 	if err != nil {
+		cancelVote()
 		return errVoteReq
 	} else if strings.Contains(voteRes.Message, "you are voting for the same station too often") {
+		// the server still counts a previous vote, keep the cooldown
 		return errVoteOften
+	} else if !voteRes.Ok {
+		cancelVote()
+		return fmt.Errorf("%w: %s", errVoteReq, voteRes.Message)
 	}
 	return nil
 }
 
-func (a *API) doServerRequest(method string, path string, body []byte) ([]byte, error) {
-	ix := rand.IntN(len(a.servers))
-	host := a.servers[ix]
-	url := fmt.Sprintf("http://%s%s", host, path)
-	return a.doRequest(method, url, body)
+func (a *API) doServerRequest(ctx context.Context, method string, path string, body []byte) ([]byte, error) {
+	if !a.Online() {
+		return nil, ErrOffline
+	}
+	s, base := a.servers.pick()
+	if s == nil {
+		return nil, ErrOffline
+	}
+	start := time.Now()
+	res, err := a.doRequest(ctx, method, base+path, body)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.servers.failure(s, base, err)
+		}
+		return nil, err
+	}
+	a.servers.success(s, time.Since(start))
+	return res, nil
+}
+
+// retryWait pauses before a retry only when every server is in cooldown,
+// otherwise the next request goes to another server.
+// It returns the context error once the caller gave up.
+func (a *API) retryWait(ctx context.Context) error {
+	if !a.servers.healthy() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(serverRetryMillis * time.Millisecond):
+		}
+	}
+	return ctx.Err()
+}
+
+func (a *API) probeServer(ctx context.Context, base string) error {
+	_, err := a.doRequest(ctx, http.MethodGet, base+urlStats, nil)
+	return err
 }
 
 func (a *API) getServers(ctx context.Context, name string) ([]string, error) {
@@ -278,8 +464,8 @@ func (a *API) getServers(ctx context.Context, name string) ([]string, error) {
 	return res, nil
 }
 
-func (a *API) getServerMirrors() ([]string, error) {
-	res, err := a.doRequest(http.MethodGet, backupServer, nil)
+func (a *API) getServerMirrors(ctx context.Context) ([]string, error) {
+	res, err := a.doRequest(ctx, http.MethodGet, backupServer, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -288,21 +474,25 @@ func (a *API) getServerMirrors() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	var ips []string
+	// the list has an entry for each IPv4 and IPv6 address of a mirror
+	var hosts []string
 	for _, server := range srv {
-		ipVal := net.ParseIP(server.IP)
-		if ipVal != nil && ipVal.To4() != nil {
-			ips = append(ips, server.IP)
+		host := server.Name
+		if host == "" && net.ParseIP(server.IP) != nil {
+			host = server.IP
+		}
+		if host != "" && !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
 		}
 	}
 
-	return ips, err
+	return hosts, err
 }
 
-func (a *API) doRequest(method string, url string, body []byte) ([]byte, error) {
+func (a *API) doRequest(ctx context.Context, method string, url string, body []byte) ([]byte, error) {
 	log := slog.With("method", "Api.doRequest")
 
-	ctx, cancel := context.WithTimeout(context.Background(), config.APIReqTimeout)
+	ctx, cancel := context.WithTimeout(ctx, config.APIReqTimeout)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
@@ -314,12 +504,16 @@ func (a *API) doRequest(method string, url string, body []byte) ([]byte, error)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("User-Agent", ua)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	res, err := http.DefaultClient.Do(req)
+	res, err := a.client.Do(req)
 	if err != nil {
 		log.Error("do browser request", slog.String("error", err.Error()))
 		return nil, err
 	}
 	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode >= http.StatusInternalServerError {
+		log.Error("browser response", slog.String("status", res.Status))
+		return nil, fmt.Errorf("%w: %s", errServerStatus, res.Status)
+	}
 
 	b, err := io.ReadAll(res.Body)
 	if err != nil {
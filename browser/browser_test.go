@@ -2,12 +2,20 @@ package browser
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/geo"
 )
 
 func Test_NewApi(t *testing.T) {
@@ -26,7 +34,7 @@ func Test_topStations(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	res, err := a.TopStations()
+	res, err := a.TopStations(ctx)
 	if err != nil {
 		t.Error(err)
 	}
@@ -48,7 +56,7 @@ func Test_getStation(t *testing.T) {
 		"a06ed3d2-ba59-4969-825d-4e9b3f336b93",
 		"96133c49-0601-11e8-ae97-52543be04c81",
 	}
-	res, err := a.GetStations(uuid)
+	res, err := a.GetStations(ctx, uuid)
 	if err != nil {
 		t.Error(err)
 	}
@@ -73,7 +81,7 @@ func Test_searchStations(t *testing.T) {
 	params.Country = strings.TrimSpace("")
 	params.State = strings.TrimSpace("")
 	params.Language = strings.TrimSpace("")
-	res, err := a.Search(params)
+	res, err := a.Search(ctx, params)
 	if err != nil {
 		t.Error(err)
 	}
@@ -91,7 +99,7 @@ func Test_getCountries(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	res, err := a.GetCountries()
+	res, err := a.GetCountries(ctx)
 	if err != nil {
 		t.Error(err)
 	}
@@ -105,7 +113,7 @@ func TestApi_StationCounter(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = a.StationCounter("748d830c-d934-41e8-bd14-870add931e1d")
+	err = a.StationCounter(ctx, "748d830c-d934-41e8-bd14-870add931e1d")
 	if err != nil {
 		t.Error(err)
 	}
@@ -118,13 +126,319 @@ func TestApi_StationVote(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = a.StationVote("748d830c-d934-41e8-bd14-870add931e1d")
+	err = a.StationVote(ctx, "748d830c-d934-41e8-bd14-870add931e1d")
 	if err != nil {
 		t.Error(err)
 	}
 	time.Sleep(300 * time.Millisecond)
-	err = a.StationVote("748d830c-d934-41e8-bd14-870add931e1d")
+	err = a.StationVote(ctx, "748d830c-d934-41e8-bd14-870add931e1d")
 	if err != errVoteTimeout {
 		t.Error(err)
 	}
 }
+
+func Test_configuredServers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/radio"+urlStations {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"stationuuid":"local-1","name":"LAN radio"}]`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL+"/radio/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.servers.urls(); len(got) != 1 || got[0] != srv.URL+"/radio" {
+		t.Fatalf("servers = %v", got)
+	}
+	res, err := a.TopStations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Stationuuid != "local-1" {
+		t.Errorf("stations = %+v", res)
+	}
+
+	_, err = NewAPI(ctx, testConfig("ftp://mirror"))
+	if err == nil {
+		t.Error("expected an error for an invalid server scheme")
+	}
+}
+
+func Test_searchCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == urlStations {
+			<-release
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	a, err := NewAPI(context.Background(), testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	_, err = a.Search(ctx, DefaultSearchParams())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("search returned after %v", d)
+	}
+	if !a.servers.healthy() {
+		t.Error("cancelled request should not put the server in cooldown")
+	}
+}
+
+// testConfig returns a config using only the given servers, without the disk cache.
+func testConfig(servers ...string) *config.Value {
+	noCache := 0
+	return &config.Value{APIServers: servers, Cache: config.Cache{MaxSizeMB: &noCache}}
+}
+
+func Test_categories(t *testing.T) {
+	var searchForm url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case urlStates:
+			_, _ = w.Write([]byte(`[{"name":"Bavaria","country":"Germany","stationcount":120}]`))
+		case urlCodecs:
+			_, _ = w.Write([]byte(`[{"name":"MP3","stationcount":300},{"name":"AAC","stationcount":90}]`))
+		case urlStations:
+			_ = r.ParseForm()
+			searchForm = r.PostForm
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := a.GetStates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0].Name != "Bavaria" || states[0].Country != "Germany" || states[0].Stationcount != 120 {
+		t.Errorf("states = %+v", states)
+	}
+	codecs, err := a.GetCodecs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codecs) != 2 || codecs[0].Name != "MP3" || codecs[1].Stationcount != 90 {
+		t.Errorf("codecs = %+v", codecs)
+	}
+
+	p := DefaultSearchParams()
+	p.CountryCode = "DE"
+	p.Codec = "AAC+"
+	p.State = "Bavaria"
+	p.StateExact = true
+	p.LanguageExact = true
+	if _, err := a.Search(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{
+		"countrycode":   "DE",
+		"codec":         "AAC+",
+		"state":         "Bavaria",
+		"stateExact":    "true",
+		"languageExact": "true",
+	} {
+		if got := searchForm.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+
+	if _, err := a.Search(ctx, DefaultSearchParams()); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"countrycode", "codec", "stateExact", "languageExact"} {
+		if searchForm.Has(k) {
+			t.Errorf("unexpected %s in default search", k)
+		}
+	}
+}
+
+func Test_searchParams_toFormData(t *testing.T) {
+	p := DefaultSearchParams()
+	p.Name = "  jazz   radio "
+	p.Country = "United States"
+	p.CountryExact = true
+	p.CountryCode = "us"
+	p.BitrateMin = 128
+	p.IsHTTPS = true
+	p.HasGeoInfo = true
+
+	form, err := url.ParseQuery(p.toFormData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{
+		"name":         "jazz radio",
+		"country":      "United States",
+		"countryExact": "true",
+		"countrycode":  "US",
+		"nameExact":    "false",
+		"tagExact":     "true",
+		"bitrateMin":   "128",
+		"bitrateMax":   "",
+		"is_https":     "true",
+		"has_geo_info": "true",
+		"limit":        strconv.Itoa(DefLimit),
+		"order":        string(Votes),
+		"reverse":      "true",
+	} {
+		if got := form.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+	if form.Has("has_extended_info") {
+		t.Error("unexpected has_extended_info")
+	}
+}
+
+func Test_searchParams_Summary(t *testing.T) {
+	p := DefaultSearchParams()
+	if got, want := p.Summary(), "all stations, by votes desc"; got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+
+	p.Name = "jazz"
+	p.TagList = "smooth, lounge"
+	p.Country = "Germany"
+	p.CountryExact = true
+	p.BitrateMin = 128
+	p.IsHTTPS = true
+	p.Order = Bitrate
+	p.Reverse = false
+	want := `name jazz, tags "smooth, lounge", country "Germany", >= 128 kbps, https, by bitrate`
+	if got := p.Summary(); got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+}
+
+func Test_SearchParamsJSON(t *testing.T) {
+	p := DefaultSearchParams()
+	p.Name = "jazz"
+	p.Reverse = false
+	p.TagExact = false
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// decoded over the defaults, as the saved searches are
+	res := DefaultSearchParams()
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res != p {
+		t.Errorf("decoded = %+v, want %+v", res, p)
+	}
+}
+
+func Test_searchCodecs(t *testing.T) {
+	var codecs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != urlStations {
+			return
+		}
+		_ = r.ParseForm()
+		codec := r.PostForm.Get("codec")
+		codecs = append(codecs, codec)
+		switch codec {
+		case "AAC":
+			_, _ = w.Write([]byte(`[{"stationuuid":"a","votes":5},{"stationuuid":"both","votes":20}]`))
+		case "MP3":
+			_, _ = w.Write([]byte(`[{"stationuuid":"both","votes":20},{"stationuuid":"m","votes":10}]`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := DefaultSearchParams()
+	p.Codec = "AAC, MP3"
+	res, err := a.Search(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(codecs, []string{"AAC", "MP3"}) {
+		t.Errorf("searched codecs = %v", codecs)
+	}
+	var uuids []string
+	for _, s := range res {
+		uuids = append(uuids, s.Stationuuid)
+	}
+	if !slices.Equal(uuids, []string{"both", "m", "a"}) {
+		t.Errorf("stations = %v", uuids)
+	}
+	if p.Pageable() {
+		t.Error("multi-codec search is pageable")
+	}
+}
+
+func Test_nearbyStations(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != urlStations {
+			return
+		}
+		_ = r.ParseForm()
+		form = r.PostForm
+		_, _ = w.Write([]byte(`[
+			{"stationuuid":"far","geo_lat":48.1351,"geo_long":11.582},
+			{"stationuuid":"nogeo"},
+			{"stationuuid":"near","geo_lat":52.5,"geo_long":13.4}]`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin := geo.Point{Lat: 52.52, Long: 13.405}
+	res, err := a.NearbyStations(ctx, berlin, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("geo_lat") != "52.52" || form.Get("geo_long") != "13.405" || form.Get("geo_distance") != "50000" {
+		t.Errorf("geo params = %v", form)
+	}
+	var uuids []string
+	for _, s := range res {
+		uuids = append(uuids, s.Stationuuid)
+	}
+	if !slices.Equal(uuids, []string{"near", "far", "nogeo"}) {
+		t.Fatalf("stations = %v", uuids)
+	}
+	if d := res[0].DistanceKm; d == nil || *d > 5 {
+		t.Errorf("near distance = %v", d)
+	}
+	if res[2].DistanceKm != nil {
+		t.Error("distance set for a station without coordinates")
+	}
+}
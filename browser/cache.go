@@ -0,0 +1,159 @@
+package browser
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dancnb/sonicradio/config"
+)
+
+type cacheKind string
+
+const (
+	cacheSearch    cacheKind = "search"
+	cacheStations  cacheKind = "stations"
+	cacheCountries cacheKind = "countries"
+	cacheLanguages cacheKind = "languages"
+	cacheTags      cacheKind = "tags"
+	cacheStates    cacheKind = "states"
+	cacheCodecs    cacheKind = "codecs"
+
+	cacheFileExt = ".json"
+)
+
+// diskCache stores raw API responses, one file per request, using the file
+// modification time as the fetch time. Expired entries are kept to be served
+// while the API is unreachable; the oldest entries are evicted once the
+// cache exceeds its maximum size.
+type diskCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	ttl      map[cacheKind]time.Duration
+	now      func() time.Time
+}
+
+// newAPICache returns the configured cache, or nil if it is disabled.
+func newAPICache(cfg config.Cache) *diskCache {
+	maxBytes := cfg.GetMaxSizeBytes()
+	if maxBytes == 0 {
+		return nil
+	}
+	dir, err := config.CacheDir()
+	if err != nil {
+		slog.Error("cache disabled", "error", err)
+		return nil
+	}
+	return newDiskCache(dir, maxBytes, map[cacheKind]time.Duration{
+		cacheSearch:    cfg.GetSearchTTL(),
+		cacheStations:  cfg.GetStationTTL(),
+		cacheCountries: cfg.GetCountriesTTL(),
+		cacheLanguages: cfg.GetLanguagesTTL(),
+		cacheTags:      cfg.GetTagsTTL(),
+		cacheStates:    cfg.GetStatesTTL(),
+		cacheCodecs:    cfg.GetCodecsTTL(),
+	})
+}
+
+func newDiskCache(dir string, maxBytes int64, ttl map[cacheKind]time.Duration) *diskCache {
+	return &diskCache{dir: dir, maxBytes: maxBytes, ttl: ttl, now: time.Now}
+}
+
+func (c *diskCache) path(kind cacheKind, key string) string {
+	h := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, string(kind)+"-"+hex.EncodeToString(h[:16])+cacheFileExt)
+}
+
+// get returns the cached response and whether it is still within its TTL.
+func (c *diskCache) get(kind cacheKind, key string) (data []byte, fresh bool, ok bool) {
+	if c == nil {
+		return nil, false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fp := c.path(kind, key)
+	fi, err := os.Stat(fp)
+	if err != nil {
+		return nil, false, false
+	}
+	data, err = os.ReadFile(fp)
+	if err != nil {
+		slog.Error("diskCache.get", "error", err)
+		return nil, false, false
+	}
+	fresh = c.now().Sub(fi.ModTime()) < c.ttl[kind]
+	return data, fresh, true
+}
+
+func (c *diskCache) put(kind cacheKind, key string, data []byte) {
+	if c == nil || c.ttl[kind] == 0 {
+		return
+	}
+	log := slog.With("method", "diskCache.put")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fp := c.path(kind, key)
+	tmp := fp + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Error("write", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, fp); err != nil {
+		log.Error("rename", "error", err)
+		_ = os.Remove(tmp)
+		return
+	}
+	now := c.now()
+	_ = os.Chtimes(fp, now, now)
+	c.evictLocked()
+}
+
+// evictLocked removes the least recently fetched entries above the size limit.
+func (c *diskCache) evictLocked() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	var files []fs.FileInfo
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), cacheFileExt) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, fi)
+		total += fi.Size()
+	}
+	if total <= c.maxBytes {
+		return
+	}
+	slices.SortFunc(files, func(a, b fs.FileInfo) int {
+		return cmp.Compare(a.ModTime().UnixNano(), b.ModTime().UnixNano())
+	})
+	for _, fi := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		err := os.Remove(filepath.Join(c.dir, fi.Name()))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Error("diskCache.evict", "error", err)
+			continue
+		}
+		total -= fi.Size()
+	}
+}
@@ -0,0 +1,139 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_diskCache(t *testing.T) {
+	now := time.Now()
+	c := newDiskCache(t.TempDir(), 10, map[cacheKind]time.Duration{cacheSearch: time.Minute})
+	c.now = func() time.Time { return now }
+
+	if _, _, ok := c.get(cacheSearch, "a"); ok {
+		t.Fatal("unexpected entry in empty cache")
+	}
+	c.put(cacheSearch, "a", []byte("aaaa"))
+	if data, fresh, ok := c.get(cacheSearch, "a"); !ok || !fresh || string(data) != "aaaa" {
+		t.Fatalf("get = %q, %v, %v", data, fresh, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if data, fresh, ok := c.get(cacheSearch, "a"); !ok || fresh || string(data) != "aaaa" {
+		t.Errorf("expired get = %q, %v, %v", data, fresh, ok)
+	}
+
+	// kinds without a TTL are not cached
+	c.put(cacheTags, "a", []byte("tags"))
+	if _, _, ok := c.get(cacheTags, "a"); ok {
+		t.Error("cached a kind without TTL")
+	}
+
+	// the oldest entries are evicted above the size limit
+	now = now.Add(time.Second)
+	c.put(cacheSearch, "b", []byte("bbbb"))
+	now = now.Add(time.Second)
+	c.put(cacheSearch, "c", []byte("cccc"))
+	if _, _, ok := c.get(cacheSearch, "a"); ok {
+		t.Error("oldest entry not evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, _, ok := c.get(cacheSearch, key); !ok {
+			t.Errorf("entry %q evicted", key)
+		}
+	}
+	entries, _ := os.ReadDir(c.dir)
+	if len(entries) != 2 {
+		t.Errorf("%d cache files, want 2", len(entries))
+	}
+}
+
+func Test_cachedRequest_stale(t *testing.T) {
+	var down atomic.Bool
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != urlCountries {
+			return
+		}
+		requests.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name":"Romania","iso_3166_1":"RO","stationcount":42}]`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	a.cache = newDiskCache(t.TempDir(), 1024, map[cacheKind]time.Duration{cacheCountries: time.Hour})
+	a.cache.now = func() time.Time { return now }
+
+	if res, err := a.GetCountries(ctx); err != nil || len(res) != 1 {
+		t.Fatalf("GetCountries = %v, %v", res, err)
+	}
+	a.countries = nil
+	if _, err := a.GetCountries(ctx); err != nil || requests.Load() != 1 {
+		t.Fatalf("fresh entry not used: %d requests, err %v", requests.Load(), err)
+	}
+
+	a.countries = nil
+	down.Store(true)
+	now = now.Add(2 * time.Hour)
+	res, err := a.GetCountries(ctx)
+	if err != nil || len(res) != 1 || res[0].ISO3166_1 != "RO" {
+		t.Fatalf("stale GetCountries = %v, %v", res, err)
+	}
+	if requests.Load() < 2 {
+		t.Error("expired entry served without a request")
+	}
+}
+
+func Test_cachedRequest_pages(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != urlStations {
+			return
+		}
+		requests.Add(1)
+		_ = r.ParseForm()
+		_, _ = w.Write([]byte(`[{"stationuuid":"offset-` + r.PostForm.Get("offset") + `"}]`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.cache = newDiskCache(t.TempDir(), 1024*1024, map[cacheKind]time.Duration{cacheSearch: time.Hour})
+
+	p := DefaultSearchParams()
+	for range 2 {
+		for _, offset := range []int{0, DefLimit} {
+			p.Offset = offset
+			res, err := a.Search(ctx, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "offset-" + strconv.Itoa(offset); len(res) != 1 || res[0].Stationuuid != want {
+				t.Errorf("page at offset %d = %+v", offset, res)
+			}
+		}
+	}
+	if requests.Load() != 2 {
+		t.Errorf("%d requests, want one per page", requests.Load())
+	}
+}
@@ -0,0 +1,65 @@
+package browser
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+// checkHistoryLimit is the number of most recent checks returned.
+const checkHistoryLimit = 10
+
+// StationChecks returns the most recent stream checks of the station, newest
+// first, with the steps of the failed ones.
+func (a *API) StationChecks(ctx context.Context, uuid string) ([]model.StationCheck, error) {
+	log := slog.With("method", "Api.StationChecks")
+	var checks []model.StationCheck
+	if err := a.uncachedRequest(ctx, http.MethodGet, urlChecks+uuid, nil, &checks); err != nil {
+		return nil, fmt.Errorf("Get station checks: %w", err)
+	}
+	slices.SortStableFunc(checks, func(a, b model.StationCheck) int {
+		return cmp.Compare(b.Timestamp, a.Timestamp)
+	})
+	checks = checks[:min(len(checks), checkHistoryLimit)]
+
+	var failed []string
+	for _, c := range checks {
+		if c.Ok == 0 {
+			failed = append(failed, c.Checkuuid)
+		}
+	}
+	if len(failed) > 0 {
+		steps, err := a.checkSteps(ctx, failed)
+		if err != nil {
+			// the checks are still useful without the failure details
+			log.Error("check steps", "error", err)
+		}
+		for i := range checks {
+			for _, s := range steps {
+				if s.Checkuuid == checks[i].Checkuuid {
+					checks[i].Steps = append(checks[i].Steps, s)
+				}
+			}
+		}
+	}
+	log.Info("", "length", len(checks), "failed", len(failed))
+	return checks, nil
+}
+
+func (a *API) checkSteps(ctx context.Context, checkUUIDs []string) ([]model.StationCheckStep, error) {
+	body := "uuids=" + strings.Join(checkUUIDs, ",")
+	var steps []model.StationCheckStep
+	if _, err := a.serverRequest(ctx, http.MethodPost, urlCheckSteps, []byte(body), &steps); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(steps, func(a, b model.StationCheckStep) int {
+		return cmp.Compare(a.Created, b.Created)
+	})
+	return steps, nil
+}
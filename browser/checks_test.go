@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_StationChecks(t *testing.T) {
+	var stepsForm string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case urlChecks + "station":
+			var checks []string
+			for i := range checkHistoryLimit + 2 {
+				ok := 1
+				if i == 5 {
+					ok = 0
+				}
+				checks = append(checks, fmt.Sprintf(
+					`{"checkuuid":"c%02d","stationuuid":"station","source":"de1","codec":"MP3","bitrate":128,"ok":%d,"timestamp_iso8601":"2024-05-01T10:%02d:00Z"}`,
+					i, ok, i))
+			}
+			_, _ = w.Write([]byte("[" + strings.Join(checks, ",") + "]"))
+		case urlCheckSteps:
+			_ = r.ParseForm()
+			stepsForm = r.PostForm.Get("uuids")
+			_, _ = w.Write([]byte(`[
+				{"stepuuid":"s2","checkuuid":"c05","url":"http://b","error":"connection refused","creation_iso8601":"2024-05-01T10:05:02Z"},
+				{"stepuuid":"s1","checkuuid":"c05","url":"http://a","urltype":"PLS","creation_iso8601":"2024-05-01T10:05:01Z"}
+			]`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks, err := a.StationChecks(ctx, "station")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != checkHistoryLimit || checks[0].Checkuuid != "c11" || checks[len(checks)-1].Checkuuid != "c02" {
+		t.Fatalf("checks = %+v", checks)
+	}
+	if stepsForm != "c05" {
+		t.Errorf("check steps uuids = %q", stepsForm)
+	}
+	for _, c := range checks {
+		switch {
+		case c.Checkuuid == "c05" && (len(c.Steps) != 2 || c.Steps[0].Stepuuid != "s1" || c.FailureReason() != "connection refused"):
+			t.Errorf("failed check = %+v", c)
+		case c.Checkuuid != "c05" && len(c.Steps) != 0:
+			t.Errorf("ok check with steps = %+v", c)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package browser
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/geo"
+	"github.com/dancnb/sonicradio/model"
+)
+
+// idSeparator separates the provider prefix from the provider station ID.
+// radio-browser station IDs have no prefix.
+const idSeparator = ":"
+
+// remoteDirectoryTTL is how long the stations of a remote directory are reused.
+const remoteDirectoryTTL = 10 * time.Minute
+
+var errVoteNotSupported = errors.New("Voting is only available for radio-browser stations")
+
+// StationDirectory is a source of stations that can be searched, looked up
+// by station ID, and told about clicks and votes.
+type StationDirectory interface {
+	Search(ctx context.Context, s SearchParams) ([]model.Station, error)
+	GetStations(ctx context.Context, ids []string) ([]model.Station, error)
+	StationCounter(ctx context.Context, id string) error
+	StationVote(ctx context.Context, id string) error
+}
+
+var _ StationDirectory = (*API)(nil)
+
+// Lists are the radio-browser station metadata lists, ordered by station
+// count.
+type Lists interface {
+	GetTags(ctx context.Context) ([]model.StationTag, error)
+	GetCountries(ctx context.Context) ([]model.Country, error)
+	GetStates(ctx context.Context) ([]model.State, error)
+	GetLanguages(ctx context.Context) ([]model.Language, error)
+	GetCodecs(ctx context.Context) ([]model.Codec, error)
+}
+
+// RadioBrowser is the radio-browser API beyond the station directory: its
+// connectivity, metadata lists, discovery feeds, stream checks and
+// publishing.
+type RadioBrowser interface {
+	StationDirectory
+	Lists
+	Online() bool
+	StatusUpdates() <-chan bool
+	TopStations(ctx context.Context) ([]model.Station, error)
+	NearbyStations(ctx context.Context, p geo.Point, radiusKm int) ([]model.Station, error)
+	FeedStations(ctx context.Context, f Feed) ([]model.Station, error)
+	Recommend(ctx context.Context, p *Profile, n int) ([]model.Station, error)
+	StationChecks(ctx context.Context, uuid string) ([]model.StationCheck, error)
+	CheckStream(ctx context.Context, streamURL string) error
+	AddStation(ctx context.Context, s model.Station) (string, error)
+}
+
+var _ RadioBrowser = (*API)(nil)
+
+// Provider is a station directory other than radio-browser, whose station
+// IDs start with its prefix, e.g. "company:42".
+type Provider interface {
+	StationDirectory
+	Prefix() string
+	Name() string
+	// Stations returns all the stations of the directory.
+	Stations(ctx context.Context) ([]model.Station, error)
+}
+
+// StationID returns the ID of a provider station.
+func StationID(prefix string, id string) string {
+	return prefix + idSeparator + id
+}
+
+// IDPrefix returns the provider prefix of a station ID, empty for the
+// radio-browser stations.
+func IDPrefix(id string) string {
+	prefix, _, ok := strings.Cut(id, idSeparator)
+	if !ok {
+		return ""
+	}
+	return prefix
+}
+
+// IsRadioBrowserID reports whether the station ID belongs to radio-browser.
+func IsRadioBrowserID(id string) bool {
+	return IDPrefix(id) == ""
+}
+
+// Directories combines radio-browser with the configured providers: searches
+// include the stations of every provider, unless restricted to one of them,
+// and the other requests are routed by the station ID prefix.
+type Directories struct {
+	api       *API
+	providers []Provider
+}
+
+var _ StationDirectory = (*Directories)(nil)
+
+func NewDirectories(api *API, providers ...Provider) *Directories {
+	return &Directories{api: api, providers: providers}
+}
+
+// NewConfiguredDirectories returns radio-browser and the directories of the config.
+func NewConfiguredDirectories(api *API, cfg *config.Value) (*Directories, error) {
+	dirs, err := cfg.GetDirectories()
+	if err != nil {
+		return nil, err
+	}
+	var providers []Provider
+	for _, d := range dirs {
+		if d.Type == config.DirectoryOPML {
+			providers = append(providers, NewOPMLDirectory(d.Prefix, d.GetName(), d.Path+d.URL, cfg.NewHTTPClient()))
+		} else if d.Path != "" {
+			providers = append(providers, NewLocalDirectory(d.Prefix, d.GetName(), d.Path))
+		} else {
+			providers = append(providers, NewRemoteDirectory(d.Prefix, d.GetName(), d.URL, cfg.NewHTTPClient()))
+		}
+	}
+	return NewDirectories(api, providers...), nil
+}
+
+// Providers returns the providers other than radio-browser.
+func (d *Directories) Providers() []Provider {
+	return d.providers
+}
+
+func (d *Directories) provider(id string) Provider {
+	prefix := IDPrefix(id)
+	for _, p := range d.providers {
+		if p.Prefix() == prefix {
+			return p
+		}
+	}
+	return nil
+}
+
+// Search returns the provider stations matching s before the radio-browser
+// ones. A failing provider is skipped, unless the search is restricted to it;
+// a radio-browser error is returned along with the provider stations.
+func (d *Directories) Search(ctx context.Context, s SearchParams) ([]model.Station, error) {
+	log := slog.With("method", "Directories.Search")
+	if s.Directory != "" {
+		for _, p := range d.providers {
+			if p.Prefix() == s.Directory {
+				return p.Search(ctx, s)
+			}
+		}
+		return nil, fmt.Errorf("unknown directory %q", s.Directory)
+	}
+
+	var res []model.Station
+	for _, p := range d.providers {
+		stations, err := p.Search(ctx, s)
+		if err != nil {
+			log.Error("", "directory", p.Prefix(), "error", err)
+			continue
+		}
+		res = append(res, stations...)
+	}
+	stations, err := d.api.Search(ctx, s)
+	if err != nil {
+		return res, err
+	}
+	return append(res, stations...), nil
+}
+
+// GetStations returns the stations of the IDs, grouped by provider. The
+// errors of the failing sources are returned along with the stations of the
+// others; the radio-browser IDs are skipped while offline.
+func (d *Directories) GetStations(ctx context.Context, ids []string) ([]model.Station, error) {
+	byProvider := make(map[Provider][]string)
+	var rbIDs []string
+	for _, id := range ids {
+		if IsRadioBrowserID(id) {
+			rbIDs = append(rbIDs, id)
+		} else if p := d.provider(id); p != nil {
+			byProvider[p] = append(byProvider[p], id)
+		}
+	}
+
+	var res []model.Station
+	var errs []error
+	if len(rbIDs) > 0 && d.api.Online() {
+		stations, err := d.api.GetStations(ctx, rbIDs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		res = append(res, stations...)
+	}
+	for _, p := range d.providers {
+		if len(byProvider[p]) == 0 {
+			continue
+		}
+		stations, err := p.GetStations(ctx, byProvider[p])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("directory %q: %w", p.Prefix(), err))
+			continue
+		}
+		res = append(res, stations...)
+	}
+	return res, errors.Join(errs...)
+}
+
+func (d *Directories) StationCounter(ctx context.Context, id string) error {
+	if IsRadioBrowserID(id) {
+		return d.api.StationCounter(ctx, id)
+	}
+	return nil
+}
+
+func (d *Directories) StationVote(ctx context.Context, id string) error {
+	if IsRadioBrowserID(id) {
+		return d.api.StationVote(ctx, id)
+	}
+	return errVoteNotSupported
+}
+
+// listProvider is a provider loading its whole station list at once; the
+// searches are done locally.
+type listProvider struct {
+	prefix string
+	name   string
+	load   func(ctx context.Context) ([]model.Station, error)
+}
+
+func (p *listProvider) Prefix() string { return p.prefix }
+
+func (p *listProvider) Name() string { return p.name }
+
+func (p *listProvider) Stations(ctx context.Context) ([]model.Station, error) {
+	return p.load(ctx)
+}
+
+func (p *listProvider) Search(ctx context.Context, s SearchParams) ([]model.Station, error) {
+	stations, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []model.Station
+	for _, st := range stations {
+		if s.matches(st) {
+			res = append(res, st)
+		}
+	}
+	sortStations(res, s.Order, s.Reverse)
+	if s.Offset >= len(res) {
+		return nil, nil
+	}
+	res = res[s.Offset:]
+	if s.Limit > 0 {
+		res = res[:min(s.Limit, len(res))]
+	}
+	return res, nil
+}
+
+func (p *listProvider) GetStations(ctx context.Context, ids []string) ([]model.Station, error) {
+	stations, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []model.Station
+	for _, st := range stations {
+		if slices.Contains(ids, st.Stationuuid) {
+			res = append(res, st)
+		}
+	}
+	return res, nil
+}
+
+func (p *listProvider) StationCounter(context.Context, string) error { return nil }
+
+func (p *listProvider) StationVote(context.Context, string) error { return errVoteNotSupported }
+
+// cachedLoad reuses the loaded stations for ttl.
+func cachedLoad(ttl time.Duration, load func(ctx context.Context) ([]model.Station, error)) func(ctx context.Context) ([]model.Station, error) {
+	var mu sync.Mutex
+	var stations []model.Station
+	var loaded time.Time
+	return func(ctx context.Context) ([]model.Station, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !loaded.IsZero() && time.Since(loaded) < ttl {
+			return stations, nil
+		}
+		res, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stations, loaded = res, time.Now()
+		return stations, nil
+	}
+}
+
+// urlID returns a stable provider ID for stations without one.
+func urlID(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return hex.EncodeToString(sum[:8])
+}
+
+// matches reports whether the station matches the text filters of the
+// search; the radio-browser specific filters are ignored.
+func (s SearchParams) matches(st model.Station) bool {
+	contains := func(v string, sub string, exact bool) bool {
+		sub = strings.TrimSpace(sub)
+		if sub == "" {
+			return true
+		}
+		if exact {
+			return strings.EqualFold(strings.TrimSpace(v), sub)
+		}
+		return strings.Contains(strings.ToLower(v), strings.ToLower(sub))
+	}
+	tagsMatch := true
+	for _, t := range splitValues(s.TagList) {
+		tagsMatch = tagsMatch && slices.ContainsFunc(splitValues(st.Tags), func(v string) bool {
+			return contains(v, t, s.TagExact)
+		})
+	}
+	return tagsMatch &&
+		contains(st.Name, s.Name, s.NameExact) &&
+		contains(st.Country, s.Country, s.CountryExact) &&
+		contains(st.Countrycode, s.CountryCode, true) &&
+		contains(st.State, s.State, s.StateExact) &&
+		contains(st.Language, s.Language, false) &&
+		(s.Codec == "" || slices.ContainsFunc(s.codecs(), func(c string) bool { return strings.EqualFold(c, st.Codec) })) &&
+		(s.BitrateMin <= 0 || st.Bitrate >= int64(s.BitrateMin)) &&
+		(s.BitrateMax <= 0 || st.Bitrate <= int64(s.BitrateMax)) &&
+		(!s.IsHTTPS || strings.HasPrefix(strings.ToLower(st.URL), "https://"))
+}
@@ -0,0 +1,141 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+func Test_LocalDirectory(t *testing.T) {
+	dir := t.TempDir()
+	m3u := "#EXTM3U\n#EXTINF:-1,Office Jazz\nhttp://radio.local/jazz\n\nhttp://radio.local/untitled\n"
+	pls := "[playlist]\nFile1=http://radio.local/news\nTitle1=Office News\nFile2=http://radio.local/jazz\nNumberOfEntries=2\n"
+	for name, content := range map[string]string{"jazz.m3u": m3u, "news.pls": pls, "notes.txt": "http://ignored"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewLocalDirectory("office", "Office", dir)
+	stations, err := p.Stations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Station{
+		{Name: "Office Jazz", URL: "http://radio.local/jazz", Tags: "jazz"},
+		{Name: "http://radio.local/untitled", URL: "http://radio.local/untitled", Tags: "jazz"},
+		{Name: "Office News", URL: "http://radio.local/news", Tags: "news"},
+	}
+	if len(stations) != len(want) {
+		t.Fatalf("stations = %+v", stations)
+	}
+	for i, s := range stations {
+		if s.Name != want[i].Name || s.URL != want[i].URL || s.Tags != want[i].Tags || IDPrefix(s.Stationuuid) != "office" {
+			t.Errorf("station %d = %+v, want %+v", i, s, want[i])
+		}
+	}
+
+	s := DefaultSearchParams()
+	s.Name = "news"
+	res, err := p.Search(context.Background(), s)
+	if err != nil || len(res) != 1 || res[0].Name != "Office News" {
+		t.Errorf("Search = %+v, %v", res, err)
+	}
+}
+
+func Test_RemoteDirectory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stations.json":
+			_, _ = w.Write([]byte(`[{"stationuuid":"42","name":"Company FM","url":"http://radio.local/fm","tags":"company"},{"name":"No URL"}]`))
+		case "/directory.opml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><opml version="1.0"><body>
+				<outline text="Music"><outline type="audio" text="Rock" URL="http://radio.local/rock" bitrate="128"/></outline>
+				<outline type="link" text="More" URL="http://radio.local/more.opml"/>
+			</body></opml>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	stations, err := NewRemoteDirectory("company", "Company", srv.URL+"/stations.json", srv.Client()).Stations(ctx)
+	if err != nil || len(stations) != 1 || stations[0].Stationuuid != "company:42" {
+		t.Errorf("JSON stations = %+v, %v", stations, err)
+	}
+	stations, err = NewRemoteDirectory("opml", "OPML", srv.URL+"/directory.opml", srv.Client()).Stations(ctx)
+	if err != nil || len(stations) != 1 || stations[0].Name != "Rock" || stations[0].Tags != "Music" || stations[0].Bitrate != 128 {
+		t.Errorf("OPML stations = %+v, %v", stations, err)
+	}
+	if _, err := NewRemoteDirectory("x", "X", srv.URL+"/missing", srv.Client()).Stations(ctx); err == nil {
+		t.Error("missing directory error = nil")
+	}
+}
+
+func Test_Directories(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case urlStations:
+			_, _ = w.Write([]byte(`[{"stationuuid":"rb-1","name":"Jazz radio"}]`))
+		case urlStationsByUUID:
+			_ = r.ParseForm()
+			if r.PostForm.Get("uuids") != "rb-1" {
+				t.Errorf("radio-browser uuids = %q", r.PostForm.Get("uuids"))
+			}
+			_, _ = w.Write([]byte(`[{"stationuuid":"rb-1","name":"Jazz radio"}]`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	office := &listProvider{prefix: "office", name: "Office", load: func(context.Context) ([]model.Station, error) {
+		return []model.Station{
+			{Stationuuid: "office:1", Name: "Office Jazz", Tags: "jazz"},
+			{Stationuuid: "office:2", Name: "Office News", Tags: "news"},
+		}, nil
+	}}
+	broken := &listProvider{prefix: "broken", name: "Broken", load: func(context.Context) ([]model.Station, error) {
+		return nil, errors.New("unreachable")
+	}}
+	d := NewDirectories(a, office, broken)
+
+	s := DefaultSearchParams()
+	s.TagList = "jazz"
+	res, err := d.Search(ctx, s)
+	if err != nil || len(res) != 2 || res[0].Stationuuid != "office:1" || res[1].Stationuuid != "rb-1" {
+		t.Errorf("Search = %+v, %v", res, err)
+	}
+	s = DefaultSearchParams()
+	s.Directory = "office"
+	if res, err := d.Search(ctx, s); err != nil || len(res) != 2 {
+		t.Errorf("directory Search = %+v, %v", res, err)
+	}
+
+	res, err = d.GetStations(ctx, []string{"office:2", "rb-1", "unknown:1"})
+	if err != nil || len(res) != 2 || res[0].Stationuuid != "rb-1" || res[1].Stationuuid != "office:2" {
+		t.Errorf("GetStations = %+v, %v", res, err)
+	}
+	// a failing directory keeps the stations of the others
+	res, err = d.GetStations(ctx, []string{"office:2", "rb-1", "broken:1"})
+	if err == nil || len(res) != 2 || res[0].Stationuuid != "rb-1" || res[1].Stationuuid != "office:2" {
+		t.Errorf("GetStations with a failing directory = %+v, %v", res, err)
+	}
+	if err := d.StationVote(ctx, "office:1"); !errors.Is(err, errVoteNotSupported) {
+		t.Errorf("provider vote error = %v", err)
+	}
+	if err := d.StationCounter(ctx, "office:1"); err != nil {
+		t.Errorf("provider counter error = %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+// feedLimit is the number of stations of the feeds that can't be paged.
+const feedLimit = 100
+
+// Feed is a list of stations to discover without composing a search.
+type Feed int
+
+const (
+	FeedTop Feed = iota
+	FeedTrending
+	FeedLastClick
+	FeedLastChange
+	FeedRandom
+)
+
+var feedNames = []string{
+	FeedTop:        "Top voted",
+	FeedTrending:   "Trending",
+	FeedLastClick:  "Recently clicked",
+	FeedLastChange: "Recently changed",
+	FeedRandom:     "Random",
+}
+
+func (f Feed) String() string {
+	if f < 0 || int(f) >= len(feedNames) {
+		return ""
+	}
+	return feedNames[f]
+}
+
+// Next returns the feed after f, wrapping around to the top stations.
+func (f Feed) Next() Feed {
+	return (f + 1) % Feed(len(feedNames))
+}
+
+// SearchParams returns the search of the feed, or false if the feed is not
+// a search and can't be paged.
+func (f Feed) SearchParams() (SearchParams, bool) {
+	s := DefaultSearchParams()
+	switch f {
+	case FeedTop:
+		return s, true
+	case FeedTrending:
+		s.Order = Clicktrend
+		return s, true
+	case FeedRandom:
+		s.Order = Random
+		return s, true
+	}
+	return s, false
+}
+
+// FeedStations returns the first stations of the feed.
+func (a *API) FeedStations(ctx context.Context, f Feed) ([]model.Station, error) {
+	switch f {
+	case FeedLastClick:
+		return a.LastClickStations(ctx, feedLimit)
+	case FeedLastChange:
+		return a.LastChangeStations(ctx, feedLimit)
+	}
+	s, _ := f.SearchParams()
+	return a.Search(ctx, s)
+}
+
+// TrendingStations returns the stations with the largest increase of clicks.
+func (a *API) TrendingStations(ctx context.Context) ([]model.Station, error) {
+	return a.FeedStations(ctx, FeedTrending)
+}
+
+// RandomStations returns a new random selection of stations on every call.
+func (a *API) RandomStations(ctx context.Context) ([]model.Station, error) {
+	return a.FeedStations(ctx, FeedRandom)
+}
+
+// LastClickStations returns the last limit stations that were played.
+func (a *API) LastClickStations(ctx context.Context, limit int) ([]model.Station, error) {
+	return a.stationList(ctx, "Api.LastClickStations", urlLastClick+strconv.Itoa(limit))
+}
+
+// LastChangeStations returns the last limit stations that were added or changed.
+func (a *API) LastChangeStations(ctx context.Context, limit int) ([]model.Station, error) {
+	return a.stationList(ctx, "Api.LastChangeStations", urlLastChange+strconv.Itoa(limit))
+}
+
+// stationList requests the stations of a list that changes all the time, so
+// the response is not cached.
+func (a *API) stationList(ctx context.Context, method string, path string) ([]model.Station, error) {
+	log := slog.With("method", method)
+	var stations []model.Station
+	if err := a.uncachedRequest(ctx, http.MethodPost, path, []byte(feedFormData), &stations); err != nil {
+		return nil, fmt.Errorf("Get stations: %w", err)
+	}
+	stations = uniqueStations(stations)
+	log.Info("", "length", len(stations))
+	return stations, nil
+}
+
+// uniqueStations drops the repeated stations, keeping the first occurrence.
+func uniqueStations(stations []model.Station) []model.Station {
+	seen := make(map[string]bool, len(stations))
+	res := stations[:0]
+	for _, s := range stations {
+		if !seen[s.Stationuuid] {
+			seen[s.Stationuuid] = true
+			res = append(res, s)
+		}
+	}
+	return res
+}
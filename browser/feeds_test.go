@@ -0,0 +1,76 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_FeedStations(t *testing.T) {
+	var randomRequests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.URL.Path {
+		case urlLastClick + "100":
+			if r.PostForm.Get("hidebroken") != "true" {
+				t.Errorf("last click form = %v", r.PostForm)
+			}
+			_, _ = w.Write([]byte(`[{"stationuuid":"a"},{"stationuuid":"b"},{"stationuuid":"a"}]`))
+		case urlLastChange + "100":
+			_, _ = w.Write([]byte(`[{"stationuuid":"changed"}]`))
+		case urlStations:
+			order := r.PostForm.Get("order")
+			if order == string(Random) {
+				randomRequests.Add(1)
+			}
+			_, _ = w.Write([]byte(`[{"stationuuid":"` + order + `"}]`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.cache = newDiskCache(t.TempDir(), 1024*1024, map[cacheKind]time.Duration{cacheSearch: time.Hour})
+
+	tests := []struct {
+		feed Feed
+		want []string
+	}{
+		{FeedTop, []string{string(Votes)}},
+		{FeedTrending, []string{string(Clicktrend)}},
+		{FeedLastClick, []string{"a", "b"}},
+		{FeedLastChange, []string{"changed"}},
+		{FeedRandom, []string{string(Random)}},
+	}
+	for _, tt := range tests {
+		res, err := a.FeedStations(ctx, tt.feed)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.feed, err)
+		}
+		var got []string
+		for _, s := range res {
+			got = append(got, s.Stationuuid)
+		}
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[len(got)-1] != tt.want[len(tt.want)-1] {
+			t.Errorf("%s = %v, want %v", tt.feed, got, tt.want)
+		}
+	}
+
+	// the random feed is reshuffled instead of served from the cache
+	if _, err := a.RandomStations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if randomRequests.Load() != 2 {
+		t.Errorf("%d random requests, want 2", randomRequests.Load())
+	}
+	if FeedRandom.Next() != FeedTop {
+		t.Errorf("feed after random = %s", FeedRandom.Next())
+	}
+}
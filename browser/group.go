@@ -0,0 +1,101 @@
+package browser
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/model"
+)
+
+var (
+	nameTokenRe = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	// bitrate tokens like 128k or 128kbps
+	bitrateTokenRe = regexp.MustCompile(`^\d{2,3}(k|kb|kbps|kbit)$`)
+	// bare numbers, bitrates only next to a variant token, e.g. "128 kbps"
+	numberTokenRe = regexp.MustCompile(`^\d{2,3}$`)
+	// name tokens telling the variants of a station apart
+	variantTokens = map[string]bool{
+		"mp3": true, "aac": true, "aacp": true, "ogg": true, "opus": true, "flac": true,
+		"kbps": true, "hq": true, "lq": true, "hd": true, "high": true, "low": true, "mobile": true,
+	}
+)
+
+// groupKey is the normalized name and homepage shared by the variants of a
+// station.
+func groupKey(s model.Station) string {
+	var tokens []string
+	all := nameTokenRe.FindAllString(strings.ToLower(s.Name), -1)
+	for i, t := range all {
+		if variantTokens[t] || bitrateTokenRe.MatchString(t) {
+			continue
+		}
+		if numberTokenRe.MatchString(t) &&
+			((i > 0 && variantTokens[all[i-1]]) || (i < len(all)-1 && variantTokens[all[i+1]])) {
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+	if len(tokens) == 0 {
+		// nothing but variant tokens, keep the station apart
+		return s.Stationuuid
+	}
+
+	homepage := strings.ToLower(strings.TrimSpace(s.Homepage))
+	homepage = strings.TrimPrefix(strings.TrimPrefix(homepage, "https://"), "http://")
+	homepage = strings.TrimPrefix(homepage, "www.")
+	homepage = strings.TrimRight(homepage, "/")
+	return strings.Join(tokens, " ") + "|" + homepage
+}
+
+// GroupVariants collapses the variants of each station into the one played by
+// default, which carries all of them in Variants. The groups keep the order
+// of their first station.
+func GroupVariants(stations []model.Station, g config.Grouping) []model.Station {
+	var keys []string
+	groups := make(map[string][]model.Station)
+	for _, s := range stations {
+		s.Variants, s.VariantOf = nil, ""
+		k := groupKey(s)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], s)
+	}
+
+	res := make([]model.Station, 0, len(keys))
+	for _, k := range keys {
+		variants := groups[k]
+		if len(variants) == 1 {
+			res = append(res, variants[0])
+			continue
+		}
+		slices.SortStableFunc(variants, func(a, b model.Station) int {
+			return cmp.Compare(b.Bitrate, a.Bitrate)
+		})
+		s := PreferredVariant(variants, g)
+		s.Variants = variants
+		res = append(res, s)
+	}
+	return res
+}
+
+// PreferredVariant returns the variant played by default, from the variants
+// sorted by bitrate; the highest bitrate one when none matches the preference.
+func PreferredVariant(variants []model.Station, g config.Grouping) model.Station {
+	var match func(s model.Station) bool
+	switch g.Prefer {
+	case config.VariantCodec:
+		match = func(s model.Station) bool { return strings.EqualFold(strings.TrimSpace(s.Codec), g.GetCodec()) }
+	case config.VariantHTTPS:
+		match = func(s model.Station) bool { return strings.HasPrefix(strings.ToLower(s.URL), "https://") }
+	}
+	if match != nil {
+		if i := slices.IndexFunc(variants, match); i >= 0 {
+			return variants[i]
+		}
+	}
+	return variants[0]
+}
@@ -0,0 +1,66 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/model"
+)
+
+func Test_GroupVariants(t *testing.T) {
+	stations := []model.Station{
+		{Stationuuid: "jazz-128", Name: "Jazz FM", Homepage: "https://www.jazz.fm/", URL: "http://jazz.fm/128", Codec: "MP3", Bitrate: 128},
+		{Stationuuid: "rock", Name: "Rock FM", Homepage: "https://rock.fm", URL: "http://rock.fm/live", Codec: "MP3", Bitrate: 192},
+		{Stationuuid: "jazz-aac", Name: "JAZZ FM (AAC 64k)", Homepage: "http://jazz.fm", URL: "https://jazz.fm/aac", Codec: "AAC", Bitrate: 64},
+		{Stationuuid: "jazz-hq", Name: "Jazz FM - 320 kbps", Homepage: "jazz.fm", URL: "http://jazz.fm/320", Codec: "MP3", Bitrate: 320},
+		{Stationuuid: "jazz-other", Name: "Jazz FM", Homepage: "https://other-jazz.example", Bitrate: 128},
+	}
+
+	tests := []struct {
+		grouping config.Grouping
+		want     string
+	}{
+		{grouping: config.Grouping{Prefer: config.VariantHighestBitrate}, want: "jazz-hq"},
+		{grouping: config.Grouping{Prefer: config.VariantCodec}, want: "jazz-aac"},
+		{grouping: config.Grouping{Prefer: config.VariantCodec, Codec: "flac"}, want: "jazz-hq"},
+		{grouping: config.Grouping{Prefer: config.VariantHTTPS}, want: "jazz-aac"},
+	}
+	for _, tt := range tests {
+		res := GroupVariants(stations, tt.grouping)
+		if len(res) != 3 {
+			t.Fatalf("GroupVariants = %+v", res)
+		}
+		if res[0].Stationuuid != tt.want || res[1].Stationuuid != "rock" || res[2].Stationuuid != "jazz-other" {
+			t.Errorf("preference %v: groups = %s, %s, %s", tt.grouping.Prefer, res[0].Stationuuid, res[1].Stationuuid, res[2].Stationuuid)
+		}
+		var variants []string
+		for _, v := range res[0].Variants {
+			variants = append(variants, v.Stationuuid)
+		}
+		if len(variants) != 3 || variants[0] != "jazz-hq" || variants[1] != "jazz-128" || variants[2] != "jazz-aac" {
+			t.Errorf("variants = %v", variants)
+		}
+		if res[1].Variants != nil {
+			t.Errorf("single station variants = %+v", res[1].Variants)
+		}
+	}
+}
+
+func Test_groupKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{a: "Jazz FM 128k", b: "Jazz FM", same: true},
+		{a: "Jazz FM MP3 128", b: "Jazz FM 64 kbps", same: true},
+		{a: "Kiss FM 98", b: "Kiss FM 104"},
+		{a: "Kiss FM 98", b: "Kiss FM"},
+	}
+	for _, tt := range tests {
+		a := groupKey(model.Station{Stationuuid: "a", Name: tt.a})
+		b := groupKey(model.Station{Stationuuid: "b", Name: tt.b})
+		if (a == b) != tt.same {
+			t.Errorf("groupKey(%q) = %q, groupKey(%q) = %q, want same %v", tt.a, a, tt.b, b, tt.same)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+// NewLocalDirectory returns a provider of the stations listed in the M3U and
+// PLS playlists of dir. The playlist names become the station tags. The
+// directory is read again on every request, so edits show up right away.
+func NewLocalDirectory(prefix string, name string, dir string) Provider {
+	return &listProvider{
+		prefix: prefix,
+		name:   name,
+		load: func(context.Context) ([]model.Station, error) {
+			return loadPlaylistDir(prefix, dir)
+		},
+	}
+}
+
+func loadPlaylistDir(prefix string, dir string) ([]model.Station, error) {
+	log := slog.With("method", "browser.loadPlaylistDir")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+	var res []model.Station
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		var parse func(*os.File) []model.Station
+		switch ext {
+		case ".m3u", ".m3u8":
+			parse = parseM3U
+		case ".pls":
+			parse = parsePLS
+		default:
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			log.Error("open playlist", "file", e.Name(), "error", err)
+			continue
+		}
+		tag := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		for _, s := range parse(f) {
+			s.Stationuuid = StationID(prefix, urlID(s.URL))
+			if seen[s.Stationuuid] {
+				continue
+			}
+			seen[s.Stationuuid] = true
+			if s.Name == "" {
+				s.Name = s.URL
+			}
+			s.Tags = tag
+			res = append(res, s)
+		}
+		_ = f.Close()
+	}
+	return res, nil
+}
+
+// parseM3U returns the stream URLs of an M3U playlist, named by their #EXTINF title.
+func parseM3U(f *os.File) []model.Station {
+	var res []model.Station
+	var title string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		l := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(l, "#EXTINF:"):
+			if _, t, ok := strings.Cut(l, ","); ok {
+				title = strings.TrimSpace(t)
+			}
+		case l == "" || strings.HasPrefix(l, "#"):
+		default:
+			res = append(res, model.Station{Name: title, URL: l})
+			title = ""
+		}
+	}
+	return res
+}
+
+// parsePLS returns the FileN entries of a PLS playlist, named by their TitleN.
+func parsePLS(f *os.File) []model.Station {
+	files := make(map[int]*model.Station)
+	var order []int
+	entry := func(n int) *model.Station {
+		if files[n] == nil {
+			files[n] = &model.Station{}
+			order = append(order, n)
+		}
+		return files[n]
+	}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if !ok {
+			continue
+		}
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.TrimSpace(v)
+		for _, field := range []string{"file", "title"} {
+			n, err := strconv.Atoi(strings.TrimPrefix(k, field))
+			if !strings.HasPrefix(k, field) || err != nil {
+				continue
+			}
+			if field == "file" {
+				entry(n).URL = v
+			} else {
+				entry(n).Name = v
+			}
+		}
+	}
+	var res []model.Station
+	for _, n := range order {
+		if files[n].URL != "" {
+			res = append(res, *files[n])
+		}
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package browser
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/dancnb/sonicradio/config"
+)
+
+var reconnectInterval = 30 * time.Second
+
+var ErrOffline = errors.New("radio-browser is unreachable, working offline")
+
+// Online reports whether the radio-browser servers are reachable.
+func (a *API) Online() bool {
+	return !a.offline.Load()
+}
+
+// StatusUpdates receives the new online state on each change.
+func (a *API) StatusUpdates() <-chan bool {
+	return a.statusCh
+}
+
+func (a *API) notifyStatus(online bool) {
+	select {
+	case a.statusCh <- online:
+	default:
+		slog.Warn("API.notifyStatus: update dropped", "online", online)
+	}
+}
+
+// setOffline switches to offline mode and, unless started with the
+// -offline flag, periodically tries to reach the servers again.
+func (a *API) setOffline() {
+	if a.offline.Swap(true) {
+		return
+	}
+	slog.Warn("browser: offline")
+	a.notifyStatus(false)
+	if !config.Offline() {
+		go a.reconnectLoop()
+	}
+}
+
+func (a *API) reconnectLoop() {
+	log := slog.With("method", "Api.reconnectLoop")
+	tick := time.NewTicker(a.reconnectInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-tick.C:
+		}
+		if a.connect(a.ctx) {
+			log.Info("browser: back online")
+			a.offline.Store(false)
+			a.notifyStatus(true)
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+func Test_offline(t *testing.T) {
+	reconnectInterval = 10 * time.Millisecond
+	t.Cleanup(func() { reconnectInterval = 30 * time.Second })
+
+	// reserve an address with nothing listening yet
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig("http://"+addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Online() {
+		t.Fatal("expected offline mode without a reachable server")
+	}
+	if online := <-a.StatusUpdates(); online {
+		t.Error("expected an offline status update")
+	}
+	if _, err := a.TopStations(ctx); !errors.Is(err, ErrOffline) {
+		t.Errorf("TopStations err = %v, want ErrOffline", err)
+	}
+	if err := a.StationVote(ctx, "uuid"); !errors.Is(err, ErrOffline) {
+		t.Errorf("StationVote err = %v, want ErrOffline", err)
+	}
+	office := &listProvider{prefix: "office", name: "Office", load: func(context.Context) ([]model.Station, error) {
+		return []model.Station{{Stationuuid: "office:1", Name: "Office Jazz"}}, nil
+	}}
+	d := NewDirectories(a, office)
+	res, err := d.Search(ctx, DefaultSearchParams())
+	if !errors.Is(err, ErrOffline) || len(res) != 1 || res[0].Stationuuid != "office:1" {
+		t.Errorf("offline directories Search = %+v, %v", res, err)
+	}
+	res, err = d.GetStations(ctx, []string{"office:1", "rb-1"})
+	if err != nil || len(res) != 1 || res[0].Stationuuid != "office:1" {
+		t.Errorf("offline directories GetStations = %+v, %v", res, err)
+	}
+
+	l, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not listen on %s again: %v", addr, err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	select {
+	case online := <-a.StatusUpdates():
+		if !online || !a.Online() {
+			t.Fatal("expected to be back online")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not reconnect")
+	}
+	if _, err := a.TopStations(ctx); err != nil {
+		t.Errorf("TopStations err = %v", err)
+	}
+}
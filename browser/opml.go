@@ -0,0 +1,179 @@
+package browser
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+// OPMLDirectory browses a hierarchical OPML directory, like the RadioTime
+// ones: link outlines open sub-menus and audio outlines are stations. The
+// directory is too large to be loaded at once, so its searches and lookups
+// only cover the stations of the root menu and of the menus already opened.
+type OPMLDirectory struct {
+	*listProvider
+	root   string
+	client *http.Client
+
+	mu sync.Mutex
+	// known stations by ID, in the order they were seen
+	known map[string]model.Station
+	order []string
+}
+
+// OPMLMenu is an OPML document, or an outline with nested outlines.
+type OPMLMenu struct {
+	Title    string
+	Links    []OPMLLink
+	Stations []model.Station
+}
+
+// OPMLLink opens a sub-menu, either from another document or nested in the
+// current one.
+type OPMLLink struct {
+	Text string
+	// location is the resolved document URL or file path, empty for a
+	// nested menu
+	location string
+	outlines []opmlOutline
+}
+
+// NewOPMLDirectory returns the provider of the OPML directory at root, an
+// HTTP(S) URL or a file path.
+func NewOPMLDirectory(prefix string, name string, root string, client *http.Client) *OPMLDirectory {
+	d := &OPMLDirectory{
+		root:   root,
+		client: client,
+		known:  make(map[string]model.Station),
+	}
+	d.listProvider = &listProvider{prefix: prefix, name: name, load: d.knownStations}
+	return d
+}
+
+// Root returns the menu of the root document.
+func (d *OPMLDirectory) Root(ctx context.Context) (*OPMLMenu, error) {
+	return d.Open(ctx, OPMLLink{Text: d.name, location: d.root})
+}
+
+// Open returns the sub-menu of the link.
+func (d *OPMLDirectory) Open(ctx context.Context, l OPMLLink) (*OPMLMenu, error) {
+	if l.location == "" {
+		return d.menu(l.Text, "", l.outlines), nil
+	}
+	b, err := d.read(ctx, l.location)
+	if err != nil {
+		return nil, err
+	}
+	var doc opml
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OPML directory: %w", err)
+	}
+	title := strings.TrimSpace(doc.Title)
+	if title == "" {
+		title = l.Text
+	}
+	return d.menu(title, l.location, doc.Outlines), nil
+}
+
+func (d *OPMLDirectory) read(ctx context.Context, location string) ([]byte, error) {
+	if isHTTPURL(location) {
+		return fetchDirectory(ctx, d.client, location)
+	}
+	b, err := os.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+	return b, nil
+}
+
+// menu maps the outlines to links and stations; links are resolved relative
+// to the location of their document.
+func (d *OPMLDirectory) menu(title string, location string, outlines []opmlOutline) *OPMLMenu {
+	m := &OPMLMenu{Title: title}
+	for _, ol := range outlines {
+		text := strings.TrimSpace(ol.Text)
+		switch {
+		case strings.EqualFold(ol.Type, "audio") && ol.URL != "":
+			s := model.Station{
+				Stationuuid: StationID(d.prefix, urlID(ol.URL)),
+				Name:        text,
+				URL:         ol.URL,
+				Bitrate:     ol.Bitrate,
+				Codec:       strings.ToUpper(strings.TrimSpace(strings.Split(ol.Formats, ",")[0])),
+				Tags:        title,
+			}
+			m.Stations = append(m.Stations, s)
+		case strings.EqualFold(ol.Type, "link") && ol.URL != "":
+			if l, ok := resolveLocation(location, ol.URL); ok {
+				m.Links = append(m.Links, OPMLLink{Text: text, location: l})
+			}
+		case len(ol.Outlines) > 0:
+			m.Links = append(m.Links, OPMLLink{Text: text, location: "", outlines: ol.Outlines})
+		}
+	}
+	d.remember(m.Stations)
+	return m
+}
+
+func (d *OPMLDirectory) remember(stations []model.Station) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range stations {
+		if _, ok := d.known[s.Stationuuid]; !ok {
+			d.order = append(d.order, s.Stationuuid)
+		}
+		d.known[s.Stationuuid] = s
+	}
+}
+
+// knownStations loads the root menu once, and returns the stations seen so far.
+func (d *OPMLDirectory) knownStations(ctx context.Context) ([]model.Station, error) {
+	d.mu.Lock()
+	empty := len(d.known) == 0
+	d.mu.Unlock()
+	if empty {
+		if _, err := d.Root(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	res := make([]model.Station, 0, len(d.order))
+	for _, id := range d.order {
+		res = append(res, d.known[id])
+	}
+	return res, nil
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// resolveLocation resolves a link found in the document at base. Links of a
+// remote document are resolved as URLs and never refer to local files.
+func resolveLocation(base string, link string) (string, bool) {
+	link = strings.TrimSpace(link)
+	if isHTTPURL(base) {
+		b, _ := url.Parse(base)
+		l, err := url.Parse(link)
+		if err != nil {
+			return "", false
+		}
+		res := b.ResolveReference(l).String()
+		return res, isHTTPURL(res)
+	}
+	if isHTTPURL(link) || filepath.IsAbs(link) {
+		return link, true
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(link)), true
+}
@@ -0,0 +1,121 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_OPMLDirectory_Remote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/root.opml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><opml version="1.0"><head><title>Radio</title></head><body>
+				<outline type="link" text="By Genre" URL="genres/index.opml"/>
+				<outline type="link" text="Music" URL="/Browse.ashx?c=music"/>
+				<outline type="link" text="Passwords" URL="file:///etc/passwd"/>
+				<outline type="audio" text="Home FM" URL="http://radio.local/home" bitrate="64" formats="mp3"/>
+				<outline type="text" text="No stations here"/>
+			</body></opml>`))
+		case "/genres/index.opml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><opml version="1.0"><head><title>Genres</title></head><body>
+				<outline text="Jazz">
+					<outline type="audio" text="Smooth" URL="http://radio.local/smooth" bitrate="128" formats="aac,mp3"/>
+				</outline>
+			</body></opml>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	d := NewOPMLDirectory("tune", "Tune", srv.URL+"/root.opml", srv.Client())
+	root, err := d.Root(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Title != "Radio" || len(root.Links) != 2 || len(root.Stations) != 1 {
+		t.Fatalf("root = %+v", root)
+	}
+	// absolute paths of a remote document refer to its host
+	if want := srv.URL + "/Browse.ashx?c=music"; root.Links[1].location != want {
+		t.Errorf("absolute link location = %q, want %q", root.Links[1].location, want)
+	}
+	if s := root.Stations[0]; s.Name != "Home FM" || s.Codec != "MP3" || s.Bitrate != 64 || IDPrefix(s.Stationuuid) != "tune" {
+		t.Errorf("root station = %+v", s)
+	}
+
+	genres, err := d.Open(ctx, root.Links[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genres.Title != "Genres" || len(genres.Links) != 1 || genres.Links[0].Text != "Jazz" || len(genres.Stations) != 0 {
+		t.Fatalf("genres = %+v", genres)
+	}
+	jazz, err := d.Open(ctx, genres.Links[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jazz.Stations) != 1 || jazz.Stations[0].Codec != "AAC" || jazz.Stations[0].Tags != "Jazz" {
+		t.Fatalf("jazz = %+v", jazz)
+	}
+
+	// stations of the opened menus are known to the searches and lookups
+	s := DefaultSearchParams()
+	s.TagList = "jazz"
+	res, err := d.Search(ctx, s)
+	if err != nil || len(res) != 1 || res[0].Name != "Smooth" {
+		t.Errorf("Search = %+v, %v", res, err)
+	}
+	res, err = d.GetStations(ctx, []string{jazz.Stations[0].Stationuuid, root.Stations[0].Stationuuid})
+	if err != nil || len(res) != 2 {
+		t.Errorf("GetStations = %+v, %v", res, err)
+	}
+
+	if _, err := d.Open(ctx, OPMLLink{Text: "Missing", location: srv.URL + "/missing.opml"}); err == nil {
+		t.Error("missing menu error = nil")
+	}
+}
+
+func Test_OPMLDirectory_Local(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"root.opml": `<opml><body><outline type="link" text="News" URL="sub/news.opml"/></body></opml>`,
+		"sub/news.opml": `<opml><body>
+			<outline type="audio" text="World News" URL="http://radio.local/news"/>
+			<outline type="link" text="Back" URL="../root.opml"/>
+		</body></opml>`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	d := NewOPMLDirectory("local", "Local", filepath.Join(dir, "root.opml"), nil)
+	// the lookups load the root menu
+	stations, err := d.Stations(ctx)
+	if err != nil || len(stations) != 0 {
+		t.Fatalf("Stations = %+v, %v", stations, err)
+	}
+	root, err := d.Root(ctx)
+	if err != nil || len(root.Links) != 1 || root.Title != "Local" {
+		t.Fatalf("root = %+v, %v", root, err)
+	}
+	news, err := d.Open(ctx, root.Links[0])
+	if err != nil || len(news.Stations) != 1 || len(news.Links) != 1 {
+		t.Fatalf("news = %+v, %v", news, err)
+	}
+	if news.Links[0].location != filepath.Join(dir, "root.opml") {
+		t.Errorf("relative link = %q", news.Links[0].location)
+	}
+}
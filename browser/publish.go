@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+const (
+	streamCheckTimeout = 10 * time.Second
+	streamCheckBytes   = 4096
+)
+
+var (
+	errStreamEmpty   = errors.New("stream sent no data")
+	errStreamContent = errors.New("stream is not audio")
+	errAddStation    = errors.New("add station request error")
+)
+
+// streamContentTypes are the accepted non-audio content types of streams and playlists.
+var streamContentTypes = []string{
+	"application/ogg",
+	"application/octet-stream",
+	"application/vnd.apple.mpegurl",
+	"application/x-mpegurl",
+	"application/pls+xml",
+	"video/mp2t",
+}
+
+// CheckStream verifies that streamURL is reachable and serves audio by
+// reading its first bytes.
+func (a *API) CheckStream(ctx context.Context, streamURL string) error {
+	log := slog.With("method", "Api.CheckStream")
+	ctx, cancel := context.WithTimeout(ctx, streamCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("sonicradio/%s", a.cfg.Version))
+	res, err := a.client.Do(req)
+	if err != nil {
+		// SHOUTcast v1 servers reply with an "ICY 200 OK" status line
+		if strings.Contains(err.Error(), `malformed HTTP version "ICY"`) {
+			log.Info("ICY stream", "url", streamURL)
+			return nil
+		}
+		return fmt.Errorf("stream request: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("stream status: %s", res.Status)
+	}
+
+	ct, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if ct != "" && !strings.HasPrefix(ct, "audio/") && !slices.Contains(streamContentTypes, ct) {
+		return fmt.Errorf("%w: %s", errStreamContent, ct)
+	}
+	n, err := io.CopyN(io.Discard, res.Body, streamCheckBytes)
+	if n == 0 {
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("stream read: %w", err)
+		}
+		return errStreamEmpty
+	}
+	log.Info("stream ok", "url", streamURL, "contentType", ct)
+	return nil
+}
+
+// AddStation publishes s to radio-browser and returns the new station UUID.
+// The request is not retried, to avoid adding the station twice.
+func (a *API) AddStation(ctx context.Context, s model.Station) (string, error) {
+	log := slog.With("method", "Api.AddStation")
+	form := url.Values{}
+	form.Set("name", s.Name)
+	form.Set("url", s.URL)
+	form.Set("homepage", s.Homepage)
+	form.Set("countrycode", strings.ToUpper(s.Countrycode))
+	form.Set("state", s.State)
+	form.Set("language", s.Language)
+	form.Set("tags", s.Tags)
+
+	res, err := a.doServerRequest(ctx, http.MethodPost, urlAdd, []byte(form.Encode()))
+	if err != nil {
+		log.Error("", "request error", err)
+		return "", errAddStation
+	}
+	log.Info(string(res))
+	var addRes model.AddStationResponse
+	if err := json.Unmarshal(res, &addRes); err != nil {
+		return "", errAddStation
+	}
+	if !addRes.Ok || addRes.UUID == "" {
+		return "", fmt.Errorf("radio-browser: %s", addRes.Message)
+	}
+	return addRes.UUID, nil
+}
@@ -0,0 +1,87 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+func Test_CheckStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/audio":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			_, _ = w.Write(make([]byte, 1024))
+		case "/empty":
+			w.Header().Set("Content-Type", "audio/aac")
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.CheckStream(ctx, srv.URL+"/audio"); err != nil {
+		t.Errorf("audio stream error = %v", err)
+	}
+	if err := a.CheckStream(ctx, srv.URL+"/empty"); !errors.Is(err, errStreamEmpty) {
+		t.Errorf("empty stream error = %v", err)
+	}
+	if err := a.CheckStream(ctx, srv.URL+"/page"); !errors.Is(err, errStreamContent) {
+		t.Errorf("html page error = %v", err)
+	}
+	if err := a.CheckStream(ctx, srv.URL+"/missing"); err == nil {
+		t.Error("missing stream error = nil")
+	}
+}
+
+func Test_AddStation(t *testing.T) {
+	var posted int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != urlAdd {
+			return
+		}
+		posted++
+		_ = r.ParseForm()
+		if r.PostForm.Get("url") == "" {
+			_, _ = w.Write([]byte(`{"ok":false,"message":"url is empty","uuid":""}`))
+			return
+		}
+		if r.PostForm.Get("name") != "Custom" || r.PostForm.Get("countrycode") != "DE" {
+			t.Errorf("add form = %v", r.PostForm)
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"message":"added station successfully","uuid":"new-uuid"}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uuid, err := a.AddStation(ctx, model.Station{Name: "Custom", URL: "http://example.com/stream", Countrycode: "de"})
+	if err != nil || uuid != "new-uuid" {
+		t.Errorf("AddStation = %q, %v", uuid, err)
+	}
+	if _, err := a.AddStation(ctx, model.Station{Name: "Custom"}); err == nil || err.Error() != "radio-browser: url is empty" {
+		t.Errorf("AddStation error = %v", err)
+	}
+	if posted != 2 {
+		t.Errorf("add requests = %d, want 2", posted)
+	}
+}
@@ -0,0 +1,252 @@
+package browser
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+const (
+	favoriteWeight = 3.0
+	// listenWeightUnit of listening time weighs as much as a favorite, up to
+	// maxListenWeight.
+	listenWeightUnit = 2 * time.Hour
+	maxListenWeight  = 2 * favoriteWeight
+
+	// candidate searches for the top preferences
+	recommendTopTags      = 4
+	recommendTopLanguages = 1
+	recommendTopCountries = 1
+	recommendSearchLimit  = 100
+)
+
+var ErrNoPreferences = errors.New("add favorites or listen to some stations to get recommendations")
+
+// Profile holds the listener preferences derived from the favorite and played
+// stations, each weighted by how much they are liked.
+type Profile struct {
+	tags      map[string]float64
+	languages map[string]float64
+	countries map[string]float64
+	codecs    map[string]float64
+	// bitrate is the weighted mean of the known bitrates
+	bitrate float64
+	// known stations are not recommended
+	known map[string]bool
+}
+
+// NewProfile builds the profile of the favorites and of the played stations,
+// weighted by their listening time.
+func NewProfile(favorites []model.Station, played []model.Station, listened map[string]time.Duration) *Profile {
+	p := &Profile{
+		tags:      make(map[string]float64),
+		languages: make(map[string]float64),
+		countries: make(map[string]float64),
+		codecs:    make(map[string]float64),
+		known:     make(map[string]bool),
+	}
+	var bitrateSum, bitrateWeight float64
+	add := func(s model.Station, w float64) {
+		p.known[s.Stationuuid] = true
+		if w <= 0 {
+			return
+		}
+		for _, t := range splitValues(s.Tags) {
+			p.tags[t] += w
+		}
+		for _, l := range splitValues(s.Language) {
+			p.languages[l] += w
+		}
+		if cc := strings.ToUpper(strings.TrimSpace(s.Countrycode)); cc != "" {
+			p.countries[cc] += w
+		}
+		if c := strings.ToLower(strings.TrimSpace(s.Codec)); c != "" {
+			p.codecs[c] += w
+		}
+		if s.Bitrate > 0 {
+			bitrateSum += w * float64(s.Bitrate)
+			bitrateWeight += w
+		}
+	}
+
+	for _, s := range favorites {
+		add(s, favoriteWeight+listenWeight(listened[s.Stationuuid]))
+	}
+	for _, s := range played {
+		if !p.known[s.Stationuuid] {
+			add(s, listenWeight(listened[s.Stationuuid]))
+		}
+	}
+	if bitrateWeight > 0 {
+		p.bitrate = bitrateSum / bitrateWeight
+	}
+	for _, m := range []map[string]float64{p.tags, p.languages, p.countries, p.codecs} {
+		normalize(m)
+	}
+	return p
+}
+
+func listenWeight(d time.Duration) float64 {
+	return min(float64(d)/float64(listenWeightUnit)*favoriteWeight, maxListenWeight)
+}
+
+// normalize scales the weights to at most 1.
+func normalize(m map[string]float64) {
+	var top float64
+	for _, w := range m {
+		top = max(top, w)
+	}
+	if top == 0 {
+		return
+	}
+	for k := range m {
+		m[k] /= top
+	}
+}
+
+func splitValues(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Empty reports whether there are no preferences to recommend from.
+func (p *Profile) Empty() bool {
+	return len(p.tags) == 0 && len(p.languages) == 0 && len(p.countries) == 0
+}
+
+// Score rates how well s matches the preferences: mostly by tag overlap, then
+// by language, country, codec and bitrate, with popularity as a tie breaker.
+func (p *Profile) Score(s model.Station) float64 {
+	var tagScore float64
+	tags := splitValues(s.Tags)
+	for _, t := range tags {
+		tagScore += p.tags[t]
+	}
+	if len(tags) > 0 {
+		// favor focused tag lists over long ones matching by chance
+		tagScore /= math.Sqrt(float64(len(tags)))
+	}
+
+	var langScore float64
+	for _, l := range splitValues(s.Language) {
+		langScore = max(langScore, p.languages[l])
+	}
+	countryScore := p.countries[strings.ToUpper(strings.TrimSpace(s.Countrycode))]
+	codecScore := p.codecs[strings.ToLower(strings.TrimSpace(s.Codec))]
+
+	var bitrateScore float64
+	if p.bitrate > 0 && s.Bitrate > 0 {
+		bitrateScore = 1 - min(math.Abs(float64(s.Bitrate)-p.bitrate)/p.bitrate, 1)
+	}
+	popularity := math.Log10(float64(max(s.Votes, 0))+1) / 10
+
+	return 3*tagScore + 1.5*langScore + countryScore + 0.5*codecScore + 0.5*bitrateScore + popularity
+}
+
+// Rank returns the n best scoring candidates that are not already known.
+func (p *Profile) Rank(candidates []model.Station, n int) []model.Station {
+	type scored struct {
+		station model.Station
+		score   float64
+	}
+	var res []scored
+	seen := make(map[string]bool)
+	for _, s := range candidates {
+		if p.known[s.Stationuuid] || seen[s.Stationuuid] {
+			continue
+		}
+		seen[s.Stationuuid] = true
+		res = append(res, scored{s, p.Score(s)})
+	}
+	slices.SortStableFunc(res, func(a, b scored) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	stations := make([]model.Station, 0, min(n, len(res)))
+	for i := 0; i < len(res) && i < n; i++ {
+		stations = append(stations, res[i].station)
+	}
+	return stations
+}
+
+// queries returns the searches for the candidates of the top preferences.
+func (p *Profile) queries() []SearchParams {
+	var res []SearchParams
+	for _, t := range topKeys(p.tags, recommendTopTags) {
+		s := DefaultSearchParams()
+		s.TagList = t
+		res = append(res, s)
+	}
+	for _, l := range topKeys(p.languages, recommendTopLanguages) {
+		s := DefaultSearchParams()
+		s.Language = l
+		s.LanguageExact = true
+		res = append(res, s)
+	}
+	for _, c := range topKeys(p.countries, recommendTopCountries) {
+		s := DefaultSearchParams()
+		s.CountryCode = c
+		res = append(res, s)
+	}
+	for i := range res {
+		res[i].Limit = recommendSearchLimit
+	}
+	return res
+}
+
+func topKeys(m map[string]float64, n int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(m[b], m[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return keys[:min(n, len(keys))]
+}
+
+// Recommend searches candidates for the top preferences of p and returns the
+// n best matches. Failed searches are skipped unless all of them fail.
+func (a *API) Recommend(ctx context.Context, p *Profile, n int) ([]model.Station, error) {
+	log := slog.With("method", "Api.Recommend")
+	if p.Empty() {
+		return nil, ErrNoPreferences
+	}
+	var candidates []model.Station
+	var lastErr error
+	queries := p.queries()
+	failed := 0
+	for _, q := range queries {
+		stations, err := a.stationSearch(ctx, q)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Error("candidate search", "error", err)
+			lastErr = err
+			failed++
+			continue
+		}
+		candidates = append(candidates, stations...)
+	}
+	if failed == len(queries) {
+		return nil, lastErr
+	}
+	res := p.Rank(candidates, n)
+	log.Info("", "candidates", len(candidates), "recommended", len(res))
+	return res, nil
+}
@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+func Test_Profile_Rank(t *testing.T) {
+	favorites := []model.Station{
+		{Stationuuid: "fav", Tags: "jazz,smooth jazz", Language: "english", Countrycode: "US", Codec: "AAC", Bitrate: 128},
+	}
+	played := []model.Station{
+		{Stationuuid: "played", Tags: "Jazz, bebop", Language: "english", Countrycode: "US", Codec: "MP3", Bitrate: 192},
+		{Stationuuid: "once", Tags: "metal", Language: "german", Countrycode: "DE"},
+	}
+	listened := map[string]time.Duration{"played": time.Hour}
+	p := NewProfile(favorites, played, listened)
+
+	candidates := []model.Station{
+		{Stationuuid: "fav", Tags: "jazz"},
+		{Stationuuid: "metal", Tags: "metal", Language: "german", Countrycode: "DE", Votes: 10000},
+		{Stationuuid: "jazz", Tags: "jazz,bebop", Language: "english", Countrycode: "US", Codec: "AAC", Bitrate: 128},
+		{Stationuuid: "pop", Tags: "pop", Language: "english", Countrycode: "GB", Bitrate: 128},
+		{Stationuuid: "jazz", Tags: "jazz,bebop"},
+		{Stationuuid: "news", Tags: "news", Countrycode: "FR"},
+	}
+	got := p.Rank(candidates, 3)
+	var uuids []string
+	for _, s := range got {
+		uuids = append(uuids, s.Stationuuid)
+	}
+	if !slices.Equal(uuids, []string{"jazz", "pop", "metal"}) {
+		t.Errorf("Rank = %v", uuids)
+	}
+
+	// a station played without listening time adds no preferences
+	if p.tags["metal"] != 0 {
+		t.Errorf("metal tag weight = %v", p.tags["metal"])
+	}
+
+	queries := p.queries()
+	if len(queries) == 0 || queries[0].TagList != "jazz" || queries[0].Limit != recommendSearchLimit {
+		t.Errorf("queries = %+v", queries)
+	}
+}
+
+func Test_Recommend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != urlStations {
+			return
+		}
+		_ = r.ParseForm()
+		if r.PostForm.Get("tagList") == "rock" {
+			_, _ = w.Write([]byte(`[{"stationuuid":"liked"},{"stationuuid":"rock","tags":"rock"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Recommend(ctx, NewProfile(nil, nil, nil), 10); !errors.Is(err, ErrNoPreferences) {
+		t.Errorf("empty profile error = %v", err)
+	}
+
+	p := NewProfile([]model.Station{{Stationuuid: "liked", Tags: "rock"}}, nil, nil)
+	res, err := a.Recommend(ctx, p, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Stationuuid != "rock" {
+		t.Errorf("Recommend = %+v", res)
+	}
+}
+
+func Test_NewProfile_directoryHistory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != urlStationsByUUID {
+			return
+		}
+		_ = r.ParseForm()
+		if r.PostForm.Get("uuids") != "rb-1" {
+			t.Errorf("radio-browser uuids = %q", r.PostForm.Get("uuids"))
+		}
+		_, _ = w.Write([]byte(`[{"stationuuid":"rb-1","tags":"jazz"}]`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := NewAPI(ctx, testConfig(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	office := &listProvider{prefix: "office", name: "Office", load: func(context.Context) ([]model.Station, error) {
+		return []model.Station{{Stationuuid: "office:1", Tags: "news"}}, nil
+	}}
+
+	// the history mixes radio-browser and directory stations
+	listened := map[string]time.Duration{"office:1": time.Hour, "rb-1": time.Hour}
+	played, err := NewDirectories(a, office).GetStations(ctx, []string{"office:1", "rb-1"})
+	if err != nil || len(played) != 2 {
+		t.Fatalf("played = %+v, %v", played, err)
+	}
+	p := NewProfile(nil, played, listened)
+	if p.tags["jazz"] == 0 || p.tags["news"] == 0 {
+		t.Errorf("profile tags = %v", p.tags)
+	}
+}
@@ -0,0 +1,113 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+// NewRemoteDirectory returns a provider of the stations served at url, either
+// as a JSON list of stations in the radio-browser format, or as an OPML
+// document whose audio outlines are the stations.
+func NewRemoteDirectory(prefix string, name string, url string, client *http.Client) Provider {
+	return &listProvider{
+		prefix: prefix,
+		name:   name,
+		load: cachedLoad(remoteDirectoryTTL, func(ctx context.Context) ([]model.Station, error) {
+			b, err := fetchDirectory(ctx, client, url)
+			if err != nil {
+				return nil, err
+			}
+			return parseDirectory(prefix, b)
+		}),
+	}
+}
+
+func fetchDirectory(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("directory request: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("directory status: %s", res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// parseDirectory parses a JSON or an OPML station list.
+func parseDirectory(prefix string, b []byte) ([]model.Station, error) {
+	b = bytes.TrimSpace(b)
+	var stations []model.Station
+	if bytes.HasPrefix(b, []byte("<")) {
+		var doc opml
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("invalid OPML directory: %w", err)
+		}
+		stations = doc.stations()
+	} else if err := json.Unmarshal(b, &stations); err != nil {
+		return nil, fmt.Errorf("invalid JSON directory: %w", err)
+	}
+
+	res := make([]model.Station, 0, len(stations))
+	for _, s := range stations {
+		if strings.TrimSpace(s.URL) == "" {
+			continue
+		}
+		id := s.Stationuuid
+		if id == "" {
+			id = urlID(s.URL)
+		}
+		s.Stationuuid = StationID(prefix, id)
+		res = append(res, s)
+	}
+	return uniqueStations(res), nil
+}
+
+type opml struct {
+	Title    string        `xml:"head>title"`
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Type     string        `xml:"type,attr"`
+	Text     string        `xml:"text,attr"`
+	URL      string        `xml:"URL,attr"`
+	Bitrate  int64         `xml:"bitrate,attr"`
+	Formats  string        `xml:"formats,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// stations returns the audio outlines, tagged with the text of their parents.
+func (o opml) stations() []model.Station {
+	var res []model.Station
+	var walk func(outlines []opmlOutline, tags []string)
+	walk = func(outlines []opmlOutline, tags []string) {
+		for _, ol := range outlines {
+			if strings.EqualFold(ol.Type, "audio") && ol.URL != "" {
+				res = append(res, model.Station{
+					Name:    ol.Text,
+					URL:     ol.URL,
+					Bitrate: ol.Bitrate,
+					Tags:    strings.Join(tags, ","),
+				})
+			}
+			if len(ol.Outlines) > 0 {
+				walk(ol.Outlines, append(tags[:len(tags):len(tags)], ol.Text))
+			}
+		}
+	}
+	walk(o.Outlines, nil)
+	return res
+}
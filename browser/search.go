@@ -1,8 +1,15 @@
 package browser
 
 import (
+	"cmp"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/dancnb/sonicradio/geo"
+	"github.com/dancnb/sonicradio/model"
 )
 
 type OrderBy string
@@ -31,37 +38,203 @@ const (
 
 const DefLimit = 30
 
+// nearbyLimit is the number of nearby stations, sorted by distance in a single page.
+const nearbyLimit = 200
+
 type SearchParams struct {
-	Name     string
-	TagList  string
-	Country  string
-	State    string
-	Language string
-	Limit    int
-	Order    OrderBy
-	Reverse  bool
-
-	Offset int
-	// CountryCode string
-	// TagExact    string //always "true"
+	Name     string  `json:"name,omitempty"`
+	TagList  string  `json:"tagList,omitempty"`
+	Country  string  `json:"country,omitempty"`
+	State    string  `json:"state,omitempty"`
+	Language string  `json:"language,omitempty"`
+	Limit    int     `json:"limit,omitempty"`
+	Order    OrderBy `json:"order,omitempty"`
+	Reverse  bool    `json:"reverse"` // true by default, false must be encoded
+
+	Offset int `json:"-"`
+
+	CountryCode string `json:"countryCode,omitempty"`
+	// Codec is a single codec or a comma separated list of alternatives.
+	Codec         string `json:"codec,omitempty"`
+	NameExact     bool   `json:"nameExact,omitempty"`
+	TagExact      bool   `json:"tagExact"` // true by default, false must be encoded
+	CountryExact  bool   `json:"countryExact,omitempty"`
+	StateExact    bool   `json:"stateExact,omitempty"`
+	LanguageExact bool   `json:"languageExact,omitempty"`
+	// BitrateMin and BitrateMax are in kbps, 0 for no limit.
+	BitrateMin int `json:"bitrateMin,omitempty"`
+	BitrateMax int `json:"bitrateMax,omitempty"`
+	// IsHTTPS, HasGeoInfo and HasExtendedInfo only filter when true.
+	IsHTTPS         bool `json:"isHttps,omitempty"`
+	HasGeoInfo      bool `json:"hasGeoInfo,omitempty"`
+	HasExtendedInfo bool `json:"hasExtendedInfo,omitempty"`
+	// Near limits the stations to NearRadiusKm around a point.
+	Near         *geo.Point `json:"-"`
+	NearRadiusKm int        `json:"-"`
+	// Directory restricts the search to the provider with this prefix.
+	Directory string `json:"directory,omitempty"`
 	// HideBroken  string //always "true"
 }
 
 func DefaultSearchParams() SearchParams {
 	return SearchParams{
-		Order:   Votes,
-		Reverse: true,
-		Offset:  0,
-		Limit:   DefLimit,
+		Order:    Votes,
+		Reverse:  true,
+		Offset:   0,
+		Limit:    DefLimit,
+		TagExact: true,
+	}
+}
+
+// Summary describes the filters of the search in a single line, e.g. for
+// the search history.
+func (p SearchParams) Summary() string {
+	var parts []string
+	add := func(label string, v string, exact bool) {
+		if v = strings.TrimSpace(v); v == "" {
+			return
+		}
+		if exact {
+			v = strconv.Quote(v)
+		}
+		parts = append(parts, label+" "+v)
+	}
+	add("name", p.Name, p.NameExact)
+	add("tags", p.TagList, p.TagExact)
+	add("country", p.Country, p.CountryExact)
+	add("country code", strings.ToUpper(p.CountryCode), false)
+	add("state", p.State, p.StateExact)
+	add("language", p.Language, p.LanguageExact)
+	add("codec", p.Codec, false)
+	switch {
+	case p.BitrateMin > 0 && p.BitrateMax > 0:
+		parts = append(parts, fmt.Sprintf("%d-%d kbps", p.BitrateMin, p.BitrateMax))
+	case p.BitrateMin > 0:
+		parts = append(parts, fmt.Sprintf(">= %d kbps", p.BitrateMin))
+	case p.BitrateMax > 0:
+		parts = append(parts, fmt.Sprintf("<= %d kbps", p.BitrateMax))
+	}
+	if p.IsHTTPS {
+		parts = append(parts, "https")
+	}
+	if p.HasGeoInfo {
+		parts = append(parts, "geo info")
+	}
+	if p.HasExtendedInfo {
+		parts = append(parts, "extended info")
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "all stations")
+	}
+
+	order := string(cmp.Or(p.Order, Votes))
+	if p.Reverse {
+		order += " desc"
+	}
+	parts = append(parts, "by "+order)
+	return strings.Join(parts, ", ")
+}
+
+// codecs returns the alternative codecs of the search.
+func (p SearchParams) codecs() []string {
+	var res []string
+	for _, c := range strings.Split(p.Codec, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			res = append(res, c)
+		}
 	}
+	return res
+}
+
+// Pageable reports whether the next page of the results can be loaded by
+// increasing Offset. A list of codecs is searched one codec at a time and
+// the merged results are re-sorted, so they are not paged.
+func (p SearchParams) Pageable() bool {
+	return len(p.codecs()) <= 1
 }
 
 func (p SearchParams) toFormData() string {
-	fname := strings.Join(strings.Fields(p.Name), "+")
-	fTags := strings.Join(strings.Fields(p.TagList), "+")
+	v := url.Values{}
+	v.Set("name", strings.Join(strings.Fields(p.Name), " "))
+	v.Set("tagList", strings.Join(strings.Fields(p.TagList), " "))
+	v.Set("country", p.Country)
+	v.Set("state", p.State)
+	v.Set("language", p.Language)
+	v.Set("nameExact", boolString(p.NameExact))
+	v.Set("tagExact", boolString(p.TagExact))
+	v.Set("countryExact", boolString(p.CountryExact))
+	v.Set("offset", strconv.Itoa(p.Offset))
+	v.Set("limit", strconv.Itoa(p.Limit))
+	v.Set("order", string(p.Order))
+	v.Set("reverse", boolString(p.Reverse))
+	v.Set("bitrateMin", strconv.Itoa(max(p.BitrateMin, 0)))
+	v.Set("bitrateMax", "")
+	if p.BitrateMax > 0 {
+		v.Set("bitrateMax", strconv.Itoa(p.BitrateMax))
+	}
+	v.Set("hidebroken", "true")
+	if p.CountryCode != "" {
+		v.Set("countrycode", strings.ToUpper(p.CountryCode))
+	}
+	if p.Codec != "" {
+		v.Set("codec", p.Codec)
+	}
+	if p.StateExact {
+		v.Set("stateExact", "true")
+	}
+	if p.LanguageExact {
+		v.Set("languageExact", "true")
+	}
+	if p.IsHTTPS {
+		v.Set("is_https", "true")
+	}
+	if p.HasGeoInfo {
+		v.Set("has_geo_info", "true")
+	}
+	if p.HasExtendedInfo {
+		v.Set("has_extended_info", "true")
+	}
+	if p.Near != nil {
+		v.Set("geo_lat", strconv.FormatFloat(p.Near.Lat, 'f', -1, 64))
+		v.Set("geo_long", strconv.FormatFloat(p.Near.Long, 'f', -1, 64))
+		if p.NearRadiusKm > 0 {
+			v.Set("geo_distance", strconv.Itoa(p.NearRadiusKm*1000))
+		}
+	}
+	return v.Encode()
+}
 
-	return fmt.Sprintf("name=%s&tagList=%s&country=%s&countryExact=false&state=%s&language=%s&tagExact=true&offset=%d&limit=%d&order=%s&bitrateMin=0&bitrateMax=&reverse=%s&hidebroken=true",
-		fname, fTags, p.Country, p.State, p.Language, p.Offset, p.Limit, p.Order, boolString(p.Reverse))
+// sortStations orders stations merged from several searches like the API would.
+func sortStations(stations []model.Station, order OrderBy, reverse bool) {
+	var cmpFn func(a, b model.Station) int
+	switch order {
+	case Votes:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Votes, b.Votes) }
+	case Clickcount:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Clickcount, b.Clickcount) }
+	case Clicktrend:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Clicktrend, b.Clicktrend) }
+	case Bitrate:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Bitrate, b.Bitrate) }
+	case Name:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) }
+	case Tags:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Tags, b.Tags) }
+	case CountryOrder:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Country, b.Country) }
+	case LanguageOrder:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Language, b.Language) }
+	case Codec:
+		cmpFn = func(a, b model.Station) int { return cmp.Compare(a.Codec, b.Codec) }
+	default:
+		return
+	}
+	slices.SortStableFunc(stations, func(a, b model.Station) int {
+		if reverse {
+			return cmpFn(b, a)
+		}
+		return cmpFn(a, b)
+	})
 }
 
 func boolString(v bool) string {
@@ -0,0 +1,213 @@
+package browser
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	probeTimeout = 3 * time.Second
+	// latency assumed for servers without a successful request
+	unknownLatency = probeTimeout
+
+	serverCooldownMin = 5 * time.Second
+	serverCooldownMax = 5 * time.Minute
+)
+
+var errServerStatus = errors.New("server error status")
+
+// server is a radio-browser API mirror tracked by a serverPool.
+type server struct {
+	url string // base URL
+	// fallback is the plain HTTP base URL tried once the HTTPS one fails.
+	fallback string
+
+	latency  time.Duration // moving average, 0 if unknown
+	failures int           // consecutive failures
+	cooldown time.Time     // not picked before this time
+}
+
+func (s *server) score() time.Duration {
+	if s.latency == 0 {
+		return unknownLatency
+	}
+	return s.latency
+}
+
+// serverPool picks the lowest-latency healthy server and keeps failing
+// servers out of rotation for an exponentially increasing cooldown.
+type serverPool struct {
+	mu      sync.Mutex
+	servers []*server
+	now     func() time.Time
+}
+
+func newServerPool(servers []*server) *serverPool {
+	return &serverPool{servers: servers, now: time.Now}
+}
+
+// discoveredServer returns a server for a public mirror host name or IP,
+// using HTTPS with a plain HTTP fallback. IP addresses use plain HTTP only,
+// since the mirror certificates are issued for host names.
+func discoveredServer(host string) *server {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+		return &server{url: "http://" + host}
+	}
+	host = strings.TrimSuffix(host, ".")
+	return &server{url: "https://" + host, fallback: "http://" + host}
+}
+
+func (p *serverPool) set(servers []*server) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.servers = servers
+}
+
+func (p *serverPool) url(s *server) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return s.url
+}
+
+func (p *serverPool) urls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	res := make([]string, len(p.servers))
+	for i, s := range p.servers {
+		res[i] = s.url
+	}
+	return res
+}
+
+// pick returns the healthy server with the lowest latency, or the one whose
+// cooldown ends first if all servers are failing. It returns nil for an
+// empty pool.
+func (p *serverPool) pick() (*server, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var best *server
+	for _, s := range p.servers {
+		if s.cooldown.After(now) {
+			continue
+		}
+		if best == nil || s.score() < best.score() {
+			best = s
+		}
+	}
+	if best == nil {
+		for _, s := range p.servers {
+			if best == nil || s.cooldown.Before(best.cooldown) {
+				best = s
+			}
+		}
+	}
+	if best == nil {
+		return nil, ""
+	}
+	return best, best.url
+}
+
+// healthy reports whether any server is out of cooldown.
+func (p *serverPool) healthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	return slices.ContainsFunc(p.servers, func(s *server) bool { return !s.cooldown.After(now) })
+}
+
+func (p *serverPool) success(s *server, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s.latency == 0 {
+		s.latency = latency
+	} else {
+		s.latency = (3*s.latency + latency) / 4
+	}
+	s.failures = 0
+	s.cooldown = time.Time{}
+}
+
+// failure records a failed request to url. The first transport error on an
+// HTTPS server with a fallback switches it to plain HTTP instead of putting
+// it in cooldown.
+func (p *serverPool) failure(s *server, url string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s.url != url {
+		// already handled by a concurrent request
+		return
+	}
+	if s.fallback != "" && !errors.Is(err, errServerStatus) {
+		slog.Info("serverPool: falling back to HTTP", "server", s.url, "error", err)
+		s.url, s.fallback = s.fallback, ""
+		return
+	}
+	s.failures++
+	cooldown := serverCooldownMax
+	if s.failures <= 8 {
+		cooldown = min(serverCooldownMin<<(s.failures-1), serverCooldownMax)
+	}
+	s.cooldown = p.now().Add(cooldown)
+	slog.Info("serverPool: server cooldown", "server", s.url, "failures", s.failures, "cooldown", cooldown)
+}
+
+// probe measures the latency of all servers concurrently, retrying over the
+// HTTP fallback if the HTTPS request fails.
+func (p *serverPool) probe(ctx context.Context, req func(ctx context.Context, url string) error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	p.mu.Lock()
+	servers := slices.Clone(p.servers)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url := p.url(s)
+			for {
+				start := time.Now()
+				err := req(ctx, url)
+				if err == nil {
+					p.success(s, time.Since(start))
+					return
+				}
+				if ctx.Err() != nil {
+					// too slow, the latency stays unknown
+					return
+				}
+				p.failure(s, url, err)
+				if next := p.url(s); next != url {
+					url = next
+					continue
+				}
+				return
+			}
+		}()
+	}
+	wg.Wait()
+	p.sort()
+}
+
+// sort orders the servers by latency, for logging.
+func (p *serverPool) sort() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	slices.SortStableFunc(p.servers, func(a, b *server) int {
+		return cmp.Compare(a.score(), b.score())
+	})
+}
@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_discoveredServer(t *testing.T) {
+	tests := []struct {
+		host, url, fallback string
+	}{
+		{host: "de1.api.radio-browser.info.", url: "https://de1.api.radio-browser.info", fallback: "http://de1.api.radio-browser.info"},
+		{host: "91.132.145.114", url: "http://91.132.145.114"},
+		{host: "2a01:4f8:1c17:5d8a::1", url: "http://[2a01:4f8:1c17:5d8a::1]"},
+	}
+	for _, tt := range tests {
+		s := discoveredServer(tt.host)
+		if s.url != tt.url || s.fallback != tt.fallback {
+			t.Errorf("discoveredServer(%q) = %q, %q", tt.host, s.url, s.fallback)
+		}
+	}
+}
+
+func Test_serverPool_pick(t *testing.T) {
+	now := time.Now()
+	fast := &server{url: "https://fast", latency: 20 * time.Millisecond}
+	slow := &server{url: "https://slow", latency: 200 * time.Millisecond}
+	unknown := &server{url: "https://unknown"}
+	p := newServerPool([]*server{unknown, slow, fast})
+	p.now = func() time.Time { return now }
+
+	if s, _ := p.pick(); s != fast {
+		t.Fatalf("picked %s, want the lowest latency", s.url)
+	}
+
+	p.failure(fast, fast.url, errServerStatus)
+	p.failure(slow, slow.url, errServerStatus)
+	p.failure(slow, slow.url, errServerStatus)
+	if s, _ := p.pick(); s != unknown {
+		t.Fatalf("picked %s, want the only healthy server", s.url)
+	}
+	if slow.cooldown.Sub(now) != 2*serverCooldownMin {
+		t.Errorf("cooldown %v after 2 failures", slow.cooldown.Sub(now))
+	}
+
+	p.failure(unknown, unknown.url, errServerStatus)
+	p.failure(unknown, unknown.url, errServerStatus)
+	if p.healthy() {
+		t.Error("expected no healthy server")
+	}
+	if s, _ := p.pick(); s != fast {
+		t.Fatalf("picked %s, want the earliest cooldown end", s.url)
+	}
+
+	now = now.Add(serverCooldownMin)
+	p.success(fast, 40*time.Millisecond)
+	if fast.failures != 0 || fast.latency != 25*time.Millisecond {
+		t.Errorf("after success: failures %d, latency %v", fast.failures, fast.latency)
+	}
+	if s, _ := p.pick(); s != fast {
+		t.Fatalf("picked %s after recovery", s.url)
+	}
+}
+
+func Test_serverPool_probe(t *testing.T) {
+	p := newServerPool([]*server{
+		discoveredServer("tls-broken"),
+		discoveredServer("down"),
+		discoveredServer("ok"),
+	})
+	p.probe(context.Background(), func(ctx context.Context, url string) error {
+		switch {
+		case strings.HasPrefix(url, "https://tls-broken"):
+			return errors.New("x509: certificate is not valid")
+		case strings.Contains(url, "down"):
+			return fmt.Errorf("%w: 503 Service Unavailable", errServerStatus)
+		case strings.HasPrefix(url, "https://ok"):
+			time.Sleep(5 * time.Millisecond)
+		}
+		return nil
+	})
+
+	want := []string{"http://tls-broken", "https://ok", "https://down"}
+	if got := p.urls(); !slices.Equal(got, want) {
+		t.Errorf("servers after probe = %v, want %v", got, want)
+	}
+	if s, url := p.pick(); url != "http://tls-broken" && url != "https://ok" {
+		t.Errorf("picked %s", s.url)
+	}
+}
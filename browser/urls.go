@@ -6,6 +6,21 @@ const (
 	urlClickCount     = "/json/url/"
 	urlCountries      = "/json/countries"
 	urlLangs          = "/json/languages"
-	// urlTags           = "/json/tags "
-	urlVote = "/json/vote/"
+	urlStats          = "/json/stats"
+	urlTags           = "/json/tags"
+	urlStates         = "/json/states"
+	urlCodecs         = "/json/codecs"
+	urlVote           = "/json/vote/"
+	urlAdd            = "/json/add"
+	urlChecks         = "/json/checks/"
+	urlCheckSteps     = "/json/checksteps"
+	urlLastClick      = "/json/stations/lastclick/"
+	urlLastChange     = "/json/stations/lastchange/"
+)
+
+const (
+	tagsFormData   = "order=stationcount&reverse=true&hidebroken=true&limit=1000"
+	statesFormData = "order=stationcount&reverse=true&hidebroken=true"
+	codecsFormData = "order=stationcount&reverse=true&hidebroken=true"
+	feedFormData   = "hidebroken=true"
 )
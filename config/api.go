@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var apiServers = flag.String("api", "", "use -api=<url>[,<url>...] to query only the given radio-browser API servers")
+
+var errAPIServerScheme = errors.New("API server scheme must be http or https")
+
+// ParseAPIServer parses a radio-browser API base URL, e.g. http://192.168.1.10:8080.
+func ParseAPIServer(s string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errAPIServerScheme
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing API server host in %q", s)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u, nil
+}
+
+// GetAPIServers returns the radio-browser API base URLs given with the -api
+// flag, or else the ones in the config file. If none are returned, the servers
+// are discovered through DNS.
+func (v *Value) GetAPIServers() ([]string, error) {
+	servers := v.APIServers
+	if *apiServers != "" {
+		servers = strings.Split(*apiServers, ",")
+	}
+	var res []string
+	for _, s := range servers {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		u, err := ParseAPIServer(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API server %q: %w", s, err)
+		}
+		res = append(res, u.String())
+	}
+	return res, nil
+}
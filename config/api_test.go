@@ -0,0 +1,25 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValue_GetAPIServers(t *testing.T) {
+	cfg := &Value{APIServers: []string{"HTTPS://api.example.com/", " http://192.168.1.10:8080 ", ""}}
+	got, err := cfg.GetAPIServers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://api.example.com", "http://192.168.1.10:8080"}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetAPIServers() = %v, want %v", got, want)
+	}
+
+	for _, s := range []string{"api.example.com", "ftp://api.example.com", "http://"} {
+		cfg.APIServers = []string{s}
+		if _, err := cfg.GetAPIServers(); err == nil {
+			t.Errorf("GetAPIServers(%q) expected error", s)
+		}
+	}
+}
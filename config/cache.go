@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const cacheSubDir = "cache"
+
+// Cache configures the disk cache of radio-browser responses.
+// Nil values fall back to the defaults.
+type Cache struct {
+	// MaxSizeMB bounds the cache size, 0 disables the cache.
+	MaxSizeMB           *int `json:"maxSizeMB,omitempty"`
+	SearchTTLMinutes    *int `json:"searchTTLMinutes,omitempty"`
+	StationTTLMinutes   *int `json:"stationTTLMinutes,omitempty"`
+	CountriesTTLMinutes *int `json:"countriesTTLMinutes,omitempty"`
+	LanguagesTTLMinutes *int `json:"languagesTTLMinutes,omitempty"`
+	TagsTTLMinutes      *int `json:"tagsTTLMinutes,omitempty"`
+	StatesTTLMinutes    *int `json:"statesTTLMinutes,omitempty"`
+	CodecsTTLMinutes    *int `json:"codecsTTLMinutes,omitempty"`
+}
+
+func (c Cache) GetMaxSizeBytes() int64 {
+	return int64(intOrDefault(c.MaxSizeMB, DefCacheMaxSizeMB)) * 1024 * 1024
+}
+
+func (c Cache) GetSearchTTL() time.Duration {
+	return time.Duration(intOrDefault(c.SearchTTLMinutes, DefCacheSearchTTLMinutes)) * time.Minute
+}
+
+func (c Cache) GetStationTTL() time.Duration {
+	return time.Duration(intOrDefault(c.StationTTLMinutes, DefCacheStationTTLMinutes)) * time.Minute
+}
+
+func (c Cache) GetCountriesTTL() time.Duration {
+	return time.Duration(intOrDefault(c.CountriesTTLMinutes, DefCacheListTTLMinutes)) * time.Minute
+}
+
+func (c Cache) GetLanguagesTTL() time.Duration {
+	return time.Duration(intOrDefault(c.LanguagesTTLMinutes, DefCacheListTTLMinutes)) * time.Minute
+}
+
+func (c Cache) GetTagsTTL() time.Duration {
+	return time.Duration(intOrDefault(c.TagsTTLMinutes, DefCacheListTTLMinutes)) * time.Minute
+}
+
+func (c Cache) GetStatesTTL() time.Duration {
+	return time.Duration(intOrDefault(c.StatesTTLMinutes, DefCacheListTTLMinutes)) * time.Minute
+}
+
+func (c Cache) GetCodecsTTL() time.Duration {
+	return time.Duration(intOrDefault(c.CodecsTTLMinutes, DefCacheListTTLMinutes)) * time.Minute
+}
+
+func intOrDefault(v *int, def int) int {
+	if v == nil {
+		return def
+	}
+	return max(0, *v)
+}
+
+// CacheDir returns the cache directory inside the config directory, creating it if needed.
+func CacheDir() (string, error) {
+	cfgDirPath, err := getOrCreateConfigDir()
+	if err != nil {
+		return "", err
+	}
+	fp := filepath.Join(cfgDirPath, cacheSubDir)
+	if err := os.MkdirAll(fp, os.ModePerm); err != nil {
+		return "", fmt.Errorf("creating cache dir at path %s: %v", fp, err)
+	}
+	return fp, nil
+}
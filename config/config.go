@@ -21,6 +21,7 @@ import (
 )
 
 var debug = flag.Bool("debug", false, "use -debug arg to log to a file")
+var offline = flag.Bool("offline", false, "use -offline arg to start without connecting to radio-browser")
 
 const (
 	APIReqTimeout     = 10 * time.Second
@@ -34,6 +35,9 @@ const (
 	cfgSubDir       = "sonicRadio"
 	cfgFilename     = "config.json"
 	historyFilename = "history.json"
+	votesFilename   = "votes.json"
+	// podcast episode resume positions
+	positionsFilename = "positions.json"
 
 	favoritesFilename = "favorites.pls"
 	favoritesTmpl     = `[playlist]
@@ -54,6 +58,8 @@ SR_country{{add $index 1}}={{.Country}}
 SR_votes{{add $index 1}}={{.Votes}}
 SR_codec{{add $index 1}}={{.Codec}}
 SR_lastcheckoktime{{add $index 1}}={{.Lastcheckoktime}}
+SR_lastcheckok{{add $index 1}}={{.Lastcheckok}}
+SR_lastchecktime{{add $index 1}}={{.Lastchecktime}}
 SR_clickcount{{add $index 1}}={{.Clickcount}}
 SR_clicktrend{{add $index 1}}={{.Clicktrend}}
 SR_geo_lat{{add $index 1}}={{.GeoLat}}
@@ -70,7 +76,27 @@ const (
 	DefMpdHost = ""
 	DefMpdPort = 6600
 
-	DefInternalBufferSeconds = 0
+	DefInternalBufferSeconds    = 0
+	DefInternalTimeshiftMinutes = 0
+	DefInternalPrebufferSeconds = 3
+
+	DefDeadAirSilenceSeconds     = 15
+	DefDeadAirSilenceThresholdDb = -50.0
+	DefDeadAirStallSeconds       = 8
+
+	DefCacheMaxSizeMB         = 50
+	DefCacheSearchTTLMinutes  = 60
+	DefCacheStationTTLMinutes = 6 * 60
+	DefCacheListTTLMinutes    = 7 * 24 * 60
+
+	DefNearbyRadiusKm = 100
+
+	DefGroupingCodec = "AAC"
+
+	DefSearchHistoryMax = 20
+
+	DefNowPlayingIntervalSeconds = 120
+	DefNowPlayingConcurrency     = 3
 )
 
 type Value struct {
@@ -89,11 +115,38 @@ type Value struct {
 
 	Internal InternalPlayer `json:"internal"`
 
+	// Proxy is an HTTP(S) or SOCKS5 proxy URL for the API and stream connections.
+	Proxy string `json:"proxy,omitempty"`
+	// APIServers are radio-browser API base URLs used instead of the discovered servers.
+	APIServers []string `json:"apiServers,omitempty"`
+	Cache      Cache    `json:"cache"`
+	Location   Location `json:"location"`
+	Grouping   Grouping `json:"grouping"`
+	// Directories are browsed alongside radio-browser.
+	Directories []Directory `json:"directories,omitempty"`
+	// Podcasts are the subscribed podcast feed URLs.
+	Podcasts []string `json:"podcasts,omitempty"`
+	// SearchHistory are the last submitted searches, newest first.
+	SearchHistory []json.RawMessage `json:"searchHistory,omitempty"`
+	// Searches are the named saved searches.
+	Searches []SavedSearch `json:"searches,omitempty"`
+	// NowPlaying polls the song titles shown under the favorites.
+	NowPlaying NowPlaying `json:"nowPlaying"`
+
 	historyMtx     sync.Mutex          `json:"-"`
 	History        []HistoryEntry      `json:"history,omitempty"`
 	HistorySaveMax *int                `json:"historySaveMax,omitempty"`
 	HistoryChan    chan []HistoryEntry `json:"-"`
 
+	votesMtx sync.Mutex `json:"-"`
+	// votes are the last vote times by station uuid, saved in their own file
+	votes map[string]time.Time
+
+	positionsMtx sync.Mutex `json:"-"`
+	// positions are the episode resume positions in seconds by episode ID,
+	// saved in their own file
+	positions map[string]int64
+
 	AutoplayFavorite string `json:"autoplayFavorite"`
 
 	favTmpl *template.Template
@@ -106,6 +159,20 @@ type Favorites struct {
 
 type InternalPlayer struct {
 	BufferSeconds int `json:"bufferSeconds"`
+	// TimeshiftMinutes enables the disk-backed timeshift buffer when > 0,
+	// replacing the in-memory buffer.
+	TimeshiftMinutes int `json:"timeshiftMinutes,omitempty"`
+	// PrebufferSeconds of compressed data are read before playback starts
+	// and after each network underrun.
+	PrebufferSeconds *int    `json:"prebufferSeconds,omitempty"`
+	DeadAir          DeadAir `json:"deadAir"`
+}
+
+func (p InternalPlayer) GetPrebufferSeconds() int {
+	if p.PrebufferSeconds == nil {
+		return DefInternalPrebufferSeconds
+	}
+	return max(0, *p.PrebufferSeconds)
 }
 
 type PlayerType uint8
@@ -205,6 +272,16 @@ func (v *Value) DeleteFavorite(s model.Station) bool {
 	return l2 != l1
 }
 
+// ReplaceFavorite replaces the favorite with the given uuid by s, keeping its position.
+func (v *Value) ReplaceFavorite(uuid string, s model.Station) bool {
+	idx := slices.IndexFunc(v.Favorites.list, func(el model.Station) bool { return el.Stationuuid == uuid })
+	if idx < 0 {
+		return false
+	}
+	v.Favorites.list[idx] = s
+	return true
+}
+
 func (v *Value) InsertFavorite(s model.Station, idx int) bool {
 	if slices.ContainsFunc(v.Favorites.list, func(el model.Station) bool {
 		return el.Stationuuid == s.Stationuuid
@@ -280,6 +357,18 @@ func Load(version string) (cfg *Value, err error) {
 		cfg.History = cfg.History[len(cfg.History)-*cfg.HistorySaveMax:]
 	}
 
+	// votes
+	err = cfg.loadVotes(filepath.Join(cfgDirPath, votesFilename))
+	if err != nil {
+		return
+	}
+
+	// podcasts
+	err = cfg.loadPositions(filepath.Join(cfgDirPath, positionsFilename))
+	if err != nil {
+		return
+	}
+
 	// favorites
 	cfg.Favorites.list, err = parsePlsFile(filepath.Join(cfgDirPath, favoritesFilename))
 	if err != nil {
@@ -310,6 +399,7 @@ func Load(version string) (cfg *Value, err error) {
 		cfg.MpdPort = DefMpdPort
 	}
 
+	err = cfg.validateProxy()
 	return
 }
 
@@ -372,7 +462,15 @@ func (v *Value) Save() error {
 		return err
 	}
 
-	return v.saveHistoryFile(cfgDirPath, entries)
+	if err := v.saveHistoryFile(cfgDirPath, entries); err != nil {
+		return err
+	}
+
+	if err := v.saveVotesFile(cfgDirPath); err != nil {
+		return err
+	}
+
+	return v.savePositionsFile(cfgDirPath)
 }
 
 func (v *Value) saveFavorites(filename string) (err error) {
@@ -469,3 +567,7 @@ func getOrCreateConfigDir() (string, error) {
 func Debug() bool {
 	return *debug
 }
+
+func Offline() bool {
+	return *offline
+}
@@ -0,0 +1,53 @@
+package config
+
+type DeadAirAction uint8
+
+const (
+	DeadAirWarn DeadAirAction = iota
+	DeadAirReconnect
+	DeadAirSkip
+	DeadAirIgnore
+)
+
+var DeadAirActions = [4]DeadAirAction{DeadAirWarn, DeadAirReconnect, DeadAirSkip, DeadAirIgnore}
+
+var deadAirActionNames = map[DeadAirAction]string{
+	DeadAirWarn:      "Warn",
+	DeadAirReconnect: "Reconnect",
+	DeadAirSkip:      "Skip favorite",
+	DeadAirIgnore:    "Ignore",
+}
+
+func (a DeadAirAction) String() string {
+	return deadAirActionNames[a]
+}
+
+// DeadAir configures the internal player's detection of sustained silence
+// and decoder stalls. Zero values fall back to the defaults.
+type DeadAir struct {
+	Action             DeadAirAction `json:"action"`
+	SilenceSeconds     int           `json:"silenceSeconds,omitempty"`
+	SilenceThresholdDb float64       `json:"silenceThresholdDb,omitempty"`
+	StallSeconds       int           `json:"stallSeconds,omitempty"`
+}
+
+func (d DeadAir) GetSilenceSeconds() int {
+	if d.SilenceSeconds > 0 {
+		return d.SilenceSeconds
+	}
+	return DefDeadAirSilenceSeconds
+}
+
+func (d DeadAir) GetSilenceThresholdDb() float64 {
+	if d.SilenceThresholdDb < 0 {
+		return d.SilenceThresholdDb
+	}
+	return DefDeadAirSilenceThresholdDb
+}
+
+func (d DeadAir) GetStallSeconds() int {
+	if d.StallSeconds > 0 {
+		return d.StallSeconds
+	}
+	return DefDeadAirStallSeconds
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DirectoryOPML is the type of the hierarchical OPML directories, browsed one
+// menu at a time.
+const DirectoryOPML = "opml"
+
+// PodcastIDPrefix starts the IDs of the podcast episodes, it can't be used by
+// a directory.
+const PodcastIDPrefix = "podcast"
+
+var (
+	errDirectoryType   = errors.New("unknown directory type")
+	errDirectoryPrefix = errors.New("directory prefix must be lowercase letters, digits or dashes")
+	errReservedPrefix  = errors.New("reserved directory prefix")
+	errDirectorySource = errors.New("directory needs either a path or a URL")
+)
+
+var directoryPrefixRe = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// Directory is a station directory browsed alongside radio-browser.
+type Directory struct {
+	// Prefix starts the IDs of the directory stations, e.g. "company" for "company:42".
+	Prefix string `json:"prefix"`
+	Name   string `json:"name,omitempty"`
+	// Path is a local directory of M3U and PLS playlists.
+	Path string `json:"path,omitempty"`
+	// URL serves a JSON list of stations or an OPML directory.
+	URL string `json:"url,omitempty"`
+	// Type is empty for the station lists, or DirectoryOPML for a
+	// hierarchical OPML directory, whose root document is at Path or URL.
+	Type string `json:"type,omitempty"`
+}
+
+func (d Directory) GetName() string {
+	if strings.TrimSpace(d.Name) != "" {
+		return d.Name
+	}
+	return d.Prefix
+}
+
+func (d Directory) Validate() error {
+	if !directoryPrefixRe.MatchString(d.Prefix) {
+		return fmt.Errorf("%w: %q", errDirectoryPrefix, d.Prefix)
+	}
+	if d.Prefix == PodcastIDPrefix {
+		return fmt.Errorf("%w: %q", errReservedPrefix, d.Prefix)
+	}
+	if d.Type != "" && d.Type != DirectoryOPML {
+		return fmt.Errorf("%w: %q", errDirectoryType, d.Type)
+	}
+	if (strings.TrimSpace(d.Path) == "") == (strings.TrimSpace(d.URL) == "") {
+		return fmt.Errorf("%w: %q", errDirectorySource, d.Prefix)
+	}
+	return nil
+}
+
+// GetDirectories returns the configured directories, which must have unique prefixes.
+func (v *Value) GetDirectories() ([]Directory, error) {
+	seen := make(map[string]bool, len(v.Directories))
+	for _, d := range v.Directories {
+		if err := d.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[d.Prefix] {
+			return nil, fmt.Errorf("duplicate directory prefix %q", d.Prefix)
+		}
+		seen[d.Prefix] = true
+	}
+	return v.Directories, nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestValue_GetDirectories(t *testing.T) {
+	tests := []struct {
+		dirs    []Directory
+		wantErr bool
+	}{
+		{dirs: []Directory{{Prefix: "office", Path: "/srv/radio"}, {Prefix: "company-2", URL: "http://radio.local/list.json"}}},
+		{dirs: []Directory{{Prefix: "Office", Path: "/srv/radio"}}, wantErr: true},
+		{dirs: []Directory{{Prefix: "office:1", Path: "/srv/radio"}}, wantErr: true},
+		{dirs: []Directory{{Prefix: "office"}}, wantErr: true},
+		{dirs: []Directory{{Prefix: "office", Path: "/srv/radio", URL: "http://radio.local"}}, wantErr: true},
+		{dirs: []Directory{{Prefix: "office", Path: "/a"}, {Prefix: "office", Path: "/b"}}, wantErr: true},
+		{dirs: []Directory{{Prefix: "tune", URL: "http://opml.local/root.opml", Type: DirectoryOPML}}},
+		{dirs: []Directory{{Prefix: "tune", URL: "http://opml.local/root.opml", Type: "xml"}}, wantErr: true},
+		{dirs: []Directory{{Prefix: "podcast", URL: "http://radio.local/list.json"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		v := &Value{Directories: tt.dirs}
+		if _, err := v.GetDirectories(); (err != nil) != tt.wantErr {
+			t.Errorf("GetDirectories(%+v) error = %v, wantErr %v", tt.dirs, err, tt.wantErr)
+		}
+	}
+	if name := (Directory{Prefix: "office"}).GetName(); name != "office" {
+		t.Errorf("default name = %q", name)
+	}
+}
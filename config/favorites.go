@@ -30,6 +30,8 @@ const (
 	prefixVotes           = "sr_votes"
 	prefixCodec           = "sr_codec"
 	prefixLastcheckoktime = "sr_lastcheckoktime"
+	prefixLastcheckok     = "sr_lastcheckok"
+	prefixLastchecktime   = "sr_lastchecktime"
 	prefixClickcount      = "sr_clickcount"
 	prefixClicktrend      = "sr_clicktrend"
 	prefixGeolat          = "sr_geo_lat"
@@ -136,6 +138,19 @@ func parsePlsFile(filename string) ([]model.Station, error) {
 			if v := getStringValue(l); v != "" {
 				elem.Lastcheckoktime = v
 			}
+		case strings.HasPrefix(ll, prefixLastcheckok):
+			if v := getStringValue(l); v != "" {
+				nr, err := strconv.Atoi(v)
+				if err != nil {
+					slog.Error(fmt.Sprintf("invalid lastcheckok value: %v", v))
+					continue
+				}
+				elem.Lastcheckok = int64(nr)
+			}
+		case strings.HasPrefix(ll, prefixLastchecktime):
+			if v := getStringValue(l); v != "" {
+				elem.Lastchecktime = v
+			}
 		case strings.HasPrefix(ll, prefixClickcount):
 			if v := getStringValue(l); v != "" {
 				nr, err := strconv.Atoi(v)
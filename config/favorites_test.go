@@ -40,7 +40,9 @@ func Test_parsePlsFile(t *testing.T) {
 			Country:         "Romania",
 			Votes:           111112,
 			Codec:           "MP3",
-			Lastcheckoktime: "",
+			Lastcheckok:     1,
+			Lastcheckoktime: "2024-05-01 10:00:00",
+			Lastchecktime:   "2024-05-02 10:00:00",
 			Clickcount:      222,
 			Clicktrend:      11,
 			GeoLat:          "44.4",
@@ -0,0 +1,40 @@
+package config
+
+import "strings"
+
+// VariantPreference picks the variant of a station group played by default.
+type VariantPreference uint8
+
+const (
+	VariantHighestBitrate VariantPreference = iota
+	VariantCodec
+	VariantHTTPS
+)
+
+var VariantPreferences = [3]VariantPreference{VariantHighestBitrate, VariantCodec, VariantHTTPS}
+
+var variantPreferenceNames = map[VariantPreference]string{
+	VariantHighestBitrate: "Highest bitrate",
+	VariantCodec:          "Preferred codec",
+	VariantHTTPS:          "HTTPS",
+}
+
+func (p VariantPreference) String() string {
+	return variantPreferenceNames[p]
+}
+
+// Grouping collapses the variants of the same station in the browse results:
+// the same station name and homepage with other bitrates, codecs or mirrors.
+type Grouping struct {
+	Enabled bool              `json:"enabled"`
+	Prefer  VariantPreference `json:"prefer"`
+	// Codec is the preferred codec, for VariantCodec
+	Codec string `json:"codec,omitempty"`
+}
+
+func (g Grouping) GetCodec() string {
+	if c := strings.TrimSpace(g.Codec); c != "" {
+		return c
+	}
+	return DefGroupingCodec
+}
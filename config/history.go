@@ -11,6 +11,8 @@ const (
 	recentlyPlayed     = 2 * time.Minute
 	tsFormat           = "15:04 02.01.2006"
 	histTitleSeparator = "|"
+	// maxListenGap bounds the listening time counted for a history entry.
+	maxListenGap = 30 * time.Minute
 )
 
 type HistoryEntry struct {
@@ -112,3 +114,20 @@ func (v *Value) equalEntries(a, b HistoryEntry) bool {
 	x := a.Uuid == b.Uuid && a.Song == b.Song
 	return x
 }
+
+// ListeningTime estimates the time spent on each station from the history,
+// counting the time until the next entry, up to maxListenGap per entry.
+func (v *Value) ListeningTime(now time.Time) map[string]time.Duration {
+	v.historyMtx.Lock()
+	defer v.historyMtx.Unlock()
+
+	res := make(map[string]time.Duration)
+	for i, e := range v.History {
+		next := now
+		if i+1 < len(v.History) {
+			next = v.History[i+1].Timestamp
+		}
+		res[e.Uuid] += min(max(next.Sub(e.Timestamp), 0), maxListenGap)
+	}
+	return res
+}
@@ -162,3 +162,28 @@ func TestValue_AddHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestValue_ListeningTime(t *testing.T) {
+	now := time.Now()
+	v := &Value{History: []HistoryEntry{
+		{Uuid: "1", Timestamp: now.Add(-3 * time.Hour)},
+		{Uuid: "2", Timestamp: now.Add(-50 * time.Minute)},
+		{Uuid: "1", Timestamp: now.Add(-40 * time.Minute)},
+		{Uuid: "1", Timestamp: now.Add(-35 * time.Minute)},
+		{Uuid: "3", Timestamp: now.Add(-5 * time.Minute)},
+	}}
+	got := v.ListeningTime(now)
+	want := map[string]time.Duration{
+		"1": maxListenGap + 5*time.Minute + maxListenGap,
+		"2": 10 * time.Minute,
+		"3": 5 * time.Minute,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListeningTime = %v", got)
+	}
+	for uuid, d := range want {
+		if got[uuid] != d {
+			t.Errorf("ListeningTime[%s] = %v, want %v", uuid, got[uuid], d)
+		}
+	}
+}
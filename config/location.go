@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dancnb/sonicradio/geo"
+)
+
+var ErrNoLocation = errors.New("no location set, add one in the settings tab")
+
+// Location is the position used for the nearby stations, given either as
+// lat/long or as a city of the embedded gazetteer.
+type Location struct {
+	Lat  *float64 `json:"lat,omitempty"`
+	Long *float64 `json:"long,omitempty"`
+	City string   `json:"city,omitempty"`
+	// RadiusKm bounds the distance of the nearby stations.
+	RadiusKm *int `json:"radiusKm,omitempty"`
+}
+
+// Point returns the configured coordinates, which take precedence over the city.
+func (l Location) Point() (geo.Point, error) {
+	if l.Lat != nil && l.Long != nil {
+		p := geo.Point{Lat: *l.Lat, Long: *l.Long}
+		return p, p.Validate()
+	}
+	if strings.TrimSpace(l.City) != "" {
+		return geo.ParseLocation(l.City)
+	}
+	return geo.Point{}, ErrNoLocation
+}
+
+// String returns the location as entered in the settings tab.
+func (l Location) String() string {
+	if l.Lat != nil && l.Long != nil {
+		return geo.Point{Lat: *l.Lat, Long: *l.Long}.String()
+	}
+	return l.City
+}
+
+// SetFromString sets the location from a city name or a "lat,long" pair, an
+// empty value clears it.
+func (l *Location) SetFromString(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		l.Lat, l.Long, l.City = nil, nil, ""
+		return nil
+	}
+	if c, ok := geo.LookupCity(s); ok {
+		l.Lat, l.Long, l.City = nil, nil, c.String()
+		return nil
+	}
+	p, err := geo.ParsePoint(s)
+	if err != nil {
+		return err
+	}
+	l.Lat, l.Long, l.City = &p.Lat, &p.Long, ""
+	return nil
+}
+
+func (l Location) GetRadiusKm() int {
+	if l.RadiusKm == nil || *l.RadiusKm <= 0 {
+		return DefNearbyRadiusKm
+	}
+	return *l.RadiusKm
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLocation(t *testing.T) {
+	var l Location
+	if _, err := l.Point(); !errors.Is(err, ErrNoLocation) {
+		t.Errorf("empty location error = %v", err)
+	}
+
+	if err := l.SetFromString("vienna"); err != nil {
+		t.Fatal(err)
+	}
+	if l.City != "Vienna, AT" || l.Lat != nil {
+		t.Errorf("city location = %+v", l)
+	}
+	if p, err := l.Point(); err != nil || p.Lat != 48.2082 {
+		t.Errorf("city point = %v, %v", p, err)
+	}
+
+	if err := l.SetFromString("45.5, -73.6"); err != nil {
+		t.Fatal(err)
+	}
+	if l.City != "" || l.String() != "45.5,-73.6" {
+		t.Errorf("lat/long location = %+v", l)
+	}
+
+	if err := l.SetFromString("Atlantis"); err == nil {
+		t.Error("expected an error for an unknown city")
+	}
+	if l.String() != "45.5,-73.6" {
+		t.Errorf("location changed by an invalid value: %v", l)
+	}
+
+	if l.GetRadiusKm() != DefNearbyRadiusKm {
+		t.Errorf("radius = %d", l.GetRadiusKm())
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// NowPlaying configures the background polling of the current song titles
+// of the favorite stations.
+type NowPlaying struct {
+	Enabled bool `json:"enabled"`
+	// IntervalSeconds between the polls of the same station, zero for the
+	// default
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+}
+
+func (n NowPlaying) GetInterval() time.Duration {
+	if n.IntervalSeconds > 0 {
+		return time.Duration(n.IntervalSeconds) * time.Second
+	}
+	return DefNowPlayingIntervalSeconds * time.Second
+}
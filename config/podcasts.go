@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	errPodcastURL        = errors.New("podcast feed must be an http(s) URL")
+	errPodcastSubscribed = errors.New("already subscribed to this podcast")
+)
+
+// AddPodcast subscribes to the podcast feed.
+func (v *Value) AddPodcast(feedURL string) error {
+	feedURL = strings.TrimSpace(feedURL)
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", errPodcastURL, feedURL)
+	}
+	if slices.Contains(v.Podcasts, feedURL) {
+		return errPodcastSubscribed
+	}
+	v.Podcasts = append(v.Podcasts, feedURL)
+	return nil
+}
+
+// RemovePodcast unsubscribes from the podcast feed.
+func (v *Value) RemovePodcast(feedURL string) {
+	v.Podcasts = slices.DeleteFunc(v.Podcasts, func(s string) bool { return s == feedURL })
+}
+
+// EpisodePosition returns the playback position an episode can be resumed from.
+func (v *Value) EpisodePosition(id string) (int64, bool) {
+	v.positionsMtx.Lock()
+	defer v.positionsMtx.Unlock()
+	sec, ok := v.positions[id]
+	return sec, ok
+}
+
+// SetEpisodePosition remembers the playback position of an episode; a zero
+// position forgets it.
+func (v *Value) SetEpisodePosition(id string, sec int64) {
+	v.positionsMtx.Lock()
+	defer v.positionsMtx.Unlock()
+	if sec <= 0 {
+		delete(v.positions, id)
+		return
+	}
+	if v.positions == nil {
+		v.positions = make(map[string]int64)
+	}
+	v.positions[id] = sec
+}
+
+func (v *Value) loadPositions(positionsFilePath string) error {
+	b, err := os.ReadFile(positionsFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var positions map[string]int64
+	if err := json.Unmarshal(b, &positions); err != nil {
+		return err
+	}
+	v.positionsMtx.Lock()
+	defer v.positionsMtx.Unlock()
+	v.positions = positions
+	return nil
+}
+
+func (v *Value) savePositionsFile(cfgDirPath string) (err error) {
+	v.positionsMtx.Lock()
+	defer v.positionsMtx.Unlock()
+	if v.positions == nil {
+		return nil
+	}
+
+	positionsFile, err := os.Create(filepath.Join(cfgDirPath, positionsFilename))
+	if err != nil {
+		return
+	}
+	defer func() {
+		closeErr := positionsFile.Close()
+		if closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	enc := json.NewEncoder(positionsFile)
+	enc.SetIndent("  ", "  ")
+	err = enc.Encode(v.positions)
+
+	return
+}
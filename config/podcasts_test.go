@@ -0,0 +1,47 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValue_podcasts(t *testing.T) {
+	v := &Value{}
+	for _, url := range []string{"", "feed.xml", "ftp://example.com/feed.xml"} {
+		if err := v.AddPodcast(url); err == nil {
+			t.Errorf("AddPodcast(%q) error = nil", url)
+		}
+	}
+	if err := v.AddPodcast(" https://example.com/feed.xml "); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.AddPodcast("https://example.com/feed.xml"); err == nil {
+		t.Error("duplicate subscription error = nil")
+	}
+	v.RemovePodcast("https://example.com/feed.xml")
+	if len(v.Podcasts) != 0 {
+		t.Errorf("podcasts after remove = %v", v.Podcasts)
+	}
+
+	v.SetEpisodePosition("a", 90)
+	v.SetEpisodePosition("b", 30)
+	v.SetEpisodePosition("b", 0)
+	if _, ok := v.EpisodePosition("b"); ok {
+		t.Error("cleared position still set")
+	}
+
+	dir := t.TempDir()
+	if err := v.savePositionsFile(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Value{}
+	if err := loaded.loadPositions(filepath.Join(dir, positionsFilename)); err != nil {
+		t.Fatal(err)
+	}
+	if sec, ok := loaded.EpisodePosition("a"); !ok || sec != 90 {
+		t.Errorf("loaded position = %v, %v", sec, ok)
+	}
+	if err := loaded.loadPositions(filepath.Join(t.TempDir(), positionsFilename)); err != nil {
+		t.Errorf("missing positions file error = %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+var errProxyScheme = fmt.Errorf("proxy scheme must be one of: %s", strings.Join(proxySchemes, ", "))
+
+// ErrInvalidProxy is returned by Load when the configured proxy can't be
+// parsed.
+var ErrInvalidProxy = errors.New("invalid proxy")
+
+var (
+	allProxyEnvVars   = []string{"ALL_PROXY", "all_proxy"}
+	httpsProxyEnvVars = []string{"HTTPS_PROXY", "https_proxy"}
+	httpProxyEnvVars  = []string{"HTTP_PROXY", "http_proxy"}
+	noProxyEnvVars    = []string{"NO_PROXY", "no_proxy"}
+)
+
+// ParseProxy parses an HTTP(S) or SOCKS5 proxy URL, defaulting to http if no scheme is given.
+func ParseProxy(s string) (*url.URL, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty proxy URL")
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !slices.Contains(proxySchemes, scheme) {
+		return nil, errProxyScheme
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing proxy host in %q", s)
+	}
+	u.Scheme = scheme
+	return u, nil
+}
+
+// validateProxy checks the configured proxy once, at load.
+func (v *Value) validateProxy() error {
+	if v.Proxy == "" {
+		return nil
+	}
+	if _, err := ParseProxy(v.Proxy); err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidProxy, v.Proxy, err)
+	}
+	return nil
+}
+
+// ProxyFor returns the proxy of a connection to target, nil for a direct
+// connection. It is the single place deciding the proxy of both the HTTP
+// clients and the external players:
+//
+//   - the configured proxy is used for all connections,
+//   - otherwise no proxy is used for localhost and the hosts in NO_PROXY,
+//   - otherwise HTTPS_PROXY or HTTP_PROXY is used, by the target scheme,
+//     with ALL_PROXY as fallback.
+func (v *Value) ProxyFor(target *url.URL) (*url.URL, error) {
+	if v.Proxy != "" {
+		return ParseProxy(v.Proxy)
+	}
+	if target == nil || noProxy(target) {
+		return nil, nil
+	}
+	envVars := httpProxyEnvVars
+	if strings.EqualFold(target.Scheme, "https") {
+		envVars = httpsProxyEnvVars
+	}
+	for _, vars := range [][]string{envVars, allProxyEnvVars} {
+		if val := lookupEnv(vars); val != "" {
+			return ParseProxy(val)
+		}
+	}
+	return nil, nil
+}
+
+// ProxyFunc returns the proxy selection function for an http.Transport.
+func (v *Value) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		return v.ProxyFor(req.URL)
+	}
+}
+
+func lookupEnv(names []string) string {
+	for _, name := range names {
+		if val, ok := os.LookupEnv(name); ok && strings.TrimSpace(val) != "" {
+			return strings.TrimSpace(val)
+		}
+	}
+	return ""
+}
+
+// noProxy reports whether target is connected to directly: localhost, or a
+// host matching a NO_PROXY entry, either "*", an IP, a CIDR range, or a
+// domain name that also matches its subdomains, with an optional port.
+func noProxy(target *url.URL) bool {
+	host := strings.ToLower(target.Hostname())
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+	port := target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[strings.ToLower(target.Scheme)]
+	}
+
+	for _, entry := range strings.Split(lookupEnv(noProxyEnvVars), ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHTTPClient returns a client using the proxy settings. It has no timeout,
+// so requests must be bound by their context.
+func (v *Value) NewHTTPClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = v.ProxyFunc()
+	return &http.Client{Transport: t}
+}
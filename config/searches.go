@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+var errSearchName = errors.New("saved search name is empty")
+
+// SavedSearch is a named search, re-run from the search form or from its own
+// tab when pinned.
+type SavedSearch struct {
+	Name string `json:"name"`
+	// Params are the encoded browser.SearchParams.
+	Params json.RawMessage `json:"params"`
+	Pinned bool            `json:"pinned,omitempty"`
+}
+
+// AddSearchHistory moves the search params to the front of the search
+// history, keeping the last DefSearchHistoryMax searches.
+func (v *Value) AddSearchHistory(params json.RawMessage) {
+	v.SearchHistory = slices.DeleteFunc(v.SearchHistory, func(p json.RawMessage) bool {
+		return bytes.Equal(p, params)
+	})
+	v.SearchHistory = slices.Insert(v.SearchHistory, 0, params)
+	if len(v.SearchHistory) > DefSearchHistoryMax {
+		v.SearchHistory = v.SearchHistory[:DefSearchHistoryMax]
+	}
+}
+
+// SaveSearch saves the search params by name, replacing the params of a
+// saved search with the same name.
+func (v *Value) SaveSearch(name string, params json.RawMessage) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errSearchName
+	}
+	if s := v.SavedSearch(name); s != nil {
+		s.Params = params
+		return nil
+	}
+	v.Searches = append(v.Searches, SavedSearch{Name: name, Params: params})
+	return nil
+}
+
+// SavedSearch returns the saved search with this name, nil if not found.
+func (v *Value) SavedSearch(name string) *SavedSearch {
+	i := slices.IndexFunc(v.Searches, func(s SavedSearch) bool { return s.Name == name })
+	if i < 0 {
+		return nil
+	}
+	return &v.Searches[i]
+}
+
+func (v *Value) RemoveSearch(name string) {
+	v.Searches = slices.DeleteFunc(v.Searches, func(s SavedSearch) bool { return s.Name == name })
+}
+
+// PinSearch shows or hides the saved search as its own tab.
+func (v *Value) PinSearch(name string, pinned bool) {
+	if s := v.SavedSearch(name); s != nil {
+		s.Pinned = pinned
+	}
+}
+
+// PinnedSearches returns the saved searches shown as tabs.
+func (v *Value) PinnedSearches() []SavedSearch {
+	var res []SavedSearch
+	for _, s := range v.Searches {
+		if s.Pinned {
+			res = append(res, s)
+		}
+	}
+	return res
+}
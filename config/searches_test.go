@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestValue_searchHistory(t *testing.T) {
+	v := &Value{}
+	for i := range DefSearchHistoryMax + 5 {
+		v.AddSearchHistory(json.RawMessage(fmt.Sprintf(`{"name":"%d"}`, i)))
+	}
+	v.AddSearchHistory(json.RawMessage(`{"name":"10"}`))
+	if len(v.SearchHistory) != DefSearchHistoryMax {
+		t.Fatalf("search history length = %d", len(v.SearchHistory))
+	}
+	if got := string(v.SearchHistory[0]); got != `{"name":"10"}` {
+		t.Errorf("newest search = %s", got)
+	}
+	if got := string(v.SearchHistory[1]); got != fmt.Sprintf(`{"name":"%d"}`, DefSearchHistoryMax+4) {
+		t.Errorf("second search = %s", got)
+	}
+}
+
+func TestValue_savedSearches(t *testing.T) {
+	v := &Value{}
+	if err := v.SaveSearch("  ", json.RawMessage(`{}`)); err == nil {
+		t.Error("empty name error = nil")
+	}
+	if err := v.SaveSearch(" jazz ", json.RawMessage(`{"name":"jazz"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SaveSearch("rock", json.RawMessage(`{"tagList":"rock"}`)); err != nil {
+		t.Fatal(err)
+	}
+	v.PinSearch("jazz", true)
+	if err := v.SaveSearch("jazz", json.RawMessage(`{"name":"jazz","reverse":true}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	pinned := v.PinnedSearches()
+	if len(v.Searches) != 2 || len(pinned) != 1 || pinned[0].Name != "jazz" {
+		t.Fatalf("searches = %+v, pinned = %+v", v.Searches, pinned)
+	}
+	if got := string(pinned[0].Params); got != `{"name":"jazz","reverse":true}` {
+		t.Errorf("replaced params = %s", got)
+	}
+
+	v.RemoveSearch("jazz")
+	if v.SavedSearch("jazz") != nil || len(v.PinnedSearches()) != 0 {
+		t.Errorf("searches after remove = %+v", v.Searches)
+	}
+}
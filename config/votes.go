@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// VoteCooldown is how long radio-browser rejects a new vote for the same station.
+const VoteCooldown = 10 * time.Minute
+
+// LastVote returns the time of the last vote for the station.
+func (v *Value) LastVote(uuid string) (time.Time, bool) {
+	v.votesMtx.Lock()
+	defer v.votesMtx.Unlock()
+	t, ok := v.votes[uuid]
+	return t, ok
+}
+
+// IsVoted reports whether the station was ever voted.
+func (v *Value) IsVoted(uuid string) bool {
+	_, ok := v.LastVote(uuid)
+	return ok
+}
+
+func (v *Value) AddVote(uuid string, t time.Time) {
+	v.votesMtx.Lock()
+	defer v.votesMtx.Unlock()
+	if v.votes == nil {
+		v.votes = make(map[string]time.Time)
+	}
+	v.votes[uuid] = t
+}
+
+// ReserveVote starts the cooldown of the station before its vote request is
+// sent, unless a cooldown is running. The returned function restores the
+// previous vote if the request fails.
+func (v *Value) ReserveVote(uuid string, now time.Time) (cancel func(), ok bool) {
+	v.votesMtx.Lock()
+	defer v.votesMtx.Unlock()
+	prev, voted := v.votes[uuid]
+	if voted && prev.Add(VoteCooldown).After(now) {
+		return nil, false
+	}
+	if v.votes == nil {
+		v.votes = make(map[string]time.Time)
+	}
+	v.votes[uuid] = now
+	return func() {
+		v.votesMtx.Lock()
+		defer v.votesMtx.Unlock()
+		if !v.votes[uuid].Equal(now) {
+			return
+		}
+		if voted {
+			v.votes[uuid] = prev
+		} else {
+			delete(v.votes, uuid)
+		}
+	}, true
+}
+
+// VoteCooldownLeft returns how long until the station can be voted again.
+func (v *Value) VoteCooldownLeft(uuid string, now time.Time) time.Duration {
+	t, ok := v.LastVote(uuid)
+	if !ok {
+		return 0
+	}
+	return max(t.Add(VoteCooldown).Sub(now), 0)
+}
+
+func (v *Value) loadVotes(votesFilePath string) error {
+	b, err := os.ReadFile(votesFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var votes map[string]time.Time
+	if err := json.Unmarshal(b, &votes); err != nil {
+		return err
+	}
+	v.votesMtx.Lock()
+	defer v.votesMtx.Unlock()
+	v.votes = votes
+	return nil
+}
+
+func (v *Value) saveVotesFile(cfgDirPath string) (err error) {
+	v.votesMtx.Lock()
+	defer v.votesMtx.Unlock()
+	if len(v.votes) == 0 {
+		return nil
+	}
+
+	votesFile, err := os.Create(filepath.Join(cfgDirPath, votesFilename))
+	if err != nil {
+		return
+	}
+	defer func() {
+		closeErr := votesFile.Close()
+		if closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	enc := json.NewEncoder(votesFile)
+	enc.SetIndent("  ", "  ")
+	err = enc.Encode(v.votes)
+
+	return
+}
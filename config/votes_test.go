@@ -0,0 +1,69 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestValue_votes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	v := &Value{}
+	if v.IsVoted("a") || v.VoteCooldownLeft("a", now) != 0 {
+		t.Error("station voted without votes")
+	}
+
+	v.AddVote("a", now.Add(-4*time.Minute))
+	if !v.IsVoted("a") {
+		t.Error("station not voted")
+	}
+	if left := v.VoteCooldownLeft("a", now); left != VoteCooldown-4*time.Minute {
+		t.Errorf("cooldown left = %v", left)
+	}
+	if left := v.VoteCooldownLeft("a", now.Add(VoteCooldown)); left != 0 {
+		t.Errorf("cooldown left after expiry = %v", left)
+	}
+
+	dir := t.TempDir()
+	if err := v.saveVotesFile(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Value{}
+	if err := loaded.loadVotes(filepath.Join(dir, votesFilename)); err != nil {
+		t.Fatal(err)
+	}
+	if vt, ok := loaded.LastVote("a"); !ok || !vt.Equal(now.Add(-4*time.Minute)) {
+		t.Errorf("loaded vote = %v, %v", vt, ok)
+	}
+	if err := loaded.loadVotes(filepath.Join(t.TempDir(), votesFilename)); err != nil {
+		t.Errorf("missing votes file error = %v", err)
+	}
+}
+
+func TestValue_ReserveVote(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	v := &Value{}
+
+	cancel, ok := v.ReserveVote("a", now)
+	if !ok {
+		t.Fatal("vote not reserved")
+	}
+	if _, ok := v.ReserveVote("a", now.Add(time.Second)); ok {
+		t.Error("second vote reserved during the cooldown")
+	}
+	cancel()
+	if v.IsVoted("a") {
+		t.Error("cancelled vote kept")
+	}
+
+	prev := now.Add(-2 * VoteCooldown)
+	v.AddVote("b", prev)
+	cancel, ok = v.ReserveVote("b", now)
+	if !ok {
+		t.Fatal("vote not reserved after the cooldown")
+	}
+	cancel()
+	if vt, _ := v.LastVote("b"); !vt.Equal(prev) {
+		t.Errorf("last vote after cancel = %v, want %v", vt, prev)
+	}
+}
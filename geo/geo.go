@@ -0,0 +1,128 @@
+// Package geo resolves user locations and computes distances between them.
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const earthRadiusKm = 6371.0
+
+var errUnknownLocation = errors.New("unknown location, use a city name or lat,long")
+
+// cities.csv is a small gazetteer of major cities: name,country code,lat,long.
+//
+//go:embed cities.csv
+var citiesCSV string
+
+type Point struct {
+	Lat  float64
+	Long float64
+}
+
+type City struct {
+	Name        string
+	CountryCode string
+	Point
+}
+
+// String returns the city as listed in the suggestions, e.g. "Berlin, DE".
+func (c City) String() string {
+	return c.Name + ", " + c.CountryCode
+}
+
+var cities = sync.OnceValue(func() []City {
+	records, err := csv.NewReader(strings.NewReader(citiesCSV)).ReadAll()
+	if err != nil {
+		slog.Error("geo: invalid gazetteer", "error", err)
+		return nil
+	}
+	res := make([]City, 0, len(records))
+	for _, r := range records {
+		lat, err1 := strconv.ParseFloat(r[2], 64)
+		long, err2 := strconv.ParseFloat(r[3], 64)
+		if err1 != nil || err2 != nil {
+			slog.Error("geo: invalid gazetteer entry", "entry", r)
+			continue
+		}
+		res = append(res, City{Name: r[0], CountryCode: r[1], Point: Point{Lat: lat, Long: long}})
+	}
+	return res
+})
+
+// Cities returns the cities of the embedded gazetteer.
+func Cities() []City {
+	return cities()
+}
+
+// LookupCity finds a city of the gazetteer by its name, optionally followed by
+// the country code, e.g. "berlin" or "Berlin, DE".
+func LookupCity(name string) (City, bool) {
+	name = strings.TrimSpace(name)
+	for _, c := range cities() {
+		if strings.EqualFold(name, c.Name) || strings.EqualFold(name, c.String()) {
+			return c, true
+		}
+	}
+	return City{}, false
+}
+
+// ParsePoint parses a "lat,long" pair in decimal degrees.
+func ParsePoint(s string) (Point, error) {
+	lat, long, ok := strings.Cut(s, ",")
+	if !ok {
+		return Point{}, errUnknownLocation
+	}
+	var p Point
+	var err error
+	if p.Lat, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil {
+		return Point{}, fmt.Errorf("invalid latitude: %w", err)
+	}
+	if p.Long, err = strconv.ParseFloat(strings.TrimSpace(long), 64); err != nil {
+		return Point{}, fmt.Errorf("invalid longitude: %w", err)
+	}
+	return p, p.Validate()
+}
+
+// ParseLocation resolves a "lat,long" pair or a gazetteer city name.
+func ParseLocation(s string) (Point, error) {
+	if c, ok := LookupCity(s); ok {
+		return c.Point, nil
+	}
+	return ParsePoint(s)
+}
+
+func (p Point) Validate() error {
+	if p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude out of range: %v", p.Lat)
+	}
+	if p.Long < -180 || p.Long > 180 {
+		return fmt.Errorf("longitude out of range: %v", p.Long)
+	}
+	return nil
+}
+
+// String formats the point as a "lat,long" pair.
+func (p Point) String() string {
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(p.Long, 'f', -1, 64)
+}
+
+// DistanceKm returns the great-circle distance between two points.
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLong := radians(b.Long - a.Long)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
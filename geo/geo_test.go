@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Point
+		wantErr bool
+	}{
+		{in: "berlin", want: Point{52.52, 13.405}},
+		{in: "Berlin, DE", want: Point{52.52, 13.405}},
+		{in: " 44.43, 26.1 ", want: Point{44.43, 26.1}},
+		{in: "-33.9,151.2", want: Point{-33.9, 151.2}},
+		{in: "Atlantis", wantErr: true},
+		{in: "91,0", wantErr: true},
+		{in: "0,181", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLocation(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLocation(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLocation(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	berlin, _ := LookupCity("Berlin")
+	paris, _ := LookupCity("Paris")
+	if d := DistanceKm(berlin.Point, paris.Point); math.Abs(d-878) > 5 {
+		t.Errorf("Berlin-Paris = %.0f km, want about 878", d)
+	}
+	if d := DistanceKm(berlin.Point, berlin.Point); d != 0 {
+		t.Errorf("same point distance = %v", d)
+	}
+}
+
+func TestCities(t *testing.T) {
+	if len(Cities()) < 50 {
+		t.Fatalf("%d cities in gazetteer", len(Cities()))
+	}
+	for _, c := range Cities() {
+		if err := c.Validate(); err != nil || len(c.CountryCode) != 2 {
+			t.Errorf("invalid city %v: %v", c, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -95,7 +96,11 @@ func run() {
 	defer cancel()
 
 	cfg, err := config.Load(version)
-	if err != nil {
+	if errors.Is(err, config.ErrInvalidProxy) {
+		slog.Error("load config", "error", err.Error())
+		fmt.Printf("config: %v\n", err)
+		return
+	} else if err != nil {
 		slog.Info("load config", "error", err.Error())
 	}
 	if cfg == nil {
@@ -106,13 +111,21 @@ func run() {
 
 	b, err := browser.NewAPI(ctx, cfg)
 	if err != nil {
-		panic(err)
+		slog.Error("browser api", "error", err.Error())
+		fmt.Printf("radio-browser API: %v\n", err)
+		return
+	}
+	dirs, err := browser.NewConfiguredDirectories(b, cfg)
+	if err != nil {
+		slog.Error("station directories", "error", err.Error())
+		fmt.Printf("station directories: %v\n", err)
+		return
 	}
 	p, err := player.NewPlayer(ctx, cfg)
 	if err != nil {
 		panic(err)
 	}
-	m := ui.NewModel(ctx, cfg, b, p)
+	m := ui.NewModel(ctx, cfg, b, dirs, dirs.Providers(), p)
 	defer func() {
 		m.Quit()
 	}()
@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 type Country struct {
 	Name         string `json:"name"`
 	ISO3166_1    string `json:"iso_3166_1"`
@@ -14,8 +16,65 @@ type Language struct {
 
 type StationTag struct {
 	Name         string `json:"name"`
-	Stationcount string `json:"stationcount"`
+	Stationcount int    `json:"stationcount"`
+}
+
+type State struct {
+	Name         string `json:"name"`
+	Country      string `json:"country"`
+	Stationcount int    `json:"stationcount"`
+}
+
+type Codec struct {
+	Name         string `json:"name"`
+	Stationcount int    `json:"stationcount"`
 }
+
+// StationCheck is the result of a stream check done by a radio-browser server.
+type StationCheck struct {
+	Checkuuid      string `json:"checkuuid"`
+	Stationuuid    string `json:"stationuuid"`
+	Source         string `json:"source"` // the check server
+	Codec          string `json:"codec"`
+	Bitrate        int64  `json:"bitrate"`
+	HLS            int64  `json:"hls"`
+	Ok             int64  `json:"ok"`
+	Timestamp      string `json:"timestamp_iso8601"`
+	ServerSoftware string `json:"server_software"`
+	TimingMs       int64  `json:"timing_ms"`
+	SSLError       int64  `json:"ssl_error"`
+
+	Steps []StationCheckStep `json:"-"`
+}
+
+// FailureReason returns the first error of the check steps.
+func (c StationCheck) FailureReason() string {
+	for _, s := range c.Steps {
+		if e := strings.TrimSpace(s.Error); e != "" {
+			return e
+		}
+	}
+	return ""
+}
+
+// StationCheckStep is a URL followed by a stream check, e.g. a playlist or a redirect.
+type StationCheckStep struct {
+	Stepuuid       string `json:"stepuuid"`
+	ParentStepuuid string `json:"parent_stepuuid"`
+	Checkuuid      string `json:"checkuuid"`
+	Stationuuid    string `json:"stationuuid"`
+	URL            string `json:"url"`
+	URLType        string `json:"urltype"`
+	Error          string `json:"error"`
+	Created        string `json:"creation_iso8601"`
+}
+
+type AddStationResponse struct {
+	Ok      bool   `json:"ok"`
+	Message string `json:"message"`
+	UUID    string `json:"uuid"`
+}
+
 type ClickCounterResponse struct {
 	Ok          string `json:"ok"`
 	Message     string `json:"message"`
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -28,13 +29,50 @@ type Station struct {
 	Votes           int64       `json:"votes"`    // Number of votes for this station. This number is by server and only ever increases. It will never be reset to 0.
 	Codec           string      `json:"codec"`
 	Bitrate         int64       `json:"bitrate"`
+	Lastcheckok     int64       `json:"lastcheckok"` // 1 if the last stream check succeeded
+	Lastchecktime   string      `json:"lastchecktime"`
 	Lastcheckoktime string      `json:"lastcheckoktime"`
+	SSLError        int64       `json:"ssl_error"`
 	Clickcount      int64       `json:"clickcount"`
 	Clicktrend      int64       `json:"clicktrend"`
 	GeoLat          interface{} `json:"geo_lat"`
 	GeoLong         interface{} `json:"geo_long"`
 
 	IsCustom bool `json:"-"`
+	// DistanceKm from the user location, set for the nearby stations only.
+	DistanceKm *float64 `json:"-"`
+	// Variants of the station in grouped results, sorted by bitrate; set on
+	// the station played by default for its group only.
+	Variants []Station `json:"-"`
+	// VariantOf is the group station whose variants are expanded.
+	VariantOf string `json:"-"`
+	// NowPlaying is the song title polled in the background, for the
+	// favorites only.
+	NowPlaying string `json:"-"`
+}
+
+// LastCheckFailed reports whether the last radio-browser stream check failed.
+// Stations that were never checked, like the custom ones, are not reported.
+func (s Station) LastCheckFailed() bool {
+	return strings.TrimSpace(s.Lastchecktime) != "" && s.Lastcheckok == 0
+}
+
+// GeoPoint returns the station coordinates, if known.
+func (s Station) GeoPoint() (lat, long float64, ok bool) {
+	lat, ok1 := geoCoord(s.GeoLat)
+	long, ok2 := geoCoord(s.GeoLong)
+	return lat, long, ok1 && ok2
+}
+
+func geoCoord(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
 }
 
 func (s Station) Title() string { return s.Name }
@@ -46,6 +84,9 @@ func (s Station) Description() string {
 	if strings.TrimSpace(s.Language) != "" {
 		desc += ", " + s.Language
 	}
+	if s.DistanceKm != nil {
+		desc = strings.TrimPrefix(fmt.Sprintf("%s, %.0f km", desc, *s.DistanceKm), ", ")
+	}
 	if strings.TrimSpace(desc) == "" {
 		desc = "-"
 	}
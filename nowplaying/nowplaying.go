@@ -0,0 +1,249 @@
+// Package nowplaying polls the current song titles of stations in the
+// background, connecting briefly to each stream in turn.
+package nowplaying
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+const (
+	// tickInterval is how often the due stations are looked up.
+	tickInterval = time.Second
+	// fetchTimeout bounds a single stream connection.
+	fetchTimeout = 15 * time.Second
+	// maxBackoff is the longest delay between the polls of a failing station.
+	maxBackoff = 30 * time.Minute
+)
+
+// FetchFunc returns the current song title of the stream at url.
+type FetchFunc func(ctx context.Context, url string) (string, error)
+
+// Update is a changed title; an empty title means it is no longer known.
+type Update struct {
+	StationID string
+	Title     string
+}
+
+// Poller polls the station titles on a staggered schedule, with a limit of
+// concurrent connections and a backoff for the failing stations.
+type Poller struct {
+	fetch       FetchFunc
+	interval    time.Duration
+	concurrency int
+	tick        time.Duration
+	updates     chan Update
+
+	mu       sync.Mutex
+	enabled  bool
+	inFlight int
+	stations map[string]*station
+}
+
+type station struct {
+	url   string
+	title string
+	// next is the time of the next poll
+	next     time.Time
+	failures int
+	polling  bool
+}
+
+// New returns a poller fetching each station every interval, at most
+// concurrency stations at a time.
+func New(fetch FetchFunc, interval time.Duration, concurrency int) *Poller {
+	return &Poller{
+		fetch:       fetch,
+		interval:    interval,
+		concurrency: max(concurrency, 1),
+		tick:        tickInterval,
+		updates:     make(chan Update),
+		stations:    make(map[string]*station),
+	}
+}
+
+// Updates returns the changed titles, sent while Run is running.
+func (p *Poller) Updates() <-chan Update {
+	return p.updates
+}
+
+// SetEnabled starts or stops the polling; the known titles are forgotten
+// when stopped.
+func (p *Poller) SetEnabled(v bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = v
+	if !v {
+		for _, s := range p.stations {
+			s.title = ""
+			s.failures = 0
+		}
+	}
+	p.stagger(time.Now(), func(*station) bool { return true })
+}
+
+// Enabled reports whether the polling is started.
+func (p *Poller) Enabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enabled
+}
+
+// SetStations replaces the polled stations. The new ones are spread over
+// the poll interval instead of being polled all at once.
+func (p *Poller) SetStations(stations []model.Station) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make(map[string]bool, len(stations))
+	added := make(map[*station]bool)
+	for _, s := range stations {
+		url := s.URL
+		if url == "" {
+			continue
+		}
+		ids[s.Stationuuid] = true
+		if st, ok := p.stations[s.Stationuuid]; ok && st.url == url {
+			continue
+		}
+		st := &station{url: url}
+		p.stations[s.Stationuuid] = st
+		added[st] = true
+	}
+	for id := range p.stations {
+		if !ids[id] {
+			delete(p.stations, id)
+		}
+	}
+	p.stagger(time.Now(), func(s *station) bool { return added[s] })
+}
+
+// stagger schedules the matching stations evenly over the next interval.
+func (p *Poller) stagger(now time.Time, match func(*station) bool) {
+	var ids []string
+	for id, s := range p.stations {
+		if match(s) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for i, id := range ids {
+		p.stations[id].next = now.Add(p.interval * time.Duration(i) / time.Duration(len(ids)))
+	}
+}
+
+// Title returns the last polled title of the station.
+func (p *Poller) Title(stationID string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.stations[stationID]
+	if !ok || s.title == "" {
+		return "", false
+	}
+	return s.title, true
+}
+
+// Run polls the due stations until ctx is done.
+func (p *Poller) Run(ctx context.Context) {
+	tick := time.NewTicker(p.tick)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-tick.C:
+			for id, url := range p.due(now) {
+				go p.poll(ctx, id, url)
+			}
+		}
+	}
+}
+
+// due marks the stations to poll now, within the concurrency limit, the
+// longest overdue first.
+func (p *Poller) due(now time.Time) map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.enabled {
+		return nil
+	}
+
+	var ids []string
+	for id, s := range p.stations {
+		if !s.polling && !s.next.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		return p.stations[a].next.Compare(p.stations[b].next)
+	})
+
+	res := make(map[string]string)
+	for _, id := range ids {
+		if p.inFlight >= p.concurrency {
+			break
+		}
+		p.inFlight++
+		p.stations[id].polling = true
+		res[id] = p.stations[id].url
+	}
+	return res
+}
+
+func (p *Poller) poll(ctx context.Context, id string, url string) {
+	log := slog.With("method", "nowplaying.Poller.poll", "station", id)
+
+	fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	title, err := p.fetch(fetchCtx, url)
+	cancel()
+
+	p.mu.Lock()
+	p.inFlight--
+	s, ok := p.stations[id]
+	if !ok || s.url != url {
+		// removed or replaced meanwhile
+		p.mu.Unlock()
+		return
+	}
+	s.polling = false
+	now := time.Now()
+	if err != nil {
+		log.Info("poll failed", "failures", s.failures+1, "err", err)
+		s.failures++
+		s.next = now.Add(p.backoff(s.failures))
+		title = ""
+	} else {
+		s.failures = 0
+		s.next = now.Add(p.interval)
+	}
+	if !p.enabled {
+		title = ""
+	}
+	changed := s.title != title
+	s.title = title
+	p.mu.Unlock()
+
+	if changed {
+		select {
+		case p.updates <- Update{StationID: id, Title: title}:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// backoff doubles the poll interval with each consecutive failure.
+func (p *Poller) backoff(failures int) time.Duration {
+	d := p.interval
+	for range failures {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,123 @@
+package nowplaying
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dancnb/sonicradio/model"
+)
+
+func TestPoller(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+	fetch := func(ctx context.Context, url string) (string, error) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		if url == "http://broken" {
+			return "", errors.New("connection refused")
+		}
+		return "song of " + url, nil
+	}
+
+	p := New(fetch, time.Hour, 2)
+	p.tick = 5 * time.Millisecond
+	p.interval = 50 * time.Millisecond
+	p.SetEnabled(true)
+	p.SetStations([]model.Station{
+		{Stationuuid: "a", URL: "http://a"},
+		{Stationuuid: "b", URL: "http://b"},
+		{Stationuuid: "c", URL: "http://c"},
+		{Stationuuid: "d", URL: "http://d"},
+		{Stationuuid: "broken", URL: "http://broken"},
+		{Stationuuid: "no-url"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go p.Run(ctx)
+
+	titles := make(map[string]string)
+	for len(titles) < 4 {
+		select {
+		case u := <-p.Updates():
+			titles[u.StationID] = u.Title
+		case <-ctx.Done():
+			t.Fatalf("updates = %v", titles)
+		}
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if titles[id] != "song of http://"+id {
+			t.Errorf("title of %s = %q", id, titles[id])
+		}
+		if title, ok := p.Title(id); !ok || title != titles[id] {
+			t.Errorf("Title(%s) = %q, %v", id, title, ok)
+		}
+	}
+	if _, ok := titles["broken"]; ok {
+		t.Error("update for the failing station")
+	}
+	mu.Lock()
+	if maxActive > 2 {
+		t.Errorf("concurrent polls = %d, want <= 2", maxActive)
+	}
+	mu.Unlock()
+
+	p.mu.Lock()
+	if s := p.stations["broken"]; s.failures == 0 {
+		t.Error("failing station has no failures")
+	}
+	if _, ok := p.stations["no-url"]; ok {
+		t.Error("station without URL is polled")
+	}
+	p.mu.Unlock()
+
+	p.SetEnabled(false)
+	if _, ok := p.Title("a"); ok {
+		t.Error("title kept after disabling")
+	}
+}
+
+func TestPoller_stagger(t *testing.T) {
+	p := New(nil, time.Minute, 1)
+	var stations []model.Station
+	for _, id := range []string{"a", "b", "c", "d"} {
+		stations = append(stations, model.Station{Stationuuid: id, URL: "http://" + id})
+	}
+	p.SetStations(stations)
+
+	first := p.stations["a"].next
+	for i, id := range []string{"a", "b", "c", "d"} {
+		if got, want := p.stations[id].next.Sub(first), time.Duration(i)*15*time.Second; got != want {
+			t.Errorf("%s polled after %v, want %v", id, got, want)
+		}
+	}
+
+	// the known stations keep their schedule
+	p.SetStations(append(stations, model.Station{Stationuuid: "e", URL: "http://e"}))
+	if !p.stations["d"].next.Equal(first.Add(45 * time.Second)) {
+		t.Error("known station rescheduled")
+	}
+}
+
+func TestPoller_backoff(t *testing.T) {
+	p := New(nil, 2*time.Minute, 1)
+	for failures, want := range map[int]time.Duration{
+		1:  4 * time.Minute,
+		3:  16 * time.Minute,
+		4:  maxBackoff,
+		40: maxBackoff,
+	} {
+		if got := p.backoff(failures); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
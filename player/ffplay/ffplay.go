@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os/exec"
 	"slices"
 	"strings"
@@ -35,23 +36,38 @@ var (
 		"-autoexit",
 		"-volume",
 	}
-	volArg = "%d"
+	volArg       = "%d"
+	httpProxyArg = "-http_proxy"
 )
 
 type FFPlay struct {
 	url     string
 	playing *exec.Cmd
+	proxy   model.ProxyFunc
 
 	pt     *playerutils.PlaybackTime
 	volume int
 }
 
-func NewFFPlay(ctx context.Context) (*FFPlay, error) {
+func NewFFPlay(ctx context.Context, proxy model.ProxyFunc) (*FFPlay, error) {
 	return &FFPlay{
-		pt: playerutils.NewPlaybackTime(),
+		pt:    playerutils.NewPlaybackTime(),
+		proxy: proxy,
 	}, nil
 }
 
+// proxyArgs returns the ffplay option for an HTTP proxy, ffplay does not support SOCKS proxies.
+func proxyArgs(proxy *url.URL) []string {
+	if proxy == nil {
+		return nil
+	}
+	if proxy.Scheme != "http" {
+		slog.Warn("ffplay supports only HTTP proxies", "proxy", proxy.Redacted())
+		return nil
+	}
+	return []string{httpProxyArg, proxy.String()}
+}
+
 var errplay = errors.New("FFplay command error")
 
 func (f *FFPlay) Play(url string) error {
@@ -73,6 +89,12 @@ func (f *FFPlay) play(url string) error {
 
 	args := slices.Clone(baseArgs)
 	args = append(args, fmt.Sprintf(volArg, f.volume))
+	proxy, err := f.proxy(url)
+	if err != nil {
+		log.Error("stream proxy", "error", err)
+		return err
+	}
+	args = append(args, proxyArgs(proxy)...)
 	args = append(args, url)
 	cmd := exec.Command(GetBaseCmd(), args...)
 	if errors.Is(cmd.Err, exec.ErrDot) {
@@ -83,7 +105,7 @@ func (f *FFPlay) play(url string) error {
 	}
 	log.Info("cmd", "args", cmd.Args)
 	cmd.Stderr = &bytes.Buffer{}
-	err := cmd.Start()
+	err = cmd.Start()
 	if err != nil {
 		log.Error("ffplay cmd start", "error", err)
 		return err
@@ -180,6 +202,10 @@ func (f *FFPlay) Seek(amtSec int) *model.Metadata {
 	return nil
 }
 
+func (f *FFPlay) SeekTo(target model.SeekTarget) *model.Metadata {
+	return nil
+}
+
 func (f *FFPlay) Close() error {
 	return nil
 }
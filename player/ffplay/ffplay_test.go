@@ -8,7 +8,7 @@ import (
 
 func TestFFPlay(t *testing.T) {
 	ctx := context.Background()
-	p, err := NewFFPlay(ctx)
+	p, err := NewFFPlay(ctx, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"log/slog"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/player/model"
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	deadAirWindow        = time.Second / 10
+	deadAirCheckInterval = time.Second
+)
+
+// deadAirDetector tracks sustained silence in the decoded samples and
+// sustained stalls of the samples handed to the speaker.
+type deadAirDetector struct {
+	enabled bool
+
+	sampleRate     beep.SampleRate
+	threshold      float64 // linear RMS amplitude
+	silenceSamples int
+	stallDur       time.Duration
+
+	// accessed only from the decoding goroutine
+	windowSize int
+	windowSum  float64
+	windowN    int
+	silentN    int
+
+	lastSample    atomic.Int64 // unix nanoseconds
+	paused        atomic.Bool
+	stallReported atomic.Bool
+	event         atomic.Pointer[model.Event]
+}
+
+func newDeadAirDetector(cfg config.DeadAir, sr beep.SampleRate) *deadAirDetector {
+	d := &deadAirDetector{
+		enabled:        cfg.Action != config.DeadAirIgnore,
+		sampleRate:     sr,
+		threshold:      math.Pow(10, cfg.GetSilenceThresholdDb()/20),
+		silenceSamples: sr.N(time.Duration(cfg.GetSilenceSeconds()) * time.Second),
+		stallDur:       time.Duration(cfg.GetStallSeconds()) * time.Second,
+		windowSize:     max(1, sr.N(deadAirWindow)),
+	}
+	d.touch(time.Now())
+	return d
+}
+
+// addSample must be called from a single goroutine with every decoded sample.
+func (d *deadAirDetector) addSample(s [2]float64) {
+	if d == nil || !d.enabled {
+		return
+	}
+	d.windowSum += (s[0]*s[0] + s[1]*s[1]) / 2
+	d.windowN++
+	if d.windowN < d.windowSize {
+		return
+	}
+
+	rms := math.Sqrt(d.windowSum / float64(d.windowN))
+	if rms < d.threshold {
+		d.silentN += d.windowN
+	} else {
+		d.silentN = 0
+	}
+	d.windowSum = 0
+	d.windowN = 0
+
+	if d.silentN >= d.silenceSamples {
+		dur := d.sampleRate.D(d.silentN)
+		slog.Info("deadAirDetector", "silence", dur)
+		d.event.Store(&model.Event{Type: model.SilenceEvent, Duration: dur})
+		d.silentN = 0
+	}
+}
+
+// touch marks that samples were handed to the speaker at the given time.
+func (d *deadAirDetector) touch(now time.Time) {
+	if d == nil {
+		return
+	}
+	d.lastSample.Store(now.UnixNano())
+	d.stallReported.Store(false)
+}
+
+func (d *deadAirDetector) setPaused(v bool) {
+	if d == nil {
+		return
+	}
+	d.paused.Store(v)
+	if !v {
+		d.touch(time.Now())
+	}
+}
+
+func (d *deadAirDetector) checkStall(now time.Time) {
+	if d == nil || !d.enabled || d.paused.Load() || d.stallReported.Load() {
+		return
+	}
+	since := now.Sub(time.Unix(0, d.lastSample.Load()))
+	if since < d.stallDur {
+		return
+	}
+	slog.Info("deadAirDetector", "stall", since)
+	d.stallReported.Store(true)
+	d.event.Store(&model.Event{Type: model.StallEvent, Duration: since})
+}
+
+// popEvent returns the last detected event, if any, and clears it.
+func (d *deadAirDetector) popEvent() *model.Event {
+	if d == nil {
+		return nil
+	}
+	return d.event.Swap(nil)
+}
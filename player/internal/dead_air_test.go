@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/player/model"
+	"github.com/gopxl/beep/v2"
+)
+
+func Test_deadAirDetector_silence(t *testing.T) {
+	sr := beep.SampleRate(1000)
+	d := newDeadAirDetector(config.DeadAir{SilenceSeconds: 2}, sr)
+
+	for range sr.N(time.Second) {
+		d.addSample([2]float64{0.5, -0.5})
+	}
+	for range sr.N(time.Second) {
+		d.addSample([2]float64{0.0001, 0})
+	}
+	if ev := d.popEvent(); ev != nil {
+		t.Fatalf("unexpected event after 1s of silence: %+v", ev)
+	}
+
+	for range sr.N(time.Second) {
+		d.addSample([2]float64{0, 0.0001})
+	}
+	ev := d.popEvent()
+	if ev == nil || ev.Type != model.SilenceEvent {
+		t.Fatalf("expected silence event, got %+v", ev)
+	}
+	if ev.Duration < 2*time.Second {
+		t.Errorf("silence duration %v < 2s", ev.Duration)
+	}
+	if ev := d.popEvent(); ev != nil {
+		t.Errorf("event not cleared after pop: %+v", ev)
+	}
+}
+
+func Test_deadAirDetector_stall(t *testing.T) {
+	d := newDeadAirDetector(config.DeadAir{StallSeconds: 3}, beep.SampleRate(1000))
+	now := time.Now()
+	d.touch(now)
+
+	d.checkStall(now.Add(2 * time.Second))
+	if ev := d.popEvent(); ev != nil {
+		t.Fatalf("unexpected stall event: %+v", ev)
+	}
+
+	d.setPaused(true)
+	d.checkStall(now.Add(10 * time.Second))
+	if ev := d.popEvent(); ev != nil {
+		t.Fatalf("unexpected stall event while paused: %+v", ev)
+	}
+	d.setPaused(false)
+
+	d.touch(now)
+	d.checkStall(now.Add(4 * time.Second))
+	ev := d.popEvent()
+	if ev == nil || ev.Type != model.StallEvent {
+		t.Fatalf("expected stall event, got %+v", ev)
+	}
+	d.checkStall(now.Add(5 * time.Second))
+	if ev := d.popEvent(); ev != nil {
+		t.Errorf("stall reported twice: %+v", ev)
+	}
+}
+
+func Test_deadAirDetector_ignore(t *testing.T) {
+	sr := beep.SampleRate(1000)
+	d := newDeadAirDetector(config.DeadAir{Action: config.DeadAirIgnore, SilenceSeconds: 1}, sr)
+	for range sr.N(2 * time.Second) {
+		d.addSample([2]float64{0, 0})
+	}
+	d.checkStall(time.Now().Add(time.Hour))
+	if ev := d.popEvent(); ev != nil {
+		t.Errorf("unexpected event with ignore action: %+v", ev)
+	}
+
+	var nilDetector *deadAirDetector
+	nilDetector.addSample([2]float64{0, 0})
+	nilDetector.checkStall(time.Now())
+	if ev := nilDetector.popEvent(); ev != nil {
+		t.Errorf("unexpected event from nil detector: %+v", ev)
+	}
+}
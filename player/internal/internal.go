@@ -3,6 +3,7 @@ package internal
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/dancnb/sonicradio/config"
@@ -16,17 +17,19 @@ type Internal struct {
 	volume int
 	cfg    config.InternalPlayer
 	buffer [][2]float64
+	client *http.Client
 
 	// streamer
 	cancelFn     context.CancelFunc
 	buffStreamer *bufferedStreamer
 }
 
-func New(ctx context.Context, volume int, cfg config.InternalPlayer) *Internal {
+func New(ctx context.Context, volume int, cfg config.InternalPlayer, client *http.Client) *Internal {
 	return &Internal{
 		volume: volume,
 		cfg:    cfg,
-		buffer: newBuffer(cfg.BufferSeconds),
+		buffer: newBuffer(cfg),
+		client: client,
 	}
 }
 
@@ -40,7 +43,7 @@ func (i *Internal) Play(url string) error {
 	var ctx context.Context
 	ctx, cancelFn := context.WithCancel(context.Background())
 	clear(i.buffer)
-	buffStreamer, err := newBufferedStreamer(ctx, url, i.volume, i.buffer)
+	buffStreamer, err := newBufferedStreamer(ctx, i.client, url, i.volume, i.buffer, i.cfg)
 	if err != nil {
 		slog.Info("newBufferedStreamer", "err", err.Error())
 		cancelFn()
@@ -85,21 +88,34 @@ func (i *Internal) Metadata() *model.Metadata {
 	return &model.Metadata{
 		Title:           i.buffStreamer.getTitle(*posSec),
 		PlaybackTimeSec: posSec,
+		Buffered:        i.buffStreamer.bufferedRange(),
+		Network:         i.buffStreamer.networkBuffer(),
+		Event:           i.buffStreamer.deadAir.popEvent(),
 	}
 }
 
 func (i *Internal) Seek(amtSec int) *model.Metadata {
-	if i.cfg.BufferSeconds > 0 {
+	if i.cfg.BufferSeconds > 0 || i.cfg.TimeshiftMinutes > 0 {
 		i.buffStreamer.seekSec(amtSec)
 		return i.Metadata()
 	}
 	return nil
 }
 
+func (i *Internal) SeekTo(target model.SeekTarget) *model.Metadata {
+	if i.cfg.BufferSeconds > 0 || i.cfg.TimeshiftMinutes > 0 {
+		i.buffStreamer.seekTo(target)
+		return i.Metadata()
+	}
+	return nil
+}
+
 func (i *Internal) Close() error { return nil }
 
-func newBuffer(bufferSeconds int) [][2]float64 {
-	if bufferSeconds <= 0 {
+// newBuffer allocates the in-memory buffer, unless the disk-backed timeshift is enabled.
+func newBuffer(cfg config.InternalPlayer) [][2]float64 {
+	bufferSeconds := cfg.BufferSeconds
+	if bufferSeconds <= 0 || cfg.TimeshiftMinutes > 0 {
 		return nil
 	}
 	sr := beep.SampleRate(defSampleRate)
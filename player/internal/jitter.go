@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	// bitrate assumed for the pre-roll when the stream does not report one
+	defBitrateKbps   = 128
+	jitterMinBufSize = 256 * 1024
+)
+
+// jitterBuffer is an in-memory read-ahead buffer of compressed stream bytes,
+// decoupling network reads from decoding. Reads wait for the pre-roll amount
+// before starting, and again after each underrun.
+type jitterBuffer struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	data []byte
+	rx   int // read index
+	n    int // buffered bytes
+
+	prebuffer int
+	buffering bool
+	started   bool
+	underruns int
+	eof       bool
+	closed    bool
+}
+
+func newJitterBuffer(prebuffer int) *jitterBuffer {
+	jb := &jitterBuffer{
+		data:      make([]byte, max(4*prebuffer, jitterMinBufSize)),
+		prebuffer: prebuffer,
+		buffering: prebuffer > 0,
+	}
+	jb.cond = sync.NewCond(&jb.mu)
+	return jb
+}
+
+// Write blocks while the buffer is full.
+func (jb *jitterBuffer) Write(p []byte) (int, error) {
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	written := 0
+	for written < len(p) {
+		for jb.n == len(jb.data) && !jb.closed {
+			jb.cond.Wait()
+		}
+		if jb.closed {
+			return written, io.ErrClosedPipe
+		}
+		wx := (jb.rx + jb.n) % len(jb.data)
+		end := len(jb.data)
+		if wx < jb.rx {
+			end = jb.rx
+		}
+		c := copy(jb.data[wx:end], p[written:])
+		jb.n += c
+		written += c
+		jb.cond.Broadcast()
+	}
+	return written, nil
+}
+
+func (jb *jitterBuffer) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	counted := false
+	for {
+		if jb.closed {
+			return 0, io.ErrClosedPipe
+		}
+		if jb.buffering && (jb.n >= jb.prebuffer || jb.eof) {
+			jb.buffering = false
+		}
+		if !jb.buffering && jb.n > 0 {
+			break
+		}
+		if jb.n == 0 && jb.eof {
+			return 0, io.EOF
+		}
+		if jb.started && !jb.buffering && !counted {
+			jb.underruns++
+			jb.buffering = jb.prebuffer > 0
+			counted = true
+		}
+		jb.cond.Wait()
+	}
+
+	end := min(len(jb.data), jb.rx+jb.n)
+	c := copy(p, jb.data[jb.rx:end])
+	jb.rx = (jb.rx + c) % len(jb.data)
+	jb.n -= c
+	jb.started = true
+	jb.cond.Broadcast()
+	return c, nil
+}
+
+// CloseWrite marks the end of the stream; buffered bytes can still be read.
+func (jb *jitterBuffer) CloseWrite() error {
+	jb.mu.Lock()
+	jb.eof = true
+	jb.cond.Broadcast()
+	jb.mu.Unlock()
+	return nil
+}
+
+// Close releases both ends of the buffer.
+func (jb *jitterBuffer) Close() error {
+	jb.mu.Lock()
+	jb.closed = true
+	jb.cond.Broadcast()
+	jb.mu.Unlock()
+	return nil
+}
+
+// stats returns the buffered bytes and the number of underruns.
+func (jb *jitterBuffer) stats() (fill int, underruns int) {
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+	return jb.n, jb.underruns
+}
+
+// jitterWriter is the network side of a jitterBuffer.
+type jitterWriter struct{ *jitterBuffer }
+
+func (w jitterWriter) Close() error { return w.CloseWrite() }
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func readAsync(r io.Reader, n int) <-chan []byte {
+	res := make(chan []byte, 1)
+	go func() {
+		b := make([]byte, n)
+		m, _ := io.ReadFull(r, b)
+		res <- b[:m]
+	}()
+	return res
+}
+
+func Test_jitterBuffer_prebuffer(t *testing.T) {
+	jb := newJitterBuffer(8)
+	res := readAsync(jb, 4)
+
+	_, _ = jb.Write([]byte("abcd"))
+	select {
+	case b := <-res:
+		t.Fatalf("read %q before the pre-roll was buffered", b)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_, _ = jb.Write([]byte("efgh"))
+	select {
+	case b := <-res:
+		if string(b) != "abcd" {
+			t.Errorf("read %q", b)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("read did not start after the pre-roll")
+	}
+	if fill, underruns := jb.stats(); fill != 4 || underruns != 0 {
+		t.Errorf("stats = %d, %d", fill, underruns)
+	}
+}
+
+func Test_jitterBuffer_underrun(t *testing.T) {
+	jb := newJitterBuffer(4)
+	_, _ = jb.Write([]byte("abcd"))
+	if b := <-readAsync(jb, 4); string(b) != "abcd" {
+		t.Fatalf("read %q", b)
+	}
+
+	// empty buffer: the reader waits for the pre-roll again
+	res := readAsync(jb, 2)
+	time.Sleep(10 * time.Millisecond)
+	_, _ = jb.Write([]byte("ef"))
+	select {
+	case b := <-res:
+		t.Fatalf("read %q while rebuffering", b)
+	case <-time.After(20 * time.Millisecond):
+	}
+	_, _ = jb.Write([]byte("gh"))
+	if b := <-res; string(b) != "ef" {
+		t.Errorf("read %q", b)
+	}
+	if _, underruns := jb.stats(); underruns != 1 {
+		t.Errorf("underruns = %d, want 1", underruns)
+	}
+
+	// end of stream flushes the remaining bytes
+	_ = jitterWriter{jb}.Close()
+	rest, err := io.ReadAll(jb)
+	if err != nil || string(rest) != "gh" {
+		t.Errorf("read %q, err %v", rest, err)
+	}
+}
+
+func Test_jitterBuffer_wrap(t *testing.T) {
+	jb := newJitterBuffer(0)
+	size := len(jb.data)
+	data := bytes.Repeat([]byte("0123456789"), size/4)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := jb.Write(data)
+		_ = jitterWriter{jb}.Close()
+		done <- err
+	}()
+	got, err := io.ReadAll(jb)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes, err %v", len(got), err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_jitterBuffer_close(t *testing.T) {
+	jb := newJitterBuffer(0)
+	_, _ = jb.Write(make([]byte, len(jb.data)))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := jb.Write([]byte("blocked"))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_ = jb.Close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("write err %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("writer not released on close")
+	}
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// titleBlocks is the number of metadata blocks read before giving up on a
+// title, as some servers send an empty block right after connecting.
+const titleBlocks = 2
+
+var (
+	errNoMetadata = errors.New("stream does not send icy metadata")
+	errNoTitle    = errors.New("stream title not found")
+)
+
+// StreamTitle connects to the stream, reads just enough of it to get the
+// current song title from its icy metadata, and disconnects.
+func StreamTitle(ctx context.Context, client *http.Client, url string) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resp, metaInfo, err := openStream(ctx, client, url)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("stream status: %s", resp.Status)
+	}
+	if metaInfo.Metaint <= 0 {
+		return "", errNoMetadata
+	}
+
+	r := bufio.NewReader(resp.Body)
+	for range titleBlocks {
+		if _, err := io.CopyN(io.Discard, r, int64(metaInfo.Metaint)); err != nil {
+			return "", fmt.Errorf("read stream audio data: %w", err)
+		}
+		metaLen, err := r.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("read stream metadata length: %w", err)
+		}
+		if metaLen == 0 {
+			continue
+		}
+		metaData := make([]byte, int(metaLen)*16)
+		if _, err := io.ReadFull(r, metaData); err != nil {
+			return "", fmt.Errorf("read stream metadata content: %w", err)
+		}
+		if title, ok := parseStreamTitle(string(metaData)); ok {
+			return strings.TrimSpace(title), nil
+		}
+	}
+	return "", errNoTitle
+}
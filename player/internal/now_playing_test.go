@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_StreamTitle(t *testing.T) {
+	const metaint = 8
+	meta := []byte("StreamTitle='Artist - Song';StreamUrl='';")
+	meta = append(meta, make([]byte, 16-len(meta)%16)...)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Icy-MetaData") != "1" {
+			t.Error("missing Icy-MetaData request header")
+		}
+		if r.URL.Path == "/plain" {
+			_, _ = w.Write(bytes.Repeat([]byte{0xff}, 64))
+			return
+		}
+		w.Header().Set("icy-metaint", "8")
+		var b bytes.Buffer
+		// an empty block first, then the title
+		b.Write(bytes.Repeat([]byte{0xff}, metaint))
+		b.WriteByte(0)
+		b.Write(bytes.Repeat([]byte{0xff}, metaint))
+		b.WriteByte(byte(len(meta) / 16))
+		b.Write(meta)
+		b.Write(bytes.Repeat([]byte{0xff}, metaint))
+		_, _ = w.Write(b.Bytes())
+	}))
+	defer srv.Close()
+
+	title, err := StreamTitle(context.Background(), srv.Client(), srv.URL+"/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "Artist - Song" {
+		t.Errorf("title = %q", title)
+	}
+
+	if _, err := StreamTitle(context.Background(), srv.Client(), srv.URL+"/plain"); err == nil {
+		t.Error("stream without metadata error = nil")
+	}
+}
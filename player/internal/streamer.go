@@ -13,8 +13,11 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/player/model"
 	"github.com/gopxl/beep/v2"
 	"github.com/gopxl/beep/v2/effects"
 	"github.com/gopxl/beep/v2/mp3"
@@ -57,20 +60,35 @@ type bufferedStreamer struct {
 	format       beep.Format           // used for getPositionSeconds
 	ctrl         *beep.Ctrl            // used for togglePause
 	volume       *effects.Volume
+	deadAir      *deadAirDetector
+
+	// network read-ahead, jitter is nil when ts is used
+	jitter      *jitterBuffer
+	bytesPerSec int64
+	prebuffer   time.Duration
+
+	// disk-backed timeshift, used instead of data when enabled
+	ts        *timeshift
+	tsReader  *tsReader
+	tsBase    int64        // timeline position where the current decoder started
+	tsPos     atomic.Int64 // current timeline position in samples
+	decoderFn func(rc io.ReadCloser) (s beep.StreamSeekCloser, format beep.Format, err error)
 }
 
 func newBufferedStreamer(
 	ctx context.Context,
+	client *http.Client,
 	url string,
 	volume int,
 	buffer [][2]float64,
+	cfg config.InternalPlayer,
 ) (*bufferedStreamer, error) {
 	log := slog.With("caller", "newBufferedStreamer", "url", url)
 	log.Info("start")
 	defer func() { log.Info("end") }()
 
 	// -- Network read
-	resp, metaInfo, err := openStream(ctx, url)
+	resp, metaInfo, err := openStream(ctx, client, url)
 	if err != nil {
 		return nil, fmt.Errorf("open stream err: %w", err)
 	}
@@ -97,18 +115,45 @@ func newBufferedStreamer(
 		if plsURL == "" {
 			return nil, fmt.Errorf("could not parse URL from playlist file [%s]", url)
 		}
-		return newBufferedStreamer(ctx, plsURL, volume, buffer)
+		return newBufferedStreamer(ctx, client, plsURL, volume, buffer, cfg)
 	}
 
+	decoderFn, err := getDecoder(metaInfo.ContentType)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	bitrate := metaInfo.Br
+	if bitrate <= 0 {
+		bitrate = defBitrateKbps
+	}
 	bs := &bufferedStreamer{
-		url:   url,
-		title: make(map[int64]string),
-		ch:    make(chan [2]float64),
-		done:  make(chan struct{}),
-		data:  buffer,
+		url:         url,
+		title:       make(map[int64]string),
+		ch:          make(chan [2]float64),
+		done:        make(chan struct{}),
+		data:        buffer,
+		decoderFn:   decoderFn,
+		bytesPerSec: int64(bitrate) * 1000 / 8,
+		prebuffer:   time.Duration(cfg.GetPrebufferSeconds()) * time.Second,
 	}
+	prebufferBytes := int64(cfg.GetPrebufferSeconds()) * bs.bytesPerSec
 
-	audioPipeR, audioPipeW := io.Pipe()
+	var audioR io.ReadCloser
+	var audioW io.WriteCloser
+	if cfg.TimeshiftMinutes > 0 {
+		bs.ts, err = newTimeshift(cfg.TimeshiftMinutes, metaInfo.Br, metaInfo.ContentType, prebufferBytes)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("create timeshift file err: %w", err)
+		}
+		bs.tsReader = bs.ts.newReader(0)
+		audioR, audioW = bs.tsReader, bs.ts
+	} else {
+		bs.jitter = newJitterBuffer(int(prebufferBytes))
+		audioR, audioW = bs.jitter, jitterWriter{bs.jitter}
+	}
 
 	titleCh := make(chan string, 1)
 	go func() {
@@ -117,13 +162,17 @@ func newBufferedStreamer(
 			case <-ctx.Done():
 				return
 			case t := <-titleCh:
-				bs.title[bs.streamPos] = t
+				if bs.ts != nil {
+					bs.ts.addTitle(t)
+				} else {
+					bs.title[bs.streamPos] = t
+				}
 			}
 		}
 	}()
 
 	bs.wg.Add(1)
-	go readStream(ctx, &bs.wg, url, audioPipeW, resp.Body, int64(metaInfo.Metaint), titleCh)
+	go readStream(ctx, &bs.wg, url, audioW, resp.Body, int64(metaInfo.Metaint), titleCh)
 
 	// -- Decode
 	// beep.Decode takes a ReadCloser containing audio data in MP3 format and returns a StreamSeekCloser,
@@ -131,15 +180,18 @@ func newBufferedStreamer(
 	//
 	// Do not close the supplied ReadSeekCloser, instead, use the Close method of the returned
 	// StreamSeekCloser when you want to release the resources.
-	decoderFn, err := getDecoder(metaInfo.ContentType)
-	if err != nil {
-		return nil, err
-	}
-	bs.beepStreamer, bs.format, err = decoderFn(audioPipeR)
+	bs.beepStreamer, bs.format, err = decoderFn(audioR)
 	if err != nil {
+		if bs.ts != nil {
+			_ = bs.ts.remove()
+		}
 		return nil, err
 	}
 	slog.Info("", "sampleRate", bs.format.SampleRate)
+	bs.deadAir = newDeadAirDetector(cfg.DeadAir, bs.format.SampleRate)
+	if bs.ts != nil {
+		bs.ts.setSampleRate(bs.format.SampleRate)
+	}
 
 	bs.wg.Add(1)
 	go func() {
@@ -152,8 +204,13 @@ func newBufferedStreamer(
 	_ = speaker.Init(bs.format.SampleRate, bs.format.SampleRate.N(time.Second/10))
 
 	// -- Buffer
+	if bs.ts == nil {
+		bs.wg.Add(1)
+		go bs.readDecodedSamples(ctx)
+	}
+
 	bs.wg.Add(1)
-	go bs.readDecodedSamples(ctx)
+	go bs.watchDeadAir(ctx)
 
 	// -- Play
 	bs.ctrl = &beep.Ctrl{Streamer: bs, Paused: false}
@@ -193,6 +250,7 @@ func (bs *bufferedStreamer) readDecodedSamples(ctx context.Context) {
 				log.Info("ctx done")
 				return
 			case bs.ch <- decodedSamples[i]:
+				bs.deadAir.addSample(decodedSamples[i])
 				if len(bs.data) > 0 {
 					wIdx := bs.wx % int64(len(bs.data))
 					bs.data[wIdx] = decodedSamples[i]
@@ -208,6 +266,10 @@ func (bs *bufferedStreamer) Stream(samples [][2]float64) (n int, ok bool) {
 	bs.rbSync.Lock()
 	defer bs.rbSync.Unlock()
 
+	if bs.ts != nil {
+		return bs.streamTimeshift(samples)
+	}
+
 	log := slog.With("method", "Stream", "url", bs.url)
 
 	i := 0
@@ -234,6 +296,7 @@ func (bs *bufferedStreamer) Stream(samples [][2]float64) (n int, ok bool) {
 		}
 		// filled samples completely from buffered data
 		if i == len(samples) {
+			bs.deadAir.touch(time.Now())
 			return i, true
 		}
 	}
@@ -248,10 +311,82 @@ func (bs *bufferedStreamer) Stream(samples [][2]float64) (n int, ok bool) {
 		i++
 	}
 
+	bs.deadAir.touch(time.Now())
 	return len(samples), len(samples) > 0
 }
 
+// streamTimeshift decodes directly from the timeshift file.
+//
+// caller must hold bs.rbSync
+func (bs *bufferedStreamer) streamTimeshift(samples [][2]float64) (n int, ok bool) {
+	n, ok = bs.beepStreamer.Stream(samples)
+	if !ok && bs.tsReader.isOverrun() {
+		// paused for longer than the timeshift holds, resume from the oldest audio
+		oldest, _, _ := bs.ts.bounds()
+		slog.Info("bufferedStreamer.streamTimeshift: read position overwritten", "resume", oldest)
+		if err := bs.openTimeshiftAt(oldest); err != nil {
+			slog.Error("bufferedStreamer.streamTimeshift", "error", err)
+			return 0, false
+		}
+		n, ok = bs.beepStreamer.Stream(samples)
+	}
+	if !ok {
+		return 0, false
+	}
+
+	pos := bs.tsBase + int64(bs.beepStreamer.Position())
+	bs.tsPos.Store(pos)
+	bs.ts.addCheckpoint(pos, bs.tsReader.offset())
+	for i := range n {
+		bs.deadAir.addSample(samples[i])
+	}
+	bs.deadAir.touch(time.Now())
+	return n, true
+}
+
+// openTimeshiftAt replaces the current decoder with one reading the
+// timeshift file from the checkpoint closest to the given position.
+//
+// caller must hold bs.rbSync
+func (bs *bufferedStreamer) openTimeshiftAt(sample int64) error {
+	cp, ok := bs.ts.seekPoint(sample)
+	if !ok {
+		return nil
+	}
+	_ = bs.beepStreamer.Close()
+
+	r := bs.ts.newReader(cp.offset)
+	s, _, err := bs.decoderFn(r)
+	if err != nil {
+		_ = r.Close()
+		return fmt.Errorf("timeshift decoder err: %w", err)
+	}
+	bs.beepStreamer, bs.tsReader, bs.tsBase = s, r, cp.sample
+	bs.tsPos.Store(cp.sample)
+	return nil
+}
+
+// watchDeadAir periodically checks whether the speaker is starved of samples.
+func (bs *bufferedStreamer) watchDeadAir(ctx context.Context) {
+	defer bs.wg.Done()
+
+	tick := time.NewTicker(deadAirCheckInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-tick.C:
+			bs.deadAir.checkStall(now)
+		}
+	}
+}
+
 func (bs *bufferedStreamer) seekSec(amtSec int) {
+	if bs.ts != nil {
+		bs.seekTimeshift(amtSec)
+		return
+	}
 	if len(bs.data) == 0 {
 		return
 	}
@@ -278,6 +413,147 @@ func (bs *bufferedStreamer) seekSec(amtSec int) {
 	bs.rbx = pos
 }
 
+func (bs *bufferedStreamer) seekTimeshift(amtSec int) {
+	bs.rbSync.Lock()
+	defer bs.rbSync.Unlock()
+
+	log := slog.With("method", "bufferedStreamer.seekTimeshift", "url", bs.url)
+
+	oldest, live, ok := bs.ts.bounds()
+	if !ok {
+		return
+	}
+	pos := bs.tsPos.Load()
+	if amtSec > 0 {
+		pos += int64(bs.secondsToSamples(amtSec))
+	} else {
+		pos -= int64(bs.secondsToSamples(-amtSec))
+	}
+	pos = max(pos, oldest)
+	pos = min(pos, live)
+	log.Info("", "oldest", oldest, "live", live, "newPos", pos)
+
+	if err := bs.openTimeshiftAt(pos); err != nil {
+		log.Error("seek", "error", err)
+	}
+}
+
+// songBoundaryToleranceSec absorbs the rounding of the playback position,
+// so that a song is still current right after seeking to its start.
+const songBoundaryToleranceSec = 1
+
+func (bs *bufferedStreamer) seekTo(target model.SeekTarget) {
+	if bs == nil {
+		return
+	}
+	pos := bs.getPositionSeconds()
+	r := bs.bufferedRange()
+	if r == nil || pos == nil {
+		return
+	}
+	dest := songSeekTarget(target, *pos, *r, bs.titleStarts())
+	slog.Info("bufferedStreamer.seekTo", "target", target, "pos", *pos, "dest", dest)
+	bs.seekSec(int(dest - *pos))
+}
+
+// songSeekTarget returns the timeline second to seek to, clamped to the buffered range.
+// starts must be sorted.
+func songSeekTarget(target model.SeekTarget, pos int64, r model.BufferedRange, starts []int64) int64 {
+	var dest int64
+	switch target {
+	case model.SeekLive:
+		dest = r.LiveSec
+	case model.SeekNextSong:
+		dest = r.LiveSec
+		for _, s := range starts {
+			if s > pos+songBoundaryToleranceSec {
+				dest = s
+				break
+			}
+		}
+	case model.SeekSongStart, model.SeekPrevSong:
+		curr, prev := r.StartSec, r.StartSec
+		for _, s := range starts {
+			if s > pos+songBoundaryToleranceSec {
+				break
+			}
+			if s > r.StartSec {
+				prev, curr = curr, s
+			}
+		}
+		dest = curr
+		if target == model.SeekPrevSong {
+			dest = prev
+		}
+	}
+	return min(max(dest, r.StartSec), r.LiveSec)
+}
+
+// bufferedRange returns the seekable part of the timeline, nil if buffering is disabled.
+func (bs *bufferedStreamer) bufferedRange() *model.BufferedRange {
+	if bs == nil {
+		return nil
+	}
+	if bs.ts != nil {
+		oldest, live, ok := bs.ts.bounds()
+		if !ok {
+			return nil
+		}
+		return &model.BufferedRange{
+			StartSec: int64(bs.samplesToSeconds(int(oldest))),
+			LiveSec:  int64(bs.samplesToSeconds(int(live))),
+		}
+	}
+	if len(bs.data) == 0 {
+		return nil
+	}
+	buffered := min(bs.wx, int64(len(bs.data)))
+	return &model.BufferedRange{
+		StartSec: max(0, bs.streamPos-int64(bs.samplesToSeconds(int(buffered)))),
+		LiveSec:  bs.streamPos,
+	}
+}
+
+// networkBuffer reports the compressed data read ahead of the decoder.
+func (bs *bufferedStreamer) networkBuffer() *model.NetworkBuffer {
+	if bs == nil {
+		return nil
+	}
+	var fill int64
+	var underruns int
+	if bs.ts != nil {
+		fill, underruns = bs.ts.stats()
+	} else if bs.jitter != nil {
+		var f int
+		f, underruns = bs.jitter.stats()
+		fill = int64(f)
+	} else {
+		return nil
+	}
+	return &model.NetworkBuffer{
+		Fill:      time.Duration(fill) * time.Second / time.Duration(bs.bytesPerSec),
+		Prebuffer: bs.prebuffer,
+		Underruns: underruns,
+	}
+}
+
+// titleStarts returns the sorted timeline seconds where the recorded titles start.
+func (bs *bufferedStreamer) titleStarts() []int64 {
+	if bs.ts != nil {
+		starts := bs.ts.titleStarts()
+		for i := range starts {
+			starts[i] = int64(bs.samplesToSeconds(int(starts[i])))
+		}
+		return starts
+	}
+	starts := make([]int64, 0, len(bs.title))
+	for k := range bs.title {
+		starts = append(starts, k)
+	}
+	slices.Sort(starts)
+	return starts
+}
+
 func (bs *bufferedStreamer) secondsToSamples(sec int) int {
 	return bs.format.SampleRate.N(time.Second * time.Duration(sec))
 }
@@ -292,10 +568,15 @@ func (bs *bufferedStreamer) togglePause() {
 	}
 	speaker.Lock()
 	bs.ctrl.Paused = !bs.ctrl.Paused
+	paused := bs.ctrl.Paused
 	speaker.Unlock()
+	bs.deadAir.setPaused(paused)
 }
 
 func (bs *bufferedStreamer) getTitle(posSec int64) string {
+	if bs.ts != nil {
+		return bs.ts.titleAt(int64(bs.secondsToSamples(int(posSec))))
+	}
 	ts := make([]int64, len(bs.title))
 	i := 0
 	for k := range bs.title {
@@ -320,6 +601,12 @@ func (bs *bufferedStreamer) getPositionSeconds() *int64 {
 	// bs.rbSync.Lock()
 	// defer bs.rbSync.Unlock()
 
+	if bs.ts != nil {
+		posD := bs.format.SampleRate.D(int(bs.tsPos.Load()))
+		posSec := int64(posD.Round(time.Second).Seconds())
+		return &posSec
+	}
+
 	if bs.rbx == 0 {
 		bs.streamPos = bs.getStreamPosition()
 		return &bs.streamPos
@@ -357,13 +644,18 @@ func (bs *bufferedStreamer) Err() error { return nil }
 func (bs *bufferedStreamer) Close() error {
 	close(bs.done)
 
-	if bs.beepStreamer == nil {
-		return nil
+	var err error
+	if bs.beepStreamer != nil {
+		if cErr := bs.beepStreamer.Close(); cErr != nil {
+			err = fmt.Errorf("beepStreamer close err: %w", cErr)
+		}
 	}
-	if err := bs.beepStreamer.Close(); err != nil {
-		return fmt.Errorf("beepStreamer close err: %w", err)
+	if bs.ts != nil {
+		if rErr := bs.ts.remove(); rErr != nil {
+			err = errors.Join(err, fmt.Errorf("timeshift remove err: %w", rErr))
+		}
 	}
-	return nil
+	return err
 }
 
 // metaInfo contains icy headers.  Example(https://icecast.walmradio.com:8443/otr_opus):
@@ -384,7 +676,6 @@ func (bs *bufferedStreamer) Close() error {
 //	"icy-url: https://walmradio.com/otr"
 type metaInfo struct {
 	// Name string
-	// Br int
 	// Notice1 string
 	// URL string
 	// Notice2 string
@@ -393,11 +684,13 @@ type metaInfo struct {
 
 	Metaint     int
 	Sr          int
+	Br          int
 	ContentType string
 }
 
 func openStream(
 	ctx context.Context,
+	client *http.Client,
 	url string,
 ) (
 	resp *http.Response,
@@ -418,7 +711,7 @@ func openStream(
 		return
 	}
 	req.Header.Set("Icy-MetaData", "1")
-	resp, err = http.DefaultClient.Do(req)
+	resp, err = client.Do(req)
 	if err != nil {
 		return
 	}
@@ -438,6 +731,9 @@ func openStream(
 			}
 		}
 	}
+	if val := resp.Header.Get("icy-br"); val != "" {
+		_, _ = fmt.Sscanf(val, "%d", &metaInfo.Br)
+	}
 	if val := resp.Header.Get("content-type"); val != "" {
 		metaInfo.ContentType = val
 	}
@@ -503,21 +799,29 @@ func readStream(
 
 				metaStr := string(metaData)
 				log.Info("--- metadata: " + metaStr)
-				if strings.Contains(metaStr, "StreamTitle='") {
-					start := strings.Index(metaStr, "StreamTitle='") + len("StreamTitle='")
-					end := strings.Index(metaStr[start:], "';")
-					if end > 0 {
-						title := metaStr[start : start+end]
-						go func() {
-							titleCh <- title
-						}()
-					}
+				if title, ok := parseStreamTitle(metaStr); ok {
+					go func() {
+						titleCh <- title
+					}()
 				}
 			}
 		}
 	}
 }
 
+// parseStreamTitle returns the StreamTitle of an icy metadata block.
+func parseStreamTitle(metaStr string) (string, bool) {
+	if !strings.Contains(metaStr, "StreamTitle='") {
+		return "", false
+	}
+	start := strings.Index(metaStr, "StreamTitle='") + len("StreamTitle='")
+	end := strings.Index(metaStr[start:], "';")
+	if end <= 0 {
+		return "", false
+	}
+	return metaStr[start : start+end], true
+}
+
 var errAACNotAvailable = errors.New("AAC streams are not supported")
 
 func getDecoder(contentType string) (
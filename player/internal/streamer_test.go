@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"testing"
+
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/player/model"
 )
 
 // http://vibration.stream2net.eu:8220/;stream/1
@@ -39,7 +43,7 @@ func Test_openStream(t *testing.T) {
 
 	for _, url := range urls {
 		slog.Info(url)
-		resp, info, err := openStream(ctx, url)
+		resp, info, err := openStream(ctx, http.DefaultClient, url)
 		if err != nil {
 			t.Errorf("open stream err=%v", err)
 		} else if resp == nil {
@@ -60,7 +64,36 @@ func Test_playStream(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	if _, err := newBufferedStreamer(ctx, url, 100, nil); err != nil {
+	if _, err := newBufferedStreamer(ctx, http.DefaultClient, url, 100, nil, config.InternalPlayer{}); err != nil {
 		t.Error(err)
 	}
 }
+
+func Test_songSeekTarget(t *testing.T) {
+	r := model.BufferedRange{StartSec: 100, LiveSec: 400}
+	starts := []int64{50, 150, 250, 350}
+	tests := []struct {
+		name   string
+		target model.SeekTarget
+		pos    int64
+		want   int64
+	}{
+		{"song start", model.SeekSongStart, 300, 250},
+		{"song start right after seeking to it", model.SeekSongStart, 249, 250},
+		{"song start before first boundary", model.SeekSongStart, 120, 100},
+		{"previous song", model.SeekPrevSong, 300, 150},
+		{"previous song at song start", model.SeekPrevSong, 250, 150},
+		{"previous song clamped to buffer", model.SeekPrevSong, 200, 100},
+		{"next song", model.SeekNextSong, 200, 250},
+		{"next song at song start", model.SeekNextSong, 250, 350},
+		{"next song in last song", model.SeekNextSong, 380, 400},
+		{"live", model.SeekLive, 120, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := songSeekTarget(tt.target, tt.pos, r, starts); got != tt.want {
+				t.Errorf("songSeekTarget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
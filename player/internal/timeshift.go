@@ -0,0 +1,522 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	timeshiftDefBitrateKbps = 320
+	// maximum size of the ogg header pages replayed before each seek
+	timeshiftMaxOggHeader = 1 << 20
+	// minimum decoded span used to estimate the stream byte rate
+	timeshiftMinRateSpanSec = 10
+)
+
+var (
+	errTimeshiftOverrun = errors.New("timeshift read position was overwritten")
+	errTimeshiftRemoved = errors.New("timeshift file removed")
+
+	oggCapturePattern = []byte("OggS")
+)
+
+type tsCheckpoint struct {
+	sample int64 // timeline position in samples
+	offset int64 // stream byte offset
+}
+
+type tsTitle struct {
+	offset int64
+	title  string
+}
+
+// timeshift stores the compressed stream bytes in a rotating temp file.
+// Byte offsets are logical: offset o is stored at o % size in the file and is
+// retained until size more bytes are written.
+type timeshift struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	f       *os.File
+	size    int64
+	written int64
+	eof     bool
+	removed bool
+
+	bytesPerSec int64 // estimate used until enough checkpoints are recorded
+	sampleRate  beep.SampleRate
+
+	// readers wait for prebuffer bytes at the live edge before resuming
+	prebuffer int64
+	underruns int
+	readOff   int64 // offset of the last read, for the fill level
+
+	// ogg vorbis decoders need the header pages before any audio page
+	ogg       bool
+	oggHeader []byte
+	oggScan   int64
+	oggDone   bool
+
+	checkpoints []tsCheckpoint
+	titles      []tsTitle
+}
+
+func newTimeshift(minutes int, bitrateKbps int, contentType string, prebuffer int64) (*timeshift, error) {
+	if bitrateKbps <= 0 {
+		bitrateKbps = timeshiftDefBitrateKbps
+	}
+	f, err := os.CreateTemp("", "sonicradio-timeshift-*")
+	if err != nil {
+		return nil, err
+	}
+	ts := &timeshift{
+		f:           f,
+		bytesPerSec: int64(bitrateKbps) * 1000 / 8,
+		prebuffer:   prebuffer,
+	}
+	ts.size = int64(minutes) * 60 * ts.bytesPerSec
+	ct := strings.ToLower(contentType)
+	ts.ogg = ct == contentTypeOgg || ct == contentTypeOgg2
+	ts.oggDone = !ts.ogg
+	ts.cond = sync.NewCond(&ts.mu)
+	slog.Info("newTimeshift", "file", f.Name(), "minutes", minutes, "bitrateKbps", bitrateKbps, "size", ts.size)
+	return ts, nil
+}
+
+func (ts *timeshift) setSampleRate(sr beep.SampleRate) {
+	ts.mu.Lock()
+	ts.sampleRate = sr
+	ts.mu.Unlock()
+}
+
+// Write appends stream bytes, overwriting the oldest ones once the file is full.
+func (ts *timeshift) Write(p []byte) (int, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.removed {
+		return 0, errTimeshiftRemoved
+	}
+	for n := 0; n < len(p); {
+		chunk := p[n:min(len(p), n+int(ts.size))]
+		if err := ts.writeAt(chunk, ts.written); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		ts.written += int64(len(chunk))
+	}
+	if !ts.oggDone {
+		ts.scanOggHeader()
+	}
+	ts.cond.Broadcast()
+	return len(p), nil
+}
+
+// Close marks the end of the stream; already written bytes remain available.
+func (ts *timeshift) Close() error {
+	ts.mu.Lock()
+	ts.eof = true
+	ts.cond.Broadcast()
+	ts.mu.Unlock()
+	return nil
+}
+
+// remove closes and deletes the temp file.
+func (ts *timeshift) remove() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.removed {
+		return nil
+	}
+	ts.removed = true
+	ts.cond.Broadcast()
+	err := ts.f.Close()
+	if rmErr := os.Remove(ts.f.Name()); rmErr != nil {
+		err = errors.Join(err, rmErr)
+	}
+	return err
+}
+
+// caller must hold ts.mu
+func (ts *timeshift) oldestLocked() int64 {
+	return max(0, ts.written-ts.size)
+}
+
+// caller must hold ts.mu
+func (ts *timeshift) writeAt(p []byte, off int64) error {
+	pos := off % ts.size
+	n := min(int64(len(p)), ts.size-pos)
+	if _, err := ts.f.WriteAt(p[:n], pos); err != nil {
+		return err
+	}
+	if rest := p[n:]; len(rest) > 0 {
+		if _, err := ts.f.WriteAt(rest, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// caller must hold ts.mu
+func (ts *timeshift) readAt(p []byte, off int64) error {
+	pos := off % ts.size
+	n := min(int64(len(p)), ts.size-pos)
+	if _, err := ts.f.ReadAt(p[:n], pos); err != nil {
+		return err
+	}
+	if rest := p[n:]; len(rest) > 0 {
+		if _, err := ts.f.ReadAt(rest, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanOggHeader collects the leading pages with granule position 0, which
+// contain the vorbis headers. Audio data always starts on a fresh page.
+//
+// caller must hold ts.mu
+func (ts *timeshift) scanOggHeader() {
+	log := slog.With("method", "timeshift.scanOggHeader")
+	var hdr [27 + 255]byte
+	for !ts.oggDone {
+		if ts.oggScan > timeshiftMaxOggHeader || ts.written > ts.size {
+			log.Info("ogg header not found")
+			ts.oggDone = true
+			return
+		}
+		avail := ts.written - ts.oggScan
+		if avail < 27 {
+			return
+		}
+		if err := ts.readAt(hdr[:27], ts.oggScan); err != nil || !bytes.Equal(hdr[:4], oggCapturePattern) {
+			log.Info("invalid ogg page", "offset", ts.oggScan, "err", err)
+			ts.oggDone = true
+			return
+		}
+		granule := int64(binary.LittleEndian.Uint64(hdr[6:14]))
+		if granule != 0 {
+			ts.oggHeader = make([]byte, ts.oggScan)
+			if err := ts.readAt(ts.oggHeader, 0); err != nil {
+				ts.oggHeader = nil
+			}
+			log.Info("ogg header found", "size", len(ts.oggHeader))
+			ts.oggDone = true
+			return
+		}
+		nSegs := int64(hdr[26])
+		if avail < 27+nSegs {
+			return
+		}
+		if err := ts.readAt(hdr[27:27+nSegs], ts.oggScan+27); err != nil {
+			ts.oggDone = true
+			return
+		}
+		pageLen := 27 + nSegs
+		for _, l := range hdr[27 : 27+nSegs] {
+			pageLen += int64(l)
+		}
+		if avail < pageLen {
+			return
+		}
+		ts.oggScan += pageLen
+	}
+}
+
+func (ts *timeshift) addTitle(title string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.titles = append(ts.titles, tsTitle{offset: ts.written, title: title})
+}
+
+// addCheckpoint records the stream offset of a decoded timeline position,
+// at most once per second and only for positions past the last checkpoint.
+func (ts *timeshift) addCheckpoint(sample, offset int64) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if l := len(ts.checkpoints); l > 0 && sample < ts.checkpoints[l-1].sample+int64(ts.sampleRate) {
+		return
+	}
+	ts.checkpoints = append(ts.checkpoints, tsCheckpoint{sample: sample, offset: offset})
+
+	oldest := ts.oldestLocked()
+	i := 0
+	for i < len(ts.checkpoints)-1 && ts.checkpoints[i].offset < oldest {
+		i++
+	}
+	ts.checkpoints = ts.checkpoints[i:]
+	// keep the title that is playing at the oldest retained offset
+	j := 0
+	for j < len(ts.titles)-1 && ts.titles[j+1].offset <= oldest {
+		j++
+	}
+	ts.titles = ts.titles[j:]
+}
+
+// caller must hold ts.mu
+func (ts *timeshift) bytesPerSampleLocked() float64 {
+	if l := len(ts.checkpoints); l > 1 {
+		first, last := ts.checkpoints[0], ts.checkpoints[l-1]
+		if last.sample-first.sample >= timeshiftMinRateSpanSec*int64(ts.sampleRate) && last.offset > first.offset {
+			return float64(last.offset-first.offset) / float64(last.sample-first.sample)
+		}
+	}
+	if ts.sampleRate <= 0 {
+		return 1
+	}
+	return float64(ts.bytesPerSec) / float64(ts.sampleRate)
+}
+
+// bounds returns the oldest retained and the live timeline positions in samples.
+func (ts *timeshift) bounds() (oldest, live int64, ok bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if len(ts.checkpoints) == 0 {
+		return 0, 0, false
+	}
+	last := ts.checkpoints[len(ts.checkpoints)-1]
+	live = ts.sampleAtLocked(ts.written)
+	return ts.firstRetainedLocked().sample, max(live, last.sample), true
+}
+
+// sampleAtLocked estimates the timeline position of a stream offset.
+//
+// caller must hold ts.mu and there must be at least one checkpoint
+func (ts *timeshift) sampleAtLocked(offset int64) int64 {
+	cp := ts.checkpoints[0]
+	for _, c := range ts.checkpoints {
+		if c.offset > offset {
+			break
+		}
+		cp = c
+	}
+	return cp.sample + int64(float64(offset-cp.offset)/ts.bytesPerSampleLocked())
+}
+
+// titleStarts returns the timeline positions, in samples, where the retained titles start.
+func (ts *timeshift) titleStarts() []int64 {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if len(ts.checkpoints) == 0 {
+		return nil
+	}
+	res := make([]int64, len(ts.titles))
+	for i, t := range ts.titles {
+		res[i] = ts.sampleAtLocked(t.offset)
+	}
+	return res
+}
+
+// firstRetainedLocked returns the oldest checkpoint whose data was not yet
+// overwritten, estimating one past the last checkpoint if there is none.
+//
+// caller must hold ts.mu
+func (ts *timeshift) firstRetainedLocked() tsCheckpoint {
+	oldest := ts.oldestLocked()
+	for _, c := range ts.checkpoints {
+		if c.offset >= oldest {
+			return c
+		}
+	}
+	last := ts.checkpoints[len(ts.checkpoints)-1]
+	return tsCheckpoint{
+		sample: last.sample + int64(float64(oldest-last.offset)/ts.bytesPerSampleLocked()),
+		offset: oldest,
+	}
+}
+
+// seekPoint returns the checkpoint to start decoding from to reach sample.
+func (ts *timeshift) seekPoint(sample int64) (tsCheckpoint, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if len(ts.checkpoints) == 0 {
+		return tsCheckpoint{}, false
+	}
+	last := ts.checkpoints[len(ts.checkpoints)-1]
+	if sample > last.sample {
+		off := last.offset + int64(float64(sample-last.sample)*ts.bytesPerSampleLocked())
+		return tsCheckpoint{sample: sample, offset: min(off, ts.written)}, true
+	}
+	cp := ts.firstRetainedLocked()
+	for _, c := range ts.checkpoints {
+		if c.sample > sample {
+			break
+		}
+		if c.sample > cp.sample {
+			cp = c
+		}
+	}
+	return cp, true
+}
+
+// stats returns the bytes buffered ahead of the last read and the number of underruns.
+func (ts *timeshift) stats() (fill int64, underruns int) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return max(0, ts.written-ts.readOff), ts.underruns
+}
+
+// titleAt returns the stream title playing at the given timeline position.
+func (ts *timeshift) titleAt(sample int64) string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	off := ts.written
+	if l := len(ts.checkpoints); l > 0 {
+		last := ts.checkpoints[l-1]
+		if sample >= last.sample {
+			off = last.offset + int64(float64(sample-last.sample)*ts.bytesPerSampleLocked())
+		} else {
+			for _, c := range ts.checkpoints {
+				if c.sample > sample {
+					break
+				}
+				off = c.offset
+			}
+		}
+	}
+	var title string
+	for _, t := range ts.titles {
+		if t.offset > off {
+			break
+		}
+		title = t.title
+	}
+	return title
+}
+
+// newReader returns a reader of the stream bytes starting at offset.
+// For ogg streams the header pages are replayed first and reading resumes
+// at the next page boundary.
+func (ts *timeshift) newReader(offset int64) *tsReader {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	r := &tsReader{ts: ts, off: offset, buffering: ts.prebuffer > 0}
+	ts.readOff = offset
+	if ts.ogg && offset <= int64(len(ts.oggHeader)) {
+		r.off = 0
+	} else if ts.ogg && len(ts.oggHeader) > 0 {
+		r.prefix = ts.oggHeader
+		r.sync = true
+	}
+	return r
+}
+
+// tsReader blocks at the end of the written data until more bytes arrive.
+// It does not implement io.Seeker, so the decoders will not scan it.
+type tsReader struct {
+	ts        *timeshift
+	off       int64
+	prefix    []byte
+	sync      bool
+	closed    bool
+	overrun   bool
+	buffering bool
+	started   bool
+}
+
+func (r *tsReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(r.prefix) > 0 {
+		n := copy(p, r.prefix)
+		r.prefix = r.prefix[n:]
+		return n, nil
+	}
+
+	ts := r.ts
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for r.sync {
+		if err := r.waitLocked(int64(len(oggCapturePattern))); err != nil {
+			return 0, err
+		}
+		buf := make([]byte, min(networkReadSize, ts.written-r.off))
+		if err := ts.readAt(buf, r.off); err != nil {
+			return 0, err
+		}
+		if i := bytes.Index(buf, oggCapturePattern); i >= 0 {
+			r.off += int64(i)
+			r.sync = false
+			break
+		}
+		r.off += int64(len(buf) - len(oggCapturePattern) + 1)
+	}
+
+	if err := r.waitLocked(1); err != nil {
+		return 0, err
+	}
+	n := min(int64(len(p)), ts.written-r.off)
+	if err := ts.readAt(p[:n], r.off); err != nil {
+		return 0, err
+	}
+	r.off += n
+	r.started = true
+	ts.readOff = r.off
+	return int(n), nil
+}
+
+// caller must hold ts.mu
+func (r *tsReader) waitLocked(need int64) error {
+	ts := r.ts
+	counted := false
+	for {
+		avail := ts.written - r.off
+		switch {
+		case r.closed || ts.removed:
+			return io.EOF
+		case r.off < ts.oldestLocked():
+			r.overrun = true
+			return errTimeshiftOverrun
+		case avail >= need && (!r.buffering || avail >= ts.prebuffer || ts.eof):
+			r.buffering = false
+			return nil
+		case ts.eof:
+			return io.EOF
+		}
+		// reached the live edge while playing
+		if r.started && !r.buffering && !counted {
+			ts.underruns++
+			r.buffering = ts.prebuffer > 0
+			counted = true
+		}
+		ts.cond.Wait()
+	}
+}
+
+// offset returns the stream offset of the next byte to be read.
+func (r *tsReader) offset() int64 {
+	r.ts.mu.Lock()
+	defer r.ts.mu.Unlock()
+	return r.off
+}
+
+func (r *tsReader) isOverrun() bool {
+	r.ts.mu.Lock()
+	defer r.ts.mu.Unlock()
+	return r.overrun
+}
+
+func (r *tsReader) Close() error {
+	r.ts.mu.Lock()
+	r.closed = true
+	r.ts.cond.Broadcast()
+	r.ts.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestTimeshift(t *testing.T, size int64, contentType string) *timeshift {
+	t.Helper()
+	ts, err := newTimeshift(1, 8, contentType, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.size = size
+	ts.setSampleRate(10)
+	t.Cleanup(func() { _ = ts.remove() })
+	return ts
+}
+
+func Test_timeshift_ring(t *testing.T) {
+	ts := newTestTimeshift(t, 8, contentTypeMpeg)
+
+	r := ts.newReader(0)
+	_, _ = ts.Write([]byte("abcdef"))
+	buf := make([]byte, 4)
+	n, err := r.Read(buf)
+	if err != nil || string(buf[:n]) != "abcd" {
+		t.Fatalf("read %q, err %v", buf[:n], err)
+	}
+
+	// wraps around, "ab" is overwritten
+	_, _ = ts.Write([]byte("ghij"))
+	if fi, _ := ts.f.Stat(); fi.Size() != 8 {
+		t.Errorf("file size %d, want 8", fi.Size())
+	}
+	got, err := io.ReadAll(io.LimitReader(r, 6))
+	if err != nil || string(got) != "efghij" {
+		t.Fatalf("read %q, err %v", got, err)
+	}
+
+	_, err = ts.newReader(0).Read(buf)
+	if !errors.Is(err, errTimeshiftOverrun) {
+		t.Errorf("expected overrun error, got %v", err)
+	}
+}
+
+func Test_timeshift_readerBlocks(t *testing.T) {
+	ts := newTestTimeshift(t, 64, contentTypeMpeg)
+	r := ts.newReader(0)
+
+	res := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		res <- string(b)
+	}()
+	_, _ = ts.Write([]byte("live"))
+	time.Sleep(10 * time.Millisecond)
+	_ = ts.Close()
+
+	select {
+	case got := <-res:
+		if got != "live" {
+			t.Errorf("read %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reader did not unblock on close")
+	}
+
+	name := ts.f.Name()
+	if err := ts.remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("timeshift file not removed: %v", err)
+	}
+}
+
+func oggPage(granule int64, payload []byte) []byte {
+	p := make([]byte, 27, 28+len(payload))
+	copy(p, oggCapturePattern)
+	binary.LittleEndian.PutUint64(p[6:14], uint64(granule))
+	p[26] = 1
+	p = append(p, byte(len(payload)))
+	return append(p, payload...)
+}
+
+func Test_timeshift_oggHeader(t *testing.T) {
+	ts := newTestTimeshift(t, 1024, contentTypeOgg)
+
+	header := append(oggPage(0, []byte("id")), oggPage(0, []byte("setup"))...)
+	audio1 := oggPage(100, []byte("audio1"))
+	audio2 := oggPage(200, []byte("audio2"))
+	stream := bytes.Join([][]byte{header, audio1, audio2}, nil)
+	// written in small chunks, as received from the network
+	for i := 0; i < len(stream); i += 5 {
+		_, _ = ts.Write(stream[i:min(len(stream), i+5)])
+	}
+	_ = ts.Close()
+
+	if !bytes.Equal(ts.oggHeader, header) {
+		t.Fatalf("header %q, want %q", ts.oggHeader, header)
+	}
+
+	// starting in the middle of a page resumes at the next one
+	got, err := io.ReadAll(ts.newReader(int64(len(header) + 3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append(append([]byte{}, header...), audio2...); !bytes.Equal(got, want) {
+		t.Errorf("read %q, want %q", got, want)
+	}
+}
+
+func Test_timeshift_checkpoints(t *testing.T) {
+	ts := newTestTimeshift(t, 1000, contentTypeMpeg)
+	_, _ = ts.Write(make([]byte, 200))
+	ts.addTitle("first")
+	_, _ = ts.Write(make([]byte, 200))
+	ts.addTitle("second")
+
+	// 10 samples per second, 10 bytes per second
+	for s := int64(0); s <= 300; s += 5 {
+		ts.addCheckpoint(s, s)
+	}
+	if len(ts.checkpoints) != 31 {
+		t.Fatalf("%d checkpoints, want one per second", len(ts.checkpoints))
+	}
+
+	cp, _ := ts.seekPoint(155)
+	if cp.sample != 150 || cp.offset != 150 {
+		t.Errorf("seekPoint(155) = %+v", cp)
+	}
+	cp, _ = ts.seekPoint(350)
+	if cp.sample != 350 || cp.offset != 350 {
+		t.Errorf("seekPoint(350) = %+v", cp)
+	}
+
+	oldest, live, ok := ts.bounds()
+	if !ok || oldest != 0 || live != 400 {
+		t.Errorf("bounds = %d, %d, %v", oldest, live, ok)
+	}
+
+	for sample, want := range map[int64]string{100: "", 250: "first", 400: "second"} {
+		if got := ts.titleAt(sample); got != want {
+			t.Errorf("titleAt(%d) = %q, want %q", sample, got, want)
+		}
+	}
+
+	if got := ts.titleStarts(); !slices.Equal(got, []int64{200, 400}) {
+		t.Errorf("titleStarts() = %v", got)
+	}
+
+	// overwrite the first 300 bytes
+	_, _ = ts.Write(make([]byte, 900))
+	ts.addCheckpoint(1300, 1300)
+	oldest, _, _ = ts.bounds()
+	if oldest != 300 {
+		t.Errorf("oldest %d after overwrite, want 300", oldest)
+	}
+	if got := ts.titleAt(1300); got != "second" {
+		t.Errorf("titleAt(1300) = %q", got)
+	}
+}
+
+func Test_timeshift_prebuffer(t *testing.T) {
+	ts := newTestTimeshift(t, 64, contentTypeMpeg)
+	ts.prebuffer = 4
+	r := ts.newReader(0)
+
+	res := readAsync(r, 2)
+	_, _ = ts.Write([]byte("ab"))
+	select {
+	case b := <-res:
+		t.Fatalf("read %q before the pre-roll was written", b)
+	case <-time.After(20 * time.Millisecond):
+	}
+	_, _ = ts.Write([]byte("cd"))
+	if b := <-res; string(b) != "ab" {
+		t.Fatalf("read %q", b)
+	}
+	if b := <-readAsync(r, 2); string(b) != "cd" {
+		t.Fatalf("read %q", b)
+	}
+
+	// live edge reached
+	res = readAsync(r, 1)
+	time.Sleep(10 * time.Millisecond)
+	_, _ = ts.Write([]byte("efgh"))
+	if b := <-res; string(b) != "e" {
+		t.Errorf("read %q", b)
+	}
+	if fill, underruns := ts.stats(); fill != 3 || underruns != 1 {
+		t.Errorf("stats = %d, %d", fill, underruns)
+	}
+}
@@ -1,7 +1,48 @@
 package model
 
+import "time"
+
 type Metadata struct {
 	Title           string
 	PlaybackTimeSec *int64
+	Buffered        *BufferedRange
+	Network         *NetworkBuffer
 	Err             error
+	Event           *Event
+}
+
+// BufferedRange is the seekable part of the stream timeline, in seconds.
+type BufferedRange struct {
+	StartSec int64
+	LiveSec  int64
+}
+
+// NetworkBuffer is the compressed stream data read ahead of the decoder.
+type NetworkBuffer struct {
+	Fill      time.Duration // estimated playback duration of the buffered data
+	Prebuffer time.Duration
+	Underruns int
+}
+
+// SeekTarget is a buffered stream position derived from the song boundaries.
+type SeekTarget uint8
+
+const (
+	SeekSongStart SeekTarget = iota
+	SeekPrevSong
+	SeekNextSong
+	SeekLive
+)
+
+type EventType uint8
+
+const (
+	SilenceEvent EventType = iota + 1
+	StallEvent
+)
+
+// Event is reported once for each detected playback anomaly.
+type Event struct {
+	Type     EventType
+	Duration time.Duration
 }
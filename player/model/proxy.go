@@ -0,0 +1,6 @@
+package model
+
+import "net/url"
+
+// ProxyFunc returns the proxy of a stream connection, nil for a direct one.
+type ProxyFunc func(streamURL string) (*url.URL, error)
@@ -280,6 +280,10 @@ func (m *Mpd) Seek(amtSec int) *model.Metadata {
 	return m.Metadata()
 }
 
+func (m *Mpd) SeekTo(target model.SeekTarget) *model.Metadata {
+	return nil
+}
+
 func (m *Mpd) Stop() error {
 	_, err := m.doCmd(cmds[stop])
 	if err != nil {
@@ -172,6 +172,10 @@ func (m *Mplayer) Seek(amtSec int) *model.Metadata {
 	return nil
 }
 
+func (m *Mplayer) SeekTo(target model.SeekTarget) *model.Metadata {
+	return nil
+}
+
 var errPlay = errors.New("MPlayer command error")
 
 func (m *Mplayer) Play(url string) error {
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"math/rand/v2"
 	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"slices"
@@ -36,6 +37,7 @@ type ipcCmd uint8
 
 const (
 	play ipcCmd = iota
+	httpProxy
 	stop
 	pause
 	unpause
@@ -49,6 +51,7 @@ const (
 
 var ipcCmds = map[ipcCmd]string{
 	play:         `["loadfile", "%s","replace"]`,
+	httpProxy:    `["set_property", "http-proxy", "%s"]`,
 	stop:         `[ "stop"]`,
 	pause:        `["set_property", "pause", true]`,
 	unpause:      `["set_property", "pause", false]`,
@@ -63,13 +66,15 @@ var ipcCmds = map[ipcCmd]string{
 type MpvSocket struct {
 	sockFile string
 	conn     net.Conn
+	proxy    model.ProxyFunc
 
 	cmd *exec.Cmd
 }
 
-func NewMPVSocket(ctx context.Context) (*MpvSocket, error) {
+func NewMPVSocket(ctx context.Context, proxy model.ProxyFunc) (*MpvSocket, error) {
 	mpv := &MpvSocket{
 		sockFile: fmt.Sprintf(sockFile, os.Getpid()),
+		proxy:    proxy,
 	}
 
 	cmd, err := mpvCmd(ctx, mpv.sockFile)
@@ -125,6 +130,19 @@ func mpvCmd(ctx context.Context, sockFile string) (*exec.Cmd, error) {
 	return cmd, nil
 }
 
+// proxyValue returns the mpv http-proxy option value, empty for a direct
+// connection; mpv does not support SOCKS proxies.
+func proxyValue(proxy *url.URL) string {
+	if proxy == nil {
+		return ""
+	}
+	if proxy.Scheme != "http" {
+		slog.Warn("mpv supports only HTTP proxies", "proxy", proxy.Redacted())
+		return ""
+	}
+	return proxy.String()
+}
+
 func (mpv *MpvSocket) Pause(value bool) error {
 	log := slog.With("method", "MpvSocket.Pause")
 	log.Info("pause", "value", value)
@@ -148,6 +166,17 @@ func (mpv *MpvSocket) Play(url string) error {
 		return errPlay
 	}
 
+	// the proxy is set for each stream, it may differ with NO_PROXY
+	proxy, err := mpv.proxy(url)
+	if err != nil {
+		log.Error("stream proxy", "error", err)
+		return err
+	}
+	_, err = mpv.ipcRequest(fmt.Sprintf(ipcCmds[httpProxy], proxyValue(proxy)))
+	if err != nil {
+		return errPlay
+	}
+
 	playCmd := fmt.Sprintf(ipcCmds[play], url)
 	_, err = mpv.ipcRequest(playCmd)
 	if err != nil {
@@ -185,6 +214,10 @@ func (mpv *MpvSocket) Seek(amtSec int) *model.Metadata {
 	return mpv.Metadata()
 }
 
+func (mpv *MpvSocket) SeekTo(target model.SeekTarget) *model.Metadata {
+	return nil
+}
+
 type icyMetadata struct {
 	Notice1     string `json:"icy-notice1"`
 	Notice2     string `json:"icy-notice2"`
@@ -7,7 +7,7 @@ import (
 
 func TestMpvSocket_Play(t *testing.T) {
 	ctx := context.Background()
-	p, err := NewMPVSocket(ctx)
+	p, err := NewMPVSocket(ctx, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os/exec"
 
 	"github.com/dancnb/sonicradio/config"
@@ -40,6 +42,12 @@ type backendPlayer interface {
 	//   - returns the metadata for the new playback position if succeeded, metadata with error if failed
 	Seek(amtSec int) *model.Metadata
 
+	// SeekTo:
+	//
+	//   - seek to a song boundary or to the live edge of the buffered stream,
+	//   - returns the metadata for the new playback position if succeeded, nil if not supported
+	SeekTo(target model.SeekTarget) *model.Metadata
+
 	Close() error
 }
 
@@ -51,23 +59,24 @@ func NewPlayer(ctx context.Context, cfg *config.Value) (*Player, error) {
 	}
 
 	vol := cfg.GetVolume()
+	proxy := streamProxy(cfg)
 	switch cfg.Player {
 	case config.Internal:
-		p.delegate = internal.New(ctx, clampVolume(vol), cfg.Internal)
+		p.delegate = internal.New(ctx, clampVolume(vol), cfg.Internal, cfg.NewHTTPClient())
 	case config.Mpv:
-		mpvPlayer, err := mpv.NewMPVSocket(ctx)
+		mpvPlayer, err := mpv.NewMPVSocket(ctx, proxy)
 		if err != nil {
 			return nil, err
 		}
 		p.delegate = mpvPlayer
 	case config.FFPlay:
-		ffplayPlayer, err := ffplay.NewFFPlay(ctx)
+		ffplayPlayer, err := ffplay.NewFFPlay(ctx, proxy)
 		if err != nil {
 			return nil, err
 		}
 		p.delegate = ffplayPlayer
 	case config.Vlc:
-		vlcPlayer, err := vlc.NewVlc(ctx)
+		vlcPlayer, err := vlc.NewVlc(ctx, proxy)
 		if err != nil {
 			return nil, err
 		}
@@ -94,6 +103,18 @@ func NewPlayer(ctx context.Context, cfg *config.Value) (*Player, error) {
 	return p, nil
 }
 
+// streamProxy resolves the proxy of each stream of the external players
+// with the same rules as the HTTP clients.
+func streamProxy(cfg *config.Value) model.ProxyFunc {
+	return func(streamURL string) (*url.URL, error) {
+		u, err := url.Parse(streamURL)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.ProxyFor(u)
+	}
+}
+
 var errNoPlayerAvailable = errors.New("No available player found. Must have at least one of the following in PATH: mpv, ffplay, vlc.")
 
 func (p *Player) checkAvailablePlayers(cfg *config.Value) error {
@@ -196,6 +217,20 @@ func (p *Player) Seek(amtSec int) *model.Metadata {
 	return p.delegate.Seek(amtSec)
 }
 
+// SeekTo:
+//
+//   - seek to a song boundary or to the live edge of the buffered stream,
+//   - returns the metadata for the new playback position if succeeded, nil if not supported
+func (p *Player) SeekTo(target model.SeekTarget) *model.Metadata {
+	return p.delegate.SeekTo(target)
+}
+
 func (p *Player) Close() error {
 	return p.delegate.Close()
 }
+
+// StreamTitle returns the current song title of a stream, independently of
+// the backend player, by briefly reading its icy metadata.
+func StreamTitle(ctx context.Context, client *http.Client, url string) (string, error) {
+	return internal.StreamTitle(ctx, client, url)
+}
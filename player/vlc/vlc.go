@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"slices"
@@ -31,6 +32,8 @@ var (
 type Vlc struct {
 	conn net.Conn
 	cmd  *exec.Cmd
+
+	proxy model.ProxyFunc
 }
 
 type vlcRcCmd uint8
@@ -62,8 +65,8 @@ var cmds = map[vlcRcCmd]string{
 	shutdown: "shutdown\n",
 }
 
-func NewVlc(ctx context.Context) (*Vlc, error) {
-	p := &Vlc{}
+func NewVlc(ctx context.Context, proxy model.ProxyFunc) (*Vlc, error) {
+	p := &Vlc{proxy: proxy}
 
 	port, err := p.getAvailablePort()
 	if err != nil {
@@ -113,6 +116,38 @@ func (v *Vlc) getAvailablePort() (string, error) {
 	return p, nil
 }
 
+// proxyOptions returns the VLC input item options for an HTTP or SOCKS5
+// proxy.
+func proxyOptions(proxy *url.URL) []string {
+	if proxy == nil {
+		return nil
+	}
+	var args []string
+	pwd, hasPwd := proxy.User.Password()
+	switch proxy.Scheme {
+	case "http":
+		u := *proxy
+		if u.User != nil {
+			u.User = url.User(u.User.Username())
+		}
+		args = append(args, ":http-proxy="+u.String())
+		if hasPwd {
+			args = append(args, ":http-proxy-pwd="+pwd)
+		}
+	case "socks5", "socks5h":
+		args = append(args, ":socks="+proxy.Host)
+		if proxy.User != nil {
+			args = append(args, ":socks-user="+proxy.User.Username())
+		}
+		if hasPwd {
+			args = append(args, ":socks-pwd="+pwd)
+		}
+	default:
+		slog.Warn("VLC supports only HTTP and SOCKS5 proxies", "proxy", proxy.Redacted())
+	}
+	return args
+}
+
 func (v *Vlc) vlcCmd(ctx context.Context, addr string) (*exec.Cmd, error) {
 	log := slog.With("method", "vlcCmd")
 	args := slices.Clone(baseArgs)
@@ -142,8 +177,15 @@ func getConn(ctx context.Context, addr string) (net.Conn, error) {
 var errPlay = errors.New("VLC request error")
 
 func (v *Vlc) Play(url string) error {
-	cmd := fmt.Sprintf(cmds[add], url)
-	_, err := v.doRequest(cmd)
+	// the proxy is set for each stream, it may differ with NO_PROXY
+	proxy, err := v.proxy(url)
+	if err != nil {
+		slog.Error("stream proxy", "method", "Vlc.Play", "error", err)
+		return err
+	}
+	mrl := strings.Join(append([]string{url}, proxyOptions(proxy)...), " ")
+	cmd := fmt.Sprintf(cmds[add], mrl)
+	_, err = v.doRequest(cmd)
 	if err != nil {
 		return errPlay
 	}
@@ -226,6 +268,10 @@ func (v *Vlc) Seek(amtSec int) *model.Metadata {
 	return v.Metadata()
 }
 
+func (v *Vlc) SeekTo(target model.SeekTarget) *model.Metadata {
+	return nil
+}
+
 func (v *Vlc) Close() (err error) {
 	log := slog.With("method", "Vlc.Close")
 	log.Info("stopping")
@@ -7,7 +7,7 @@ import (
 
 func TestVlc(t *testing.T) {
 	ctx := context.Background()
-	p, err := NewVlc(ctx)
+	p, err := NewVlc(ctx, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,252 @@
+// Package podcast fetches RSS and Atom podcast feeds and maps their episodes
+// to playable stations.
+package podcast
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dancnb/sonicradio/browser"
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/model"
+)
+
+// IDPrefix is the station ID prefix of the episodes.
+const IDPrefix = config.PodcastIDPrefix
+
+var errNoEpisodes = errors.New("not a podcast feed")
+
+// rss date layouts seen in the wild, RFC 1123 being the standard one
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+}
+
+type Feed struct {
+	URL      string
+	Title    string
+	Episodes []Episode
+}
+
+type Episode struct {
+	// ID is derived from the episode guid, or from its audio URL
+	ID        string
+	Title     string
+	FeedURL   string
+	FeedTitle string
+	Published time.Time
+	// Duration is zero when not published by the feed
+	Duration time.Duration
+	URL      string
+	Link     string
+}
+
+func (e Episode) FilterValue() string { return e.Title + " " + e.FeedTitle }
+
+// Station returns the episode as a playable station.
+func (e Episode) Station() model.Station {
+	return model.Station{
+		Stationuuid: browser.StationID(IDPrefix, e.ID),
+		Name:        e.Title,
+		URL:         e.URL,
+		Homepage:    e.Link,
+		Tags:        e.FeedTitle,
+	}
+}
+
+// EpisodeID returns the ID of an episode station, false for other stations.
+func EpisodeID(stationID string) (string, bool) {
+	if browser.IDPrefix(stationID) != IDPrefix {
+		return "", false
+	}
+	return strings.TrimPrefix(stationID, IDPrefix+":"), true
+}
+
+// Fetch downloads and parses the feed at url.
+func Fetch(ctx context.Context, client *http.Client, url string) (*Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("feed request: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed status: %s", res.Status)
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(url, b)
+}
+
+type rss struct {
+	Title string    `xml:"channel>title"`
+	Items []rssItem `xml:"channel>item"`
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	GUID      string `xml:"guid"`
+	Link      string `xml:"link"`
+	PubDate   string `xml:"pubDate"`
+	Duration  string `xml:"duration"`
+	Enclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+type atom struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Duration  string `xml:"duration"`
+	Links     []struct {
+		Rel  string `xml:"rel,attr"`
+		Href string `xml:"href,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"link"`
+}
+
+// Parse parses an RSS 2.0 or an Atom feed; the episodes without audio are
+// skipped, the others are sorted newest first.
+func Parse(url string, b []byte) (*Feed, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	f := &Feed{URL: url}
+	switch root.XMLName.Local {
+	case "rss":
+		var doc rss
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("invalid feed: %w", err)
+		}
+		f.Title = strings.TrimSpace(doc.Title)
+		for _, it := range doc.Items {
+			if it.Enclosure.URL == "" || !isAudio(it.Enclosure.Type) {
+				continue
+			}
+			f.Episodes = append(f.Episodes, Episode{
+				ID:        episodeID(url, cmp.Or(strings.TrimSpace(it.GUID), it.Enclosure.URL)),
+				Title:     strings.TrimSpace(it.Title),
+				Published: parseDate(it.PubDate),
+				Duration:  parseDuration(it.Duration),
+				URL:       strings.TrimSpace(it.Enclosure.URL),
+				Link:      strings.TrimSpace(it.Link),
+			})
+		}
+	case "feed":
+		var doc atom
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("invalid feed: %w", err)
+		}
+		f.Title = strings.TrimSpace(doc.Title)
+		for _, en := range doc.Entries {
+			e := Episode{
+				Title:     strings.TrimSpace(en.Title),
+				Published: parseDate(cmp.Or(en.Published, en.Updated)),
+				Duration:  parseDuration(en.Duration),
+			}
+			for _, l := range en.Links {
+				switch {
+				case l.Rel == "enclosure" && isAudio(l.Type):
+					e.URL = strings.TrimSpace(l.Href)
+				case l.Rel == "" || l.Rel == "alternate":
+					e.Link = strings.TrimSpace(l.Href)
+				}
+			}
+			if e.URL == "" {
+				continue
+			}
+			e.ID = episodeID(url, cmp.Or(strings.TrimSpace(en.ID), e.URL))
+			f.Episodes = append(f.Episodes, e)
+		}
+	default:
+		return nil, fmt.Errorf("%w: <%s>", errNoEpisodes, root.XMLName.Local)
+	}
+
+	if f.Title == "" {
+		f.Title = url
+	}
+	for i := range f.Episodes {
+		f.Episodes[i].FeedURL = url
+		f.Episodes[i].FeedTitle = f.Title
+		if f.Episodes[i].Title == "" {
+			f.Episodes[i].Title = f.Episodes[i].URL
+		}
+	}
+	SortEpisodes(f.Episodes)
+	return f, nil
+}
+
+// SortEpisodes sorts the episodes newest first.
+func SortEpisodes(episodes []Episode) {
+	slices.SortStableFunc(episodes, func(a, b Episode) int {
+		return b.Published.Compare(a.Published)
+	})
+}
+
+// isAudio reports whether the enclosure type is audio; untyped enclosures are
+// assumed to be.
+func isAudio(mimeType string) bool {
+	mimeType = strings.TrimSpace(mimeType)
+	return mimeType == "" || strings.HasPrefix(mimeType, "audio/")
+}
+
+// episodeID is unique across feeds, guids being only unique within a feed.
+func episodeID(feedURL string, guid string) string {
+	sum := sha1.Sum([]byte(feedURL + "\n" + guid))
+	return hex.EncodeToString(sum[:8])
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseDuration parses the iTunes durations: seconds, MM:SS or HH:MM:SS.
+func parseDuration(s string) time.Duration {
+	var d time.Duration
+	for _, part := range strings.Split(strings.TrimSpace(s), ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		d = d*60 + time.Duration(n*float64(time.Second))
+	}
+	return d
+}
@@ -0,0 +1,116 @@
+package podcast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const rssFeed = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+	<title>Morning Show</title>
+	<item>
+		<title>Episode 1</title>
+		<guid>ep-1</guid>
+		<pubDate>Mon, 02 Sep 2024 06:00:00 +0000</pubDate>
+		<itunes:duration>1:02:03</itunes:duration>
+		<enclosure url="http://radio.local/ep1.mp3" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>Episode 2</title>
+		<guid>ep-2</guid>
+		<pubDate>Tue, 3 Sep 2024 06:00:00 GMT</pubDate>
+		<itunes:duration>2700</itunes:duration>
+		<enclosure url="http://radio.local/ep2.mp3" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>Video</title>
+		<enclosure url="http://radio.local/ep.mp4" type="video/mp4"/>
+	</item>
+	<item><title>No audio</title></item>
+</channel></rss>`
+
+const atomFeed = `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Evening Talk</title>
+	<entry>
+		<id>urn:talk:1</id>
+		<title>Talk 1</title>
+		<published>2024-09-01T18:00:00Z</published>
+		<link href="http://radio.local/talk1"/>
+		<link rel="enclosure" href="http://radio.local/talk1.ogg" type="audio/ogg"/>
+	</entry>
+	<entry>
+		<title>Article</title>
+		<link href="http://radio.local/article"/>
+	</entry>
+</feed>`
+
+func TestParse(t *testing.T) {
+	f, err := Parse("http://radio.local/rss", []byte(rssFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title != "Morning Show" || len(f.Episodes) != 2 {
+		t.Fatalf("rss feed = %+v", f)
+	}
+	// newest first
+	e := f.Episodes[1]
+	if e.Title != "Episode 1" || e.Duration != time.Hour+2*time.Minute+3*time.Second || e.FeedTitle != "Morning Show" ||
+		!e.Published.Equal(time.Date(2024, 9, 2, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("rss episode = %+v", e)
+	}
+	if f.Episodes[0].Duration != 45*time.Minute {
+		t.Errorf("seconds duration = %v", f.Episodes[0].Duration)
+	}
+
+	s := e.Station()
+	if id, ok := EpisodeID(s.Stationuuid); !ok || id != e.ID || s.URL != "http://radio.local/ep1.mp3" {
+		t.Errorf("episode station = %+v", s)
+	}
+	if _, ok := EpisodeID("96062a7b-0601-11e8-ae97-52543be04c81"); ok {
+		t.Error("radio-browser station is an episode")
+	}
+
+	// the same guid in another feed is another episode
+	other, err := Parse("http://other.local/rss", []byte(rssFeed))
+	if err != nil || other.Episodes[1].ID == e.ID {
+		t.Errorf("episode IDs not unique across feeds: %v", err)
+	}
+
+	f, err = Parse("http://radio.local/atom", []byte(atomFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title != "Evening Talk" || len(f.Episodes) != 1 {
+		t.Fatalf("atom feed = %+v", f)
+	}
+	if e := f.Episodes[0]; e.URL != "http://radio.local/talk1.ogg" || e.Link != "http://radio.local/talk1" || e.Published.IsZero() {
+		t.Errorf("atom episode = %+v", e)
+	}
+
+	if _, err := Parse("http://radio.local/opml", []byte(`<opml><body/></opml>`)); err == nil {
+		t.Error("OPML parsed as a feed")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(rssFeed))
+	}))
+	defer srv.Close()
+
+	f, err := Fetch(context.Background(), srv.Client(), srv.URL+"/feed.xml")
+	if err != nil || len(f.Episodes) != 2 || f.URL != srv.URL+"/feed.xml" {
+		t.Errorf("Fetch = %+v, %v", f, err)
+	}
+	if _, err := Fetch(context.Background(), srv.Client(), srv.URL+"/missing.xml"); err == nil {
+		t.Error("missing feed error = nil")
+	}
+}
@@ -0,0 +1,570 @@
+package ui
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dancnb/sonicradio/browser"
+	smodel "github.com/dancnb/sonicradio/model"
+)
+
+const (
+	categoriesTitle             = "Categories"
+	categoriesFilterPrompt      = "Filter:       "
+	categoriesLoadingMsg        = "\n  Fetching categories... \n"
+	noCategoryEntriesMsg        = "\n  No entries found. \n"
+	opmlStationsItem            = "Stations"
+	categoriesFilterPlaceholder = "name"
+)
+
+type categoryKind uint8
+
+const (
+	categoryTags categoryKind = iota
+	categoryCountries
+	categoryStates
+	categoryLanguages
+	categoryCodecs
+	categoryDirectories
+)
+
+var categoryKinds = []categoryKind{categoryTags, categoryCountries, categoryStates, categoryLanguages, categoryCodecs}
+
+func (k categoryKind) String() string {
+	switch k {
+	case categoryTags:
+		return "Tags"
+	case categoryCountries:
+		return "Countries"
+	case categoryStates:
+		return "States"
+	case categoryLanguages:
+		return "Languages"
+	case categoryCodecs:
+		return "Codecs"
+	case categoryDirectories:
+		return "Directories"
+	}
+	return ""
+}
+
+// categoryItem is either a category at the first level, or an entry of a
+// category with the search params of its stations. The items of an OPML
+// directory open its menus instead, or hold the stations of a menu.
+type categoryItem struct {
+	kind   categoryKind
+	name   string
+	count  int
+	params *browser.SearchParams
+
+	opml *browser.OPMLDirectory
+	// link is the opened sub-menu, nil for the root menu
+	link     *browser.OPMLLink
+	stations []smodel.Station
+}
+
+func (i categoryItem) FilterValue() string { return i.name }
+
+// categoryModel lets the user browse the stations by tag, country, state,
+// language or codec, and the configured directories. Choosing an entry runs a
+// search for its stations.
+type categoryModel struct {
+	enabled bool
+
+	ctx context.Context
+	// cancelLoad aborts the in-flight entries or search request
+	cancelLoad context.CancelFunc
+
+	browser   browser.Lists
+	directory browser.StationDirectory
+	providers []browser.Provider
+	style     *Style
+
+	// kind is the opened category, nil at the first level
+	kind *categoryKind
+	// menus are the levels left to open the current OPML directory menu
+	menus   []categoryLevel
+	viewMsg string
+
+	list   list.Model
+	keymap categoryKeymap
+}
+
+// categoryLevel is a list level restored when going back.
+type categoryLevel struct {
+	title string
+	items []list.Item
+	index int
+}
+
+type categoryKeymap struct {
+	open key.Binding
+	back key.Binding
+}
+
+func newCategoryModel(ctx context.Context, browser browser.Lists, directory browser.StationDirectory, providers []browser.Provider, s *Style) *categoryModel {
+	c := &categoryModel{
+		ctx:        ctx,
+		cancelLoad: func() {},
+		browser:    browser,
+		directory:  directory,
+		providers:  providers,
+		style:      s,
+		keymap: categoryKeymap{
+			open: key.NewBinding(
+				key.WithKeys("enter", "l"),
+				key.WithHelp("enter/l", "open"),
+			),
+			back: key.NewBinding(
+				key.WithKeys("esc", "h"),
+				key.WithHelp("esc/h", "back"),
+			),
+		},
+	}
+	c.createList()
+	return c
+}
+
+func (c *categoryModel) createList() {
+	delegate := &categoryDelegate{keymap: &c.keymap, style: c.style}
+	l := list.New([]list.Item{}, delegate, 0, 0)
+	l.InfiniteScrolling = true
+	l.SetShowTitle(true)
+	l.Title = categoriesTitle
+	l.Styles.Title = c.style.PrimaryColorStyle
+	l.SetShowStatusBar(false)
+	l.SetShowPagination(false)
+	l.SetShowFilter(true)
+	l.SetStatusBarItemName("entry", "entries")
+	l.Styles.NoItems = c.style.NoItemsStyle
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.PrevPage.SetKeys("pgup", "ctrl+b")
+	l.KeyMap.PrevPage.SetHelp("ctrl+b/pgup", "prev page")
+	l.KeyMap.NextPage.SetKeys("pgdown", "ctrl+f")
+	l.KeyMap.NextPage.SetHelp("ctrl+f/pgdn", "next page")
+
+	l.Help.ShortSeparator = "   "
+	l.Help.Styles = c.style.HelpStyles()
+	l.Styles.HelpStyle = c.style.HelpStyle
+
+	c.style.TextInputSyle(&l.FilterInput, categoriesFilterPrompt, categoriesFilterPlaceholder)
+	c.list = l
+}
+
+func (c *categoryModel) isEnabled() bool {
+	return c.enabled
+}
+
+func (c *categoryModel) isFiltering() bool {
+	return c.list.FilterState() == list.Filtering
+}
+
+func (c *categoryModel) setSize(width, height int) {
+	h, v := c.style.DocStyle.GetFrameSize()
+	c.list.SetSize(width-h, height-v)
+}
+
+// Init opens the model at the level it was last closed.
+func (c *categoryModel) Init() tea.Cmd {
+	c.enabled = true
+	if c.kind == nil || len(c.list.Items()) == 0 {
+		return c.showCategories()
+	}
+	return nil
+}
+
+func (c *categoryModel) showCategories() tea.Cmd {
+	c.kind = nil
+	c.menus = nil
+	c.viewMsg = ""
+	c.list.Title = categoriesTitle
+	c.list.ResetFilter()
+	items := make([]list.Item, 0, len(categoryKinds)+1)
+	for _, k := range categoryKinds {
+		items = append(items, categoryItem{kind: k, name: k.String()})
+	}
+	if len(c.providers) > 0 {
+		items = append(items, categoryItem{kind: categoryDirectories, name: categoryDirectories.String()})
+	}
+	cmd := c.list.SetItems(items)
+	c.list.Select(0)
+	return cmd
+}
+
+func (c *categoryModel) openCategory(kind categoryKind) tea.Cmd {
+	c.kind = &kind
+	c.menus = nil
+	c.viewMsg = categoriesLoadingMsg
+	c.list.Title = categoriesTitle + " › " + kind.String()
+	c.list.ResetFilter()
+	cmd := c.list.SetItems(nil)
+
+	c.cancelLoad()
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.cancelLoad = cancel
+	return tea.Batch(cmd, func() tea.Msg {
+		defer cancel()
+		entries, err := c.getEntries(ctx, kind)
+		return categoryEntriesMsg{kind: kind, entries: entries, err: err}
+	})
+}
+
+func (c *categoryModel) getEntries(ctx context.Context, kind categoryKind) ([]categoryItem, error) {
+	var res []categoryItem
+	add := func(name string, count int, params browser.SearchParams) {
+		if strings.TrimSpace(name) == "" || count <= 0 {
+			return
+		}
+		res = append(res, categoryItem{kind: kind, name: name, count: count, params: &params})
+	}
+
+	switch kind {
+	case categoryTags:
+		tags, err := c.browser.GetTags(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			p := browser.DefaultSearchParams()
+			p.TagList = t.Name
+			add(t.Name, t.Stationcount, p)
+		}
+	case categoryCountries:
+		countries, err := c.browser.GetCountries(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ct := range countries {
+			p := browser.DefaultSearchParams()
+			p.CountryCode = ct.ISO3166_1
+			add(ct.Name, ct.Stationcount, p)
+		}
+	case categoryStates:
+		states, err := c.browser.GetStates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range states {
+			p := browser.DefaultSearchParams()
+			p.State = st.Name
+			p.StateExact = true
+			p.Country = st.Country
+			name := st.Name
+			if st.Country != "" {
+				name = fmt.Sprintf("%s (%s)", st.Name, st.Country)
+			}
+			add(name, st.Stationcount, p)
+		}
+	case categoryLanguages:
+		langs, err := c.browser.GetLanguages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range langs {
+			p := browser.DefaultSearchParams()
+			p.Language = l.Name
+			p.LanguageExact = true
+			add(l.Name, l.Stationcount, p)
+		}
+	case categoryCodecs:
+		codecs, err := c.browser.GetCodecs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, cd := range codecs {
+			p := browser.DefaultSearchParams()
+			p.Codec = cd.Name
+			add(cd.Name, cd.Stationcount, p)
+		}
+	case categoryDirectories:
+		// listed in the configured order
+		for _, pr := range c.providers {
+			if o, ok := pr.(*browser.OPMLDirectory); ok {
+				// browsed one menu at a time
+				res = append(res, categoryItem{kind: kind, name: o.Name(), opml: o})
+				continue
+			}
+			stations, err := pr.Stations(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", pr.Name(), err)
+			}
+			p := browser.DefaultSearchParams()
+			p.Directory = pr.Prefix()
+			add(pr.Name(), len(stations), p)
+		}
+		return res, nil
+	}
+
+	slices.SortStableFunc(res, func(a, b categoryItem) int {
+		return cmp.Compare(b.count, a.count)
+	})
+	return res, nil
+}
+
+// searchCmd closes the model and searches the stations of the entry.
+func (c *categoryModel) searchCmd(entry categoryItem) tea.Cmd {
+	c.enabled = false
+	c.cancelLoad()
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.cancelLoad = cancel
+	return func() tea.Msg {
+		defer cancel()
+		stations, err := c.directory.Search(ctx, *entry.params)
+		if errors.Is(err, context.Canceled) {
+			return searchRespMsg{cancelled: true}
+		}
+		res := searchRespMsg{stations: stations}
+		if err != nil {
+			res.statusMsg = statusMsg(err.Error())
+		} else if len(stations) == 0 {
+			res.viewMsg = noStationsFound
+		} else {
+			res.params = entry.params
+			res.source = c.directory
+		}
+		return res
+	}
+}
+
+// openMenu opens the OPML directory menu of the item, keeping the current
+// level to go back to.
+func (c *categoryModel) openMenu(it categoryItem) tea.Cmd {
+	c.menus = append(c.menus, categoryLevel{title: c.list.Title, items: c.list.Items(), index: c.list.Index()})
+	c.viewMsg = categoriesLoadingMsg
+	c.list.Title += " › " + it.name
+	c.list.ResetFilter()
+	cmd := c.list.SetItems(nil)
+
+	c.cancelLoad()
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.cancelLoad = cancel
+	depth := len(c.menus)
+	return tea.Batch(cmd, func() tea.Msg {
+		defer cancel()
+		var menu *browser.OPMLMenu
+		var err error
+		if it.link == nil {
+			menu, err = it.opml.Root(ctx)
+		} else {
+			menu, err = it.opml.Open(ctx, *it.link)
+		}
+		return opmlMenuMsg{depth: depth, opml: it.opml, menu: menu, err: err}
+	})
+}
+
+// backMenu restores the level the current menu was opened from.
+func (c *categoryModel) backMenu() tea.Cmd {
+	c.cancelLoad()
+	prev := c.menus[len(c.menus)-1]
+	c.menus = c.menus[:len(c.menus)-1]
+	c.viewMsg = ""
+	c.list.Title = prev.title
+	c.list.ResetFilter()
+	cmd := c.list.SetItems(prev.items)
+	c.list.Select(prev.index)
+	return cmd
+}
+
+func (c *categoryModel) showMenu(msg opmlMenuMsg) tea.Cmd {
+	if len(msg.menu.Links) == 0 && len(msg.menu.Stations) > 0 {
+		// a menu of stations only is shown in the browse list right away,
+		// the categories stay at its parent
+		title := c.list.Title
+		c.backMenu()
+		return c.stationsCmd(categoryItem{name: title, stations: msg.menu.Stations})
+	}
+
+	c.viewMsg = ""
+	var items []list.Item
+	if len(msg.menu.Stations) > 0 {
+		items = append(items, categoryItem{
+			kind:     categoryDirectories,
+			name:     opmlStationsItem,
+			count:    len(msg.menu.Stations),
+			opml:     msg.opml,
+			stations: msg.menu.Stations,
+		})
+	}
+	for i := range msg.menu.Links {
+		items = append(items, categoryItem{kind: categoryDirectories, name: msg.menu.Links[i].Text, opml: msg.opml, link: &msg.menu.Links[i]})
+	}
+	if len(items) == 0 {
+		c.viewMsg = noCategoryEntriesMsg
+	}
+	cmd := c.list.SetItems(items)
+	c.list.Select(0)
+	return cmd
+}
+
+// stationsCmd closes the model and shows the stations of an OPML menu.
+func (c *categoryModel) stationsCmd(it categoryItem) tea.Cmd {
+	c.enabled = false
+	c.cancelLoad()
+	return func() tea.Msg {
+		return searchRespMsg{stations: it.stations, statusMsg: statusMsg(it.name)}
+	}
+}
+
+func (c *categoryModel) close() tea.Cmd {
+	c.enabled = false
+	c.cancelLoad()
+	return func() tea.Msg {
+		return searchRespMsg{cancelled: true}
+	}
+}
+
+func (c *categoryModel) Update(msg tea.Msg) (*categoryModel, tea.Cmd) {
+	logTeaMsg(msg, "ui.categoryModel.Update")
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.setSize(msg.Width, msg.Height)
+		return c, nil
+
+	case categoryEntriesMsg:
+		if c.kind == nil || *c.kind != msg.kind {
+			return c, nil
+		}
+		if msg.err != nil {
+			if errors.Is(msg.err, context.Canceled) {
+				return c, nil
+			}
+			c.viewMsg = noCategoryEntriesMsg
+			return c, nil
+		}
+		c.viewMsg = ""
+		if len(msg.entries) == 0 {
+			c.viewMsg = noCategoryEntriesMsg
+		}
+		items := make([]list.Item, len(msg.entries))
+		for i := range msg.entries {
+			items[i] = msg.entries[i]
+		}
+		cmd := c.list.SetItems(items)
+		c.list.Select(0)
+		return c, cmd
+
+	case opmlMenuMsg:
+		if msg.depth == 0 || msg.depth != len(c.menus) {
+			return c, nil
+		}
+		if msg.err != nil {
+			if errors.Is(msg.err, context.Canceled) {
+				return c, nil
+			}
+			c.viewMsg = noCategoryEntriesMsg
+			return c, nil
+		}
+		return c, c.showMenu(msg)
+
+	case tea.KeyMsg:
+		if c.isFiltering() {
+			break
+		}
+		switch {
+		case key.Matches(msg, c.keymap.back):
+			if c.list.FilterState() == list.FilterApplied {
+				break
+			}
+			if len(c.menus) > 0 {
+				return c, c.backMenu()
+			}
+			if c.kind != nil {
+				c.cancelLoad()
+				return c, c.showCategories()
+			}
+			return c, c.close()
+
+		case key.Matches(msg, c.keymap.open):
+			it, ok := c.list.SelectedItem().(categoryItem)
+			if !ok {
+				return c, nil
+			}
+			if it.stations != nil {
+				return c, c.stationsCmd(categoryItem{name: c.list.Title, stations: it.stations})
+			}
+			if it.opml != nil {
+				return c, c.openMenu(it)
+			}
+			if it.params == nil {
+				return c, c.openCategory(it.kind)
+			}
+			return c, c.searchCmd(it)
+		}
+	}
+
+	var cmd tea.Cmd
+	c.list, cmd = c.list.Update(msg)
+	return c, cmd
+}
+
+func (c *categoryModel) View() string {
+	if c.viewMsg != "" {
+		var sections []string
+		availHeight := c.list.Height()
+		title := c.list.Styles.TitleBar.Render(c.list.Styles.Title.Render(c.list.Title))
+		availHeight -= lipgloss.Height(title)
+		help := c.list.Styles.HelpStyle.Render(c.list.Help.View(c.list))
+		availHeight -= lipgloss.Height(help)
+		viewSection := c.style.ViewStyle.Height(availHeight).Render(c.viewMsg)
+		sections = append(sections, title, viewSection, help)
+		return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	}
+	return c.list.View()
+}
+
+type categoryDelegate struct {
+	keymap *categoryKeymap
+	style  *Style
+}
+
+func (d *categoryDelegate) ShortHelp() []key.Binding {
+	return []key.Binding{d.keymap.open, d.keymap.back}
+}
+
+func (d *categoryDelegate) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{d.keymap.open, d.keymap.back}}
+}
+
+func (d *categoryDelegate) Height() int { return 1 }
+
+func (d *categoryDelegate) Spacing() int { return 0 }
+
+func (d *categoryDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
+
+func (d *categoryDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	it, ok := item.(categoryItem)
+	if !ok {
+		return
+	}
+	itStyle := d.style.SecondaryColorStyle
+	if index == m.Index() {
+		itStyle = d.style.HistorySelItemStyle
+	}
+
+	prefixRender := d.style.PrefixStyle.Render("   ")
+	count := ""
+	if it.params != nil || it.stations != nil {
+		count = fmt.Sprintf(" %d", it.count)
+	}
+	maxWidth := max(m.Width()-lipgloss.Width(prefixRender)-HeaderPadDist, 0)
+	name := it.name
+	for lipgloss.Width(itStyle.Render(name+count)) > maxWidth && len(name) > 0 {
+		name = name[:len(name)-1]
+	}
+	hFill := max(maxWidth-lipgloss.Width(name)-lipgloss.Width(count), 0)
+
+	var res strings.Builder
+	res.WriteString(prefixRender)
+	res.WriteString(itStyle.Render(name + strings.Repeat(" ", hFill) + count))
+	_, _ = fmt.Fprint(w, res.String())
+}
@@ -1,13 +1,20 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dancnb/sonicradio/browser"
 	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/geo"
 	"github.com/dancnb/sonicradio/model"
+	playermodel "github.com/dancnb/sonicradio/player/model"
+	"github.com/dancnb/sonicradio/podcast"
 )
 
 func (m *Model) favoritesReqCmd() tea.Msg {
@@ -34,7 +41,7 @@ func (m *Model) favoritesReqCmd() tea.Msg {
 		return favoritesStationRespMsg{stations: favorites}
 	}
 
-	newStations, err := m.browser.GetStations(reqList)
+	newStations, err := m.directory.GetStations(m.ctx, reqList)
 	for i := range newStations {
 		found := false
 		for j := range favorites {
@@ -61,13 +68,121 @@ func (m *Model) favoritesReqCmd() tea.Msg {
 	return res
 }
 
+func (m *Model) nearbyStationsCmd(p geo.Point) tea.Cmd {
+	return func() tea.Msg {
+		stations, err := m.browser.NearbyStations(m.ctx, p, m.cfg.Location.GetRadiusKm())
+		res := searchRespMsg{stations: stations}
+		if err != nil {
+			res.statusMsg = statusMsg(err.Error())
+		} else if len(stations) == 0 {
+			res.viewMsg = noStationsFound
+		}
+		return res
+	}
+}
+
+// voteCmd votes the station, reporting the cooldown left if it was voted recently.
+func voteCmd(ctx context.Context, dir browser.StationDirectory, cfg *config.Value, uuid string) tea.Cmd {
+	return func() tea.Msg {
+		err := dir.StationVote(ctx, uuid)
+		if err != nil {
+			if wait := cfg.VoteCooldownLeft(uuid, time.Now()); wait > 0 {
+				return statusMsg(fmt.Sprintf(voteCooldown, wait.Round(time.Second)))
+			}
+			return statusMsg(err.Error())
+		}
+		return statusMsg(voteSuccesful)
+	}
+}
+
+// feedCmd loads the first page of the discovery feed.
+func (m *Model) feedCmd(f browser.Feed) tea.Cmd {
+	return func() tea.Msg {
+		stations, err := m.browser.FeedStations(m.ctx, f)
+		res := searchRespMsg{stations: stations, statusMsg: statusMsg(fmt.Sprintf(feedStatus, f))}
+		if errors.Is(err, browser.ErrOffline) {
+			res.statusMsg = feedsOffline
+			res.viewMsg = offlineViewMsg
+		} else if err != nil {
+			res.statusMsg = statusMsg(err.Error())
+		} else if len(stations) == 0 {
+			res.viewMsg = noStationsFound
+		} else if params, ok := f.SearchParams(); ok {
+			res.params = &params
+			res.source = m.browser
+		}
+		return res
+	}
+}
+
+// recommendedCmd ranks stations against the favorites and the stations
+// played in the history.
+func (m *Model) recommendedCmd() tea.Msg {
+	log := slog.With("method", "ui.Model.recommendedCmd")
+	listened := m.cfg.ListeningTime(time.Now())
+	var uuids []string
+	for uuid := range listened {
+		if !m.cfg.IsFavorite(uuid) {
+			uuids = append(uuids, uuid)
+		}
+	}
+	slices.Sort(uuids)
+	played, err := m.directory.GetStations(m.ctx, uuids)
+	if err != nil {
+		log.Error("played stations", "error", err)
+	}
+
+	profile := browser.NewProfile(m.cfg.GetFavorites(), played, listened)
+	stations, err := m.browser.Recommend(m.ctx, profile, recommendedLimit)
+	res := searchRespMsg{stations: stations, statusMsg: recommendedStatus}
+	if err != nil {
+		res.statusMsg = statusMsg(err.Error())
+	} else if len(stations) == 0 {
+		res.viewMsg = noStationsFound
+	}
+	return res
+}
+
+// publishCmd checks the custom station stream, publishes it to radio-browser
+// and fetches the published station.
+func (m *Model) publishCmd(s model.Station) tea.Cmd {
+	return func() tea.Msg {
+		res := publishRespMsg{customUUID: s.Stationuuid}
+		if err := m.browser.CheckStream(m.ctx, s.URL); err != nil {
+			res.err = err
+			return res
+		}
+		uuid, err := m.browser.AddStation(m.ctx, s)
+		if err != nil {
+			res.err = err
+			return res
+		}
+		published := s
+		published.Stationuuid = uuid
+		published.IsCustom = false
+		if stations, err := m.browser.GetStations(m.ctx, []string{uuid}); err == nil && len(stations) == 1 {
+			published = stations[0]
+		} else {
+			slog.Info("published station not fetched", "uuid", uuid, "error", err)
+		}
+		res.station = &published
+		return res
+	}
+}
+
 func (m *Model) topStationsCmd() tea.Msg {
-	stations, err := m.browser.TopStations()
+	stations, err := m.browser.TopStations(m.ctx)
 	res := topStationsRespMsg{stations: stations}
-	if err != nil {
+	if errors.Is(err, browser.ErrOffline) {
+		res.viewMsg = offlineViewMsg
+	} else if err != nil {
 		res.statusMsg = statusMsg(err.Error())
 	} else if len(stations) == 0 {
 		res.viewMsg = noStationsFound
+	} else {
+		params := browser.DefaultSearchParams()
+		res.params = &params
+		res.source = m.browser
 	}
 	return res
 }
@@ -89,8 +204,20 @@ func (m *Model) volumeCmd(up bool) tea.Cmd {
 }
 
 func (m *Model) seekCmd(amtSec int) tea.Cmd {
+	return m.seekWithCmd("ui.Model.seekCmd", func() *playermodel.Metadata {
+		return m.player.Seek(amtSec)
+	})
+}
+
+func (m *Model) seekToCmd(target playermodel.SeekTarget) tea.Cmd {
+	return m.seekWithCmd("ui.Model.seekToCmd", func() *playermodel.Metadata {
+		return m.player.SeekTo(target)
+	})
+}
+
+func (m *Model) seekWithCmd(method string, seekFn func() *playermodel.Metadata) tea.Cmd {
 	return func() tea.Msg {
-		log := slog.With("method", "ui.Model.seekCmd")
+		log := slog.With("method", method)
 		log.Info("begin")
 		defer log.Info("end")
 
@@ -105,7 +232,7 @@ func (m *Model) seekCmd(amtSec int) tea.Cmd {
 		} else {
 			return nil
 		}
-		metadata := m.player.Seek(amtSec)
+		metadata := seekFn()
 		if metadata == nil {
 			return nil
 		} else if metadata.Err != nil {
@@ -120,14 +247,70 @@ func (m *Model) seekCmd(amtSec int) tea.Cmd {
 func (m *Model) playStationCmd(selStation model.Station) tea.Cmd {
 	m.songTitle = ""
 	m.playbackTime = 0
+	m.buffered = nil
 	m.updateStatus(fmt.Sprintf("Connecting to %s...", selStation.Name))
 	cmds := []tea.Cmd{m.initSpinner(), m.delegate.playCmd(selStation)}
 	return tea.Batch(cmds...)
 }
 
+// playEpisodeCmd plays the episode from where it was left.
+func (m *Model) playEpisodeCmd(e podcast.Episode) tea.Cmd {
+	s := e.Station()
+	cmd := m.playStationCmd(s)
+	sec, ok := m.cfg.EpisodePosition(e.ID)
+	if !ok {
+		m.resumingEpisode = ""
+		return cmd
+	}
+	m.resumingEpisode = s.Stationuuid
+	return tea.Batch(cmd, m.resumeEpisodeCmd(s.Stationuuid, time.Duration(sec)*time.Second))
+}
+
+// resumeEpisodeCmd seeks the episode to the resume position, retrying while
+// the player is still opening it. The players seek relative to the current
+// position, which is close to the start.
+func (m *Model) resumeEpisodeCmd(uuid string, pos time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		log := slog.With("method", "ui.Model.resumeEpisodeCmd")
+		for i := 0; i < episodeResumeAttempts; i++ {
+			time.Sleep(episodeResumeDelay)
+			msg, playing := m.seekEpisode(uuid, pos)
+			if !playing {
+				return nil
+			}
+			if msg != nil {
+				return episodeResumedMsg{uuid: uuid, position: pos, metadata: msg}
+			}
+		}
+		log.Info("could not resume", "id", uuid, "position", pos)
+		return episodeResumedMsg{uuid: uuid, position: pos}
+	}
+}
+
+func (m *Model) seekEpisode(uuid string, pos time.Duration) (*metadataMsg, bool) {
+	// the seek is a blocking call to the player, don't hold the lock across it
+	m.delegate.playingMtx.RLock()
+	curr := m.delegate.currPlaying
+	m.delegate.playingMtx.RUnlock()
+	if curr == nil || curr.Stationuuid != uuid {
+		return nil, false
+	}
+	s := *curr
+	var elapsed int64
+	if metadata := m.player.Metadata(); metadata != nil && metadata.Err == nil && metadata.PlaybackTimeSec != nil {
+		elapsed = *metadata.PlaybackTimeSec
+	}
+	metadata := m.player.Seek(int(int64(pos.Seconds()) - elapsed))
+	if metadata == nil || metadata.Err != nil {
+		return nil, true
+	}
+	msg := getMetadataMsg(s, *metadata)
+	return &msg, true
+}
+
 func (m *Model) playUUIDCmd(uuid string) tea.Cmd {
 	return func() tea.Msg {
-		stations, err := m.browser.GetStations([]string{uuid})
+		stations, err := m.directory.GetStations(m.ctx, []string{uuid})
 		res := playUUIDRespMsg{stations: stations}
 		if err != nil {
 			res.statusMsg = statusMsg(err.Error())
@@ -137,3 +320,41 @@ func (m *Model) playUUIDCmd(uuid string) tea.Cmd {
 		return res
 	}
 }
+
+func (m *Model) deadAirCmd(msg deadAirMsg) tea.Cmd {
+	log := slog.With("method", "ui.Model.deadAirCmd")
+
+	reason := fmt.Sprintf("no audio for %v", msg.event.Duration.Round(time.Second))
+	if msg.event.Type == playermodel.StallEvent {
+		reason = fmt.Sprintf("stream stalled for %v", msg.event.Duration.Round(time.Second))
+	}
+	action := m.cfg.Internal.DeadAir.Action
+	log.Info("", "station", msg.station.Name, "reason", reason, "action", action.String())
+
+	switch action {
+	case config.DeadAirWarn:
+		m.updateStatus(fmt.Sprintf("%s: %s", msg.station.Name, reason))
+	case config.DeadAirReconnect:
+		return m.playStationCmd(msg.station)
+	case config.DeadAirSkip:
+		next := m.nextFavorite(msg.station.Stationuuid)
+		if next == nil {
+			m.updateStatus(fmt.Sprintf("%s: %s, no other favorite to skip to", msg.station.Name, reason))
+			return nil
+		}
+		return m.playStationCmd(*next)
+	}
+	return nil
+}
+
+// nextFavorite returns the favorite following the station with the given uuid,
+// or the first favorite if the station is not a favorite.
+func (m *Model) nextFavorite(uuid string) *model.Station {
+	favorites := m.cfg.GetFavorites()
+	idx := slices.IndexFunc(favorites, func(s model.Station) bool { return s.Stationuuid == uuid })
+	next := (idx + 1) % max(1, len(favorites))
+	if len(favorites) == 0 || favorites[next].Stationuuid == uuid {
+		return nil
+	}
+	return &favorites[next]
+}
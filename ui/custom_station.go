@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -20,7 +21,7 @@ type customStationModel struct {
 	enabled bool
 	style   *Style
 
-	browser   *browser.API
+	browser   browser.Lists
 	countries []string
 	languages []string
 
@@ -45,7 +46,7 @@ const (
 	customStationInputIdxBitrate
 )
 
-func newCustomStationModel(b *browser.API, s *Style) *customStationModel {
+func newCustomStationModel(ctx context.Context, b browser.Lists, s *Style) *customStationModel {
 	k := newCustomStationKeymap()
 	inputs := []textinput.Model{
 		s.NewInputModel("Name", "required", &k.prevSugg, &k.nextSugg, &k.acceptSugg, nil),
@@ -72,12 +73,12 @@ func newCustomStationModel(b *browser.API, s *Style) *customStationModel {
 		keymap:     k,
 		help:       h,
 	}
-	go m.getSuggestions()
+	go m.getSuggestions(ctx)
 	return m
 }
 
-func (s *customStationModel) getSuggestions() {
-	countries, err := s.browser.GetCountries()
+func (s *customStationModel) getSuggestions(ctx context.Context) {
+	countries, err := s.browser.GetCountries(ctx)
 	if err == nil && len(countries) > 0 {
 		for i := range countries {
 			s.countries = append(s.countries, countries[i].ISO3166_1)
@@ -86,7 +87,7 @@ func (s *customStationModel) getSuggestions() {
 		s.textInputs[customStationInputIdxCountry].TextInput().SetSuggestions(s.countries)
 	}
 
-	langs, err := s.browser.GetLanguages()
+	langs, err := s.browser.GetLanguages(ctx)
 	if err == nil && len(langs) > 0 {
 		for i := range langs {
 			s.languages = append(s.languages, langs[i].Name)
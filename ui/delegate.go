@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -16,16 +17,18 @@ import (
 	"github.com/dancnb/sonicradio/config"
 	"github.com/dancnb/sonicradio/model"
 	"github.com/dancnb/sonicradio/player"
+	playermodel "github.com/dancnb/sonicradio/player/model"
 )
 
-func newStationDelegate(cfg *config.Value, s *Style, p *player.Player, b *browser.API) *stationDelegate {
+func newStationDelegate(ctx context.Context, cfg *config.Value, s *Style, p *player.Player, dir browser.StationDirectory) *stationDelegate {
 	keymap := newDelegateKeyMap()
 
 	d := list.NewDefaultDelegate()
 
 	st := &stationDelegate{
+		ctx:             ctx,
 		player:          p,
-		b:               b,
+		dir:             dir,
 		cfg:             cfg,
 		style:           s,
 		keymap:          keymap,
@@ -36,8 +39,9 @@ func newStationDelegate(cfg *config.Value, s *Style, p *player.Player, b *browse
 }
 
 type stationDelegate struct {
+	ctx    context.Context
 	player *player.Player
-	b      *browser.API
+	dir    browser.StationDirectory
 	cfg    *config.Value
 	style  *Style
 
@@ -93,6 +97,15 @@ func (d *stationDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd {
 			d.keymap.info.SetEnabled(false)
 			return func() tea.Msg { return toggleInfoMsg{enable: true, station: selStation} }
 
+		case key.Matches(msg, d.keymap.vote):
+			if !isSel {
+				break
+			}
+			if selStation.IsCustom {
+				return func() tea.Msg { return statusMsg(voteCustom) }
+			}
+			return voteCmd(d.ctx, d.dir, d.cfg, selStation.Stationuuid)
+
 		case key.Matches(msg, d.keymap.toggleFavorite):
 			if !isSel {
 				break
@@ -232,7 +245,7 @@ func (d *stationDelegate) playCmd(s model.Station) tea.Cmd {
 }
 
 func (d *stationDelegate) increaseCounter(station model.Station) {
-	_ = d.b.StationCounter(station.Stationuuid)
+	_ = d.dir.StationCounter(d.ctx, station.Stationuuid)
 }
 
 func (d *stationDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
@@ -241,12 +254,27 @@ func (d *stationDelegate) Render(w io.Writer, m list.Model, index int, listItem
 		return
 	}
 	name := s.Name
+	if s.VariantOf != "" {
+		name = VariantChar + name
+	} else if len(s.Variants) > 1 {
+		name += fmt.Sprintf(VariantsFmt, len(s.Variants)-1)
+	}
 	if d.cfg.IsFavorite(s.Stationuuid) {
 		name += FavChar
 	}
 	if d.cfg.AutoplayFavorite == s.Stationuuid {
 		name += d.style.BaseBold.Render(AutoplayChar)
 	}
+	if d.cfg.IsVoted(s.Stationuuid) {
+		name += VotedChar
+	}
+	if s.LastCheckFailed() {
+		name += WarningChar
+	}
+	desc := s.Description()
+	if s.NowPlaying != "" {
+		desc = fmt.Sprintf(NowPlayingFmt, s.NowPlaying, desc)
+	}
 
 	isSel := index == m.Index()
 
@@ -270,7 +298,7 @@ func (d *stationDelegate) Render(w io.Writer, m list.Model, index int, listItem
 		prefixStyle := d.style.NowPlayingPrefixStyle
 		widthOffset := 1
 
-		str = d.renderStationView(prefix, name, s.Description(), listWidth, widthOffset, prefixStyle, itStyle, descStyle)
+		str = d.renderStationView(prefix, name, desc, listWidth, widthOffset, prefixStyle, itStyle, descStyle)
 
 		str = d.style.SelectedBorderStyle.Render(str)
 	} else {
@@ -283,7 +311,7 @@ func (d *stationDelegate) Render(w io.Writer, m list.Model, index int, listItem
 		prefixStyle := d.style.PrefixStyle
 		widthOffset := 0
 
-		str = d.renderStationView(prefix, name, s.Description(), listWidth, widthOffset, prefixStyle, itStyle, descStyle)
+		str = d.renderStationView(prefix, name, desc, listWidth, widthOffset, prefixStyle, itStyle, descStyle)
 	}
 
 	_, _ = fmt.Fprint(w, str)
@@ -424,7 +452,12 @@ func (d *stationDelegate) FullHelp() [][]key.Binding {
 			d.keymap.volumeUp,
 			d.keymap.seekBack,
 			d.keymap.seekFw,
+			d.keymap.songStart,
+			d.keymap.prevSong,
+			d.keymap.nextSong,
+			d.keymap.goLive,
 			d.keymap.info,
+			d.keymap.vote,
 			d.keymap.toggleFavorite,
 			d.keymap.toggleAutoplay,
 			d.keymap.delete,
@@ -448,6 +481,10 @@ func newDelegateKeyMap() *delegateKeyMap {
 			key.WithKeys("i"),
 			key.WithHelp("i", "station info"),
 		),
+		vote: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "vote station"),
+		),
 		toggleFavorite: key.NewBinding(
 			key.WithKeys("f"),
 			key.WithHelp("f", "favorite station"),
@@ -484,13 +521,45 @@ func newDelegateKeyMap() *delegateKeyMap {
 			key.WithKeys("right", ".", ">"),
 			key.WithHelp("→/>", "seek forward"),
 		),
+		songStart: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "song start"),
+		),
+		prevSong: key.NewBinding(
+			key.WithKeys("{"),
+			key.WithHelp("{", "previous song"),
+		),
+		nextSong: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "next song"),
+		),
+		goLive: key.NewBinding(
+			key.WithKeys("}"),
+			key.WithHelp("}", "go live"),
+		),
+	}
+}
+
+// seekTarget maps the song navigation keys to their seek targets.
+func (k *delegateKeyMap) seekTarget(msg tea.KeyMsg) (playermodel.SeekTarget, bool) {
+	switch {
+	case key.Matches(msg, k.songStart):
+		return playermodel.SeekSongStart, true
+	case key.Matches(msg, k.prevSong):
+		return playermodel.SeekPrevSong, true
+	case key.Matches(msg, k.nextSong):
+		return playermodel.SeekNextSong, true
+	case key.Matches(msg, k.goLive):
+		return playermodel.SeekLive, true
 	}
+	return 0, false
 }
 
 type delegateKeyMap struct {
 	pause          key.Binding
 	playSelected   key.Binding
 	info           key.Binding
+	vote           key.Binding
 	toggleFavorite key.Binding
 	toggleAutoplay key.Binding
 	delete         key.Binding
@@ -500,4 +569,8 @@ type delegateKeyMap struct {
 	volumeUp       key.Binding
 	seekBack       key.Binding
 	seekFw         key.Binding
+	songStart      key.Binding
+	prevSong       key.Binding
+	nextSong       key.Binding
+	goLive         key.Binding
 }
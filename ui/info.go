@@ -1,14 +1,17 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dancnb/sonicradio/browser"
+	"github.com/dancnb/sonicradio/config"
 	"github.com/dancnb/sonicradio/model"
 )
 
@@ -17,16 +20,24 @@ type infoModel struct {
 
 	style *Style
 
-	b       *browser.API
+	ctx     context.Context
+	cfg     *config.Value
+	b       browser.RadioBrowser
+	dir     browser.StationDirectory
 	station model.Station
 
+	// stream check history of the station
+	checks        []model.StationCheck
+	checksLoading bool
+	checksErr     string
+
 	keymap infoKeymap
 	help   help.Model
 	width  int
 	height int
 }
 
-func newInfoModel(b *browser.API, s *Style) *infoModel {
+func newInfoModel(ctx context.Context, cfg *config.Value, b browser.RadioBrowser, dir browser.StationDirectory, s *Style) *infoModel {
 	k := newInfoKeymap()
 
 	h := help.New()
@@ -35,7 +46,10 @@ func newInfoModel(b *browser.API, s *Style) *infoModel {
 	h.Styles = s.HelpStyles()
 
 	return &infoModel{
+		ctx:    ctx,
+		cfg:    cfg,
 		b:      b,
+		dir:    dir,
 		style:  s,
 		keymap: k,
 		help:   h,
@@ -45,7 +59,16 @@ func newInfoModel(b *browser.API, s *Style) *infoModel {
 func (i *infoModel) Init(s model.Station) tea.Cmd {
 	i.station = s
 	i.setEnabled(true)
-	return nil
+	i.checks = nil
+	i.checksErr = ""
+	i.checksLoading = !s.IsCustom && browser.IsRadioBrowserID(s.Stationuuid) && i.b.Online()
+	if !i.checksLoading {
+		return nil
+	}
+	return func() tea.Msg {
+		checks, err := i.b.StationChecks(i.ctx, s.Stationuuid)
+		return stationChecksMsg{uuid: s.Stationuuid, checks: checks, err: err}
+	}
 }
 
 func (s *infoModel) setSize(width, height int) {
@@ -73,16 +96,23 @@ func (i *infoModel) Update(msg tea.Msg) (*infoModel, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		i.setSize(msg.Width, msg.Height)
 
+	case stationChecksMsg:
+		if msg.uuid != i.station.Stationuuid {
+			break
+		}
+		i.checksLoading = false
+		i.checks = msg.checks
+		if msg.err != nil {
+			i.checksErr = msg.err.Error()
+		}
+
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, i.keymap.vote):
-			return i, func() tea.Msg {
-				err := i.b.StationVote(i.station.Stationuuid)
-				if err != nil {
-					return statusMsg(err.Error())
-				}
-				return statusMsg(voteSuccesful)
+			if i.station.IsCustom {
+				return i, func() tea.Msg { return statusMsg(voteCustom) }
 			}
+			return i, voteCmd(i.ctx, i.dir, i.cfg, i.station.Stationuuid)
 		case key.Matches(msg, i.keymap.cancel):
 			return i, func() tea.Msg {
 				i.setEnabled(false)
@@ -101,6 +131,7 @@ func (i *infoModel) View() string {
 	i.renderInfoField(&b, "Stream URL    ", i.station.URL)
 	i.renderInfoField(&b, "Tags          ", i.station.Tags)
 	i.renderInfoField(&b, "Votes         ", fmt.Sprintf("%d", i.station.Votes))
+	i.renderInfoField(&b, "Voted         ", i.lastVote())
 	i.renderInfoField(&b, "Clicks        ", fmt.Sprintf("%d", i.station.Clickcount))
 	trend := fmt.Sprintf("%d", i.station.Clicktrend)
 	if i.station.Clicktrend > 0 {
@@ -121,6 +152,7 @@ func (i *infoModel) View() string {
 	i.renderInfoField(&b, "Country       ", country)
 	i.renderInfoField(&b, "State         ", i.station.State)
 	i.renderInfoField(&b, "Language      ", i.station.Language)
+	i.renderInfoField(&b, "Last check    ", i.lastCheck())
 	i.renderInfoField(&b, "Last ok check ", i.station.Lastcheckoktime)
 	lat := ""
 	if i.station.GeoLat != nil {
@@ -137,6 +169,8 @@ func (i *infoModel) View() string {
 	help := i.style.HelpStyle.Render(i.help.View(&i.keymap))
 	availHeight -= lipgloss.Height(help)
 
+	i.renderChecks(&b, availHeight-lipgloss.Height(b.String()))
+
 	content := b.String()
 	inputsHeight := lipgloss.Height(content)
 	for i := 0; i < availHeight-inputsHeight; i++ {
@@ -145,6 +179,87 @@ func (i *infoModel) View() string {
 	return b.String() + help
 }
 
+func (i *infoModel) lastVote() string {
+	t, ok := i.cfg.LastVote(i.station.Stationuuid)
+	if !ok {
+		return ""
+	}
+	res := t.Local().Format(time.DateTime)
+	if wait := i.cfg.VoteCooldownLeft(i.station.Stationuuid, time.Now()); wait > 0 {
+		res += fmt.Sprintf(", vote again in %s", wait.Round(time.Second))
+	}
+	return res
+}
+
+func (i *infoModel) lastCheck() string {
+	if strings.TrimSpace(i.station.Lastchecktime) == "" {
+		return ""
+	}
+	res := "ok"
+	if i.station.LastCheckFailed() {
+		res = "failed" + WarningChar
+	}
+	res += ", " + i.station.Lastchecktime
+	if i.station.SSLError != 0 {
+		res += ", SSL error"
+	}
+	return res
+}
+
+// renderChecks writes the check history lines that fit in maxLines.
+func (i *infoModel) renderChecks(b *strings.Builder, maxLines int) {
+	// a blank line, the title and at least one check
+	if i.station.IsCustom || !browser.IsRadioBrowserID(i.station.Stationuuid) || maxLines < 3 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i.style.InfoFieldNameStyle.Render("Check history"))
+	b.WriteString("\n")
+	maxLines -= 2
+
+	var lines []string
+	switch {
+	case i.checksLoading:
+		lines = append(lines, checksLoadingMsg)
+	case i.checksErr != "":
+		lines = append(lines, i.checksErr)
+	case len(i.checks) == 0:
+		lines = append(lines, noChecksMsg)
+	}
+	for _, c := range i.checks {
+		lines = append(lines, checkLine(c))
+	}
+	for _, l := range lines[:min(len(lines), maxLines)] {
+		b.WriteString(i.style.SecondaryColorStyle.MaxWidth(max(i.width, 0)).Render(l))
+		b.WriteString("\n")
+	}
+}
+
+// checkLine formats the check time, result, detected stream and check server.
+func checkLine(c model.StationCheck) string {
+	ts := c.Timestamp
+	if t, err := time.Parse(time.RFC3339, c.Timestamp); err == nil {
+		ts = t.Local().Format(time.DateTime)
+	}
+	result := "ok  "
+	detail := strings.TrimSpace(c.Codec)
+	if c.Bitrate != 0 {
+		detail += fmt.Sprintf(" %d kbps", c.Bitrate)
+	}
+	if c.Ok == 0 {
+		result = "fail"
+		detail = c.FailureReason()
+	}
+	if c.SSLError != 0 {
+		detail += ", SSL error"
+	}
+	detail = strings.TrimPrefix(strings.TrimSpace(detail), ", ")
+	if detail == "" {
+		detail = "-"
+	}
+	return fmt.Sprintf("%s  %s  %-24s  %s", ts, result, detail, c.Source)
+}
+
 func (i *infoModel) renderInfoField(b *strings.Builder, fieldName, fieldValue string) {
 	fnRender := i.style.InfoFieldNameStyle.Render(PadFieldName(fieldName, nil))
 	b.WriteString(fnRender)
@@ -8,10 +8,38 @@ func newListKeymap() listKeymap {
 			key.WithKeys("s"),
 			key.WithHelp("s", "search"),
 		),
+		categories: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "browse categories"),
+		),
+		nearby: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "stations near me"),
+		),
+		recommended: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "recommended stations"),
+		),
+		nextFeed: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "next feed"),
+		),
+		reloadFeed: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "reload feed"),
+		),
+		variants: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "toggle variants"),
+		),
 		addCustomFavorite: key.NewBinding(
 			key.WithKeys("A"),
 			key.WithHelp("A", "add custom favorite"),
 		),
+		publishCustom: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "publish custom station"),
+		),
 		toNowPlaying: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "go to now playing"),
@@ -36,6 +64,10 @@ func newListKeymap() listKeymap {
 			key.WithKeys("H"),
 			key.WithHelp("H", "go to history tab"),
 		),
+		podcastsTab: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "go to podcasts tab"),
+		),
 		settingsTab: key.NewBinding(
 			key.WithKeys("S"),
 			key.WithHelp("S", "go to settings tab"),
@@ -66,13 +98,21 @@ func newListKeymap() listKeymap {
 
 type listKeymap struct {
 	search            key.Binding
+	categories        key.Binding
+	nearby            key.Binding
+	recommended       key.Binding
+	nextFeed          key.Binding
+	reloadFeed        key.Binding
+	variants          key.Binding
 	addCustomFavorite key.Binding
+	publishCustom     key.Binding
 	toNowPlaying      key.Binding
 	nextTab           key.Binding
 	prevTab           key.Binding
 	favoritesTab      key.Binding
 	browseTab         key.Binding
 	historyTab        key.Binding
+	podcastsTab       key.Binding
 	settingsTab       key.Binding
 	stationView       key.Binding
 	digits            []key.Binding
@@ -81,13 +121,21 @@ type listKeymap struct {
 
 func (k *listKeymap) setEnabled(v bool) {
 	k.search.SetEnabled(v)
+	k.categories.SetEnabled(v)
+	k.nearby.SetEnabled(v)
+	k.recommended.SetEnabled(v)
+	k.nextFeed.SetEnabled(v)
+	k.reloadFeed.SetEnabled(v)
+	k.variants.SetEnabled(v)
 	k.addCustomFavorite.SetEnabled(v)
+	k.publishCustom.SetEnabled(v)
 	k.toNowPlaying.SetEnabled(v)
 	k.nextTab.SetEnabled(v)
 	k.prevTab.SetEnabled(v)
 	k.favoritesTab.SetEnabled(v)
 	k.browseTab.SetEnabled(v)
 	k.historyTab.SetEnabled(v)
+	k.podcastsTab.SetEnabled(v)
 	k.settingsTab.SetEnabled(v)
 	k.stationView.SetEnabled(v)
 	for i := range k.digits {
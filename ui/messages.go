@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dancnb/sonicradio/browser"
 	smodel "github.com/dancnb/sonicradio/model"
 	"github.com/dancnb/sonicradio/player/model"
+	"github.com/dancnb/sonicradio/podcast"
 )
 
 // tea.Msg
@@ -19,6 +21,7 @@ type (
 		stationName  string
 		songTitle    string
 		playbackTime *time.Duration
+		buffered     *model.BufferedRange
 	}
 
 	volumeMsg struct {
@@ -41,6 +44,10 @@ type (
 		viewMsg
 		statusMsg
 		stations []smodel.Station
+		// params of the first page, nil if more pages can't be loaded
+		params *browser.SearchParams
+		// source loaded the first page, the next pages are loaded from it
+		source browser.StationDirectory
 	}
 
 	searchRespMsg struct {
@@ -48,6 +55,29 @@ type (
 		statusMsg
 		stations  []smodel.Station
 		cancelled bool
+		// params of the first page, nil if more pages can't be loaded
+		params *browser.SearchParams
+		// source loaded the first page, the next pages are loaded from it
+		source browser.StationDirectory
+	}
+
+	// the saved searches pinned as tabs changed
+	pinnedSearchesMsg struct{}
+
+	// results of the saved search pinned as a tab
+	savedSearchRespMsg struct {
+		viewMsg
+		statusMsg
+		name     string
+		stations []smodel.Station
+	}
+
+	// next page of the browse results
+	pageRespMsg struct {
+		statusMsg
+		stations []smodel.Station
+		params   browser.SearchParams
+		gen      int
 	}
 
 	customStationRespMsg struct {
@@ -55,6 +85,20 @@ type (
 		cancelled bool
 	}
 
+	// stream check history of the station shown in the info view
+	stationChecksMsg struct {
+		uuid   string
+		checks []smodel.StationCheck
+		err    error
+	}
+
+	// custom station published to radio-browser
+	publishRespMsg struct {
+		customUUID string
+		station    *smodel.Station
+		err        error
+	}
+
 	toggleFavoriteMsg struct {
 		added   bool
 		station smodel.Station
@@ -82,6 +126,57 @@ type (
 		statusMsg
 		stations []smodel.Station
 	}
+
+	// silence or stall detected by the player
+	deadAirMsg struct {
+		station smodel.Station
+		event   model.Event
+	}
+
+	// entries of a browse category
+	categoryEntriesMsg struct {
+		kind    categoryKind
+		entries []categoryItem
+		err     error
+	}
+
+	// menu of an OPML directory, opened at depth
+	opmlMenuMsg struct {
+		depth int
+		opml  *browser.OPMLDirectory
+		menu  *browser.OPMLMenu
+		err   error
+	}
+
+	// subscribed podcast feeds, nil for the failed ones
+	podcastFeedsMsg struct {
+		feeds []*podcast.Feed
+		err   error
+	}
+
+	podcastSubscribeMsg struct {
+		url  string
+		feed *podcast.Feed
+		err  error
+	}
+
+	// an episode was sought to its resume position, or could not be
+	episodeResumedMsg struct {
+		uuid     string
+		position time.Duration
+		metadata *metadataMsg
+	}
+
+	// radio-browser connectivity changed
+	browserStatusMsg struct {
+		online bool
+	}
+
+	// the polled song title of a favorite changed, empty if no longer known
+	nowPlayingMsg struct {
+		uuid  string
+		title string
+	}
 )
 
 func getMetadataMsg(s smodel.Station, m model.Metadata) metadataMsg {
@@ -89,6 +184,7 @@ func getMetadataMsg(s smodel.Station, m model.Metadata) metadataMsg {
 		stationUUID: s.Stationuuid,
 		stationName: s.Name,
 		songTitle:   m.Title,
+		buffered:    m.Buffered,
 	}
 	if m.PlaybackTimeSec != nil {
 		t := time.Second * (time.Duration(*m.PlaybackTimeSec))
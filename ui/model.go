@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -22,7 +23,10 @@ import (
 	"github.com/dancnb/sonicradio/browser"
 	"github.com/dancnb/sonicradio/config"
 	"github.com/dancnb/sonicradio/model"
+	"github.com/dancnb/sonicradio/nowplaying"
 	"github.com/dancnb/sonicradio/player"
+	playermodel "github.com/dancnb/sonicradio/player/model"
+	"github.com/dancnb/sonicradio/podcast"
 )
 
 const (
@@ -30,51 +34,120 @@ const (
 	loadingMsg          = "\n  Fetching stations... \n"
 	noFavoritesAddedMsg = "\n  No favorite stations added.\n"
 	noStationsFound     = "\n  No stations found. \n"
+	recommendingMsg     = "\n  Finding stations you may like... \n"
+	offlineViewMsg      = "\n  Offline: stations can be browsed once radio-browser is reachable again. \n"
 	emptyHistoryMsg     = "\n  No playback history available. \n"
+	noPodcastsMsg       = "\n  No podcasts subscribed, press a to add a feed. \n"
+	loadingPodcastsMsg  = "\n  Fetching podcasts... \n"
+	noEpisodesMsg       = "\n  No episodes found. \n"
 
 	// header status
-	noPlayingMsg     = "Nothing playing"
-	missingFavorites = "Some stations were not found"
-	prevTermErr      = "Could not terminate previous playback!"
-	voteSuccesful    = "Station was voted successfully"
+	noPlayingMsg      = "Nothing playing"
+	missingFavorites  = "Some stations were not found"
+	prevTermErr       = "Could not terminate previous playback!"
+	voteSuccesful     = "Station was voted successfully"
+	voteCooldown      = "Station was voted recently, vote again in %s"
+	voteCustom        = "Custom stations can't be voted"
+	offlineStatus     = "Offline: radio-browser is unreachable"
+	onlineStatus      = "Connected to radio-browser"
+	searchOffline     = "Search is not available offline"
+	categoriesOffline = "Categories are not available offline"
+	loadingPageMsg    = "Loading more stations..."
+	nearbyOffline     = "Nearby stations are not available offline"
+	recommendOffline  = "Recommendations are not available offline"
+	recommendedStatus = "Recommended stations"
+	feedsOffline      = "Feeds are not available offline"
+	feedStatus        = "Feed: %s"
+	publishOffline    = "Publishing is not available offline"
+	publishNotCustom  = "Only custom stations can be published"
+	publishConfirm    = "Press U again to publish %q to radio-browser"
+	publishing        = "Checking the stream and publishing..."
+	published         = "Published to radio-browser, station UUID %s"
+	checksLoadingMsg  = "Fetching check history..."
+	noChecksMsg       = "No checks available"
+
+	podcastSubscribing        = "Fetching the podcast feed..."
+	podcastSubscribed         = "Subscribed to %s"
+	podcastUnsubscribeConfirm = "Press d again to unsubscribe from %q"
+	podcastUnsubscribed       = "Unsubscribed from %s"
+	podcastsReloading         = "Reloading podcasts..."
+	episodeResumeAt           = "resume at %s"
+	episodeResumed            = "Resumed at %s"
+	episodeResumeFailed       = "Could not resume the episode at %s"
+
 	statusMsgTimeout = 1 * time.Second
 
 	// metadata
 	volumeFmt          = "%3d%%%s"
 	playerPollInterval = 500 * time.Millisecond
+
+	// the resume seek is retried until the player has opened the episode
+	episodeResumeAttempts = 10
+	episodeResumeDelay    = 500 * time.Millisecond
 )
 
-func NewModel(ctx context.Context, cfg *config.Value, b *browser.API, p *player.Player) *Model {
-	m := newModel(ctx, cfg, b, p)
+func NewModel(
+	ctx context.Context,
+	cfg *config.Value,
+	b browser.RadioBrowser,
+	dir browser.StationDirectory,
+	providers []browser.Provider,
+	p *player.Player,
+) *Model {
+	m := newModel(ctx, cfg, b, dir, providers, p)
 	progr := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx))
 	m.Progr = progr
 	trapSignal(progr)
 	go updatePlayerMetadata(ctx, progr, m)
+	go updateBrowserStatus(ctx, progr, b)
+	go m.nowPlaying.Run(ctx)
+	go updateNowPlaying(ctx, progr, m.nowPlaying)
 	return m
 }
 
-func newModel(ctx context.Context, cfg *config.Value, b *browser.API, p *player.Player) *Model {
+func newModel(
+	ctx context.Context,
+	cfg *config.Value,
+	b browser.RadioBrowser,
+	dir browser.StationDirectory,
+	providers []browser.Provider,
+	p *player.Player,
+) *Model {
 	style := NewStyle(cfg.Theme)
 
-	delegate := newStationDelegate(cfg, style, p, b)
+	delegate := newStationDelegate(ctx, cfg, style, p, dir)
+
+	infoModel := newInfoModel(ctx, cfg, b, dir, style)
+
+	client := cfg.NewHTTPClient()
+	fetchTitle := func(ctx context.Context, url string) (string, error) {
+		return player.StreamTitle(ctx, client, url)
+	}
+	poller := nowplaying.New(fetchTitle, cfg.NowPlaying.GetInterval(), config.DefNowPlayingConcurrency)
+	poller.SetEnabled(cfg.NowPlaying.Enabled)
 
-	infoModel := newInfoModel(b, style)
 	m := Model{
+		ctx:          ctx,
 		cfg:          cfg,
 		style:        style,
 		browser:      b,
+		directory:    dir,
+		providers:    providers,
 		player:       p,
 		delegate:     delegate,
 		statusUpdate: make(chan struct{}),
+		nowPlaying:   poller,
 
 		volumeBar: getVolumeBar(style.GetSecondColor()),
 	}
 	m.tabs = []uiTab{
-		newFavoritesTab(cfg, infoModel, b, style),
-		newBrowseTab(ctx, b, infoModel, style),
+		newFavoritesTab(ctx, cfg, infoModel, b, style),
+		newBrowseTab(ctx, cfg, b, dir, providers, infoModel, style),
 		newHistoryTab(ctx, cfg, style),
-		newSettingsTab(ctx, cfg, style, p.AvailablePlayerTypes(), m.changeTheme),
+		newPodcastsTab(ctx, cfg, style),
+		newSettingsTab(ctx, cfg, style, p.AvailablePlayerTypes(), m.changeTheme, m.changeNowPlaying),
 	}
+	m.syncSearchTabs()
 
 	if cfg.HasFavorites() || cfg.HasFavoritesV1() {
 		m.toFavoritesTab()
@@ -108,6 +181,28 @@ func updatePlayerMetadata(ctx context.Context, progr *tea.Program, m *Model) {
 	}
 }
 
+func updateBrowserStatus(ctx context.Context, progr *tea.Program, b browser.RadioBrowser) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case online := <-b.StatusUpdates():
+			progr.Send(browserStatusMsg{online: online})
+		}
+	}
+}
+
+func updateNowPlaying(ctx context.Context, progr *tea.Program, p *nowplaying.Poller) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u := <-p.Updates():
+			progr.Send(nowPlayingMsg{uuid: u.StationID, title: u.Title})
+		}
+	}
+}
+
 func pollMetadata(m *Model, progr *tea.Program) {
 	log := slog.With("method", "pollMetadata")
 
@@ -120,7 +215,12 @@ func pollMetadata(m *Model, progr *tea.Program) {
 	metadata := m.player.Metadata()
 	if metadata == nil {
 		return
-	} else if metadata.Err != nil {
+	}
+	if metadata.Event != nil {
+		deadAir := deadAirMsg{station: *m.delegate.currPlaying, event: *metadata.Event}
+		go progr.Send(deadAir)
+	}
+	if metadata.Err != nil {
 		log.Error("", "metadata", metadata.Err)
 		return
 	}
@@ -130,13 +230,20 @@ func pollMetadata(m *Model, progr *tea.Program) {
 
 type Model struct {
 	Progr *tea.Program
-
-	ready    bool
-	cfg      *config.Value
-	style    *Style
-	browser  *browser.API
-	player   *player.Player
-	delegate *stationDelegate
+	ctx   context.Context
+
+	ready   bool
+	cfg     *config.Value
+	style   *Style
+	browser browser.RadioBrowser
+	// directory is radio-browser together with the configured directories
+	directory browser.StationDirectory
+	// providers are the configured directories
+	providers []browser.Provider
+	player    *player.Player
+	delegate  *stationDelegate
+	// nowPlaying polls the song titles of the favorites
+	nowPlaying *nowplaying.Poller
 
 	tabs         []uiTab
 	activeTabIdx uiTabIndex
@@ -147,9 +254,13 @@ type Model struct {
 
 	// display station metadata
 	playbackTime time.Duration
-	spinner      *spinner.Model
-	songTitle    string
-	volumeBar    progress.Model
+	buffered     *playermodel.BufferedRange
+	// resumingEpisode is the episode station being sought to its resume
+	// position, whose position is not saved meanwhile
+	resumingEpisode string
+	spinner         *spinner.Model
+	songTitle       string
+	volumeBar       progress.Model
 
 	width        int
 	totHeight    int
@@ -196,16 +307,24 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case metadataMsg:
-		go m.cfg.AddHistoryEntry(
-			time.Now(),
-			strings.TrimSpace(msg.stationUUID),
-			strings.TrimSpace(msg.stationName),
-			strings.TrimSpace(msg.songTitle),
-		)
+		if id, ok := podcast.EpisodeID(msg.stationUUID); ok {
+			// episodes are not kept in the history, only their position
+			if msg.playbackTime != nil && m.resumingEpisode != msg.stationUUID {
+				m.tabs[podcastsTabIx].(*podcastsTab).savePosition(id, *msg.playbackTime)
+			}
+		} else {
+			go m.cfg.AddHistoryEntry(
+				time.Now(),
+				strings.TrimSpace(msg.stationUUID),
+				strings.TrimSpace(msg.stationName),
+				strings.TrimSpace(msg.songTitle),
+			)
+		}
 		m.songTitle = msg.songTitle
 		if msg.playbackTime != nil {
 			m.playbackTime = *msg.playbackTime
 		}
+		m.buffered = msg.buffered
 		return m, nil
 
 	case spinner.TickMsg:
@@ -220,18 +339,62 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	//
 	// messages that need to reach a particular tab
 	//
-	case topStationsRespMsg, searchRespMsg:
+	case topStationsRespMsg, searchRespMsg, categoryEntriesMsg, opmlMenuMsg, pageRespMsg:
 		return m.tabs[browseTabIx].Update(m, msg)
 
-	case customStationRespMsg:
+	case pinnedSearchesMsg:
+		return m, m.syncSearchTabs()
+
+	case savedSearchRespMsg:
+		for _, t := range m.tabs[settingsTabIx+1:] {
+			if t.(*searchTab).name == msg.name {
+				return t.Update(m, msg)
+			}
+		}
+		return m, nil
+
+	case podcastFeedsMsg, podcastSubscribeMsg:
+		return m.tabs[podcastsTabIx].Update(m, msg)
+
+	case episodeResumedMsg:
+		if m.resumingEpisode == msg.uuid {
+			m.resumingEpisode = ""
+		}
+		if msg.metadata == nil {
+			m.updateStatus(fmt.Sprintf(episodeResumeFailed, formatPosition(msg.position)))
+			return m, nil
+		}
+		m.updateStatus(fmt.Sprintf(episodeResumed, formatPosition(msg.position)))
+		return m.Update(*msg.metadata)
+
+	case customStationRespMsg, publishRespMsg:
+		return m.tabs[favoriteTabIx].Update(m, msg)
+
+	case stationChecksMsg:
+		// the info view is shared by the station tabs
 		return m.tabs[favoriteTabIx].Update(m, msg)
 
 	case favoritesStationRespMsg:
 		return m.tabs[favoriteTabIx].Update(m, msg)
 
-	case toggleFavoriteMsg:
+	case toggleFavoriteMsg, nowPlayingMsg:
 		return m.tabs[favoriteTabIx].Update(m, msg)
 
+	case deadAirMsg:
+		return m, m.deadAirCmd(msg)
+
+	case browserStatusMsg:
+		if !msg.online {
+			m.updateStatus(offlineStatus)
+			return m, nil
+		}
+		m.updateStatus(onlineStatus)
+		if bt := m.tabs[browseTabIx].(*browseTab); len(bt.list.Items()) == 0 {
+			bt.viewMsg = loadingMsg
+			return m, m.topStationsCmd
+		}
+		return m, nil
+
 	case pauseRespMsg:
 		if msg.err != "" {
 			m.updateStatus(msg.err)
@@ -254,7 +417,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else if activeTab, ok := activeTab.(filteringTab); ok && activeTab.IsFiltering() {
 			break
 		} else if activeTab, ok := activeTab.(stationTab); ok &&
-			(activeTab.IsSearchEnabled() || activeTab.IsFiltering() || activeTab.IsCustomStationEnabled()) {
+			(activeTab.IsSearchEnabled() || activeTab.IsFiltering() || activeTab.IsCustomStationEnabled() || activeTab.IsCategoryEnabled()) {
 			break
 		}
 
@@ -278,6 +441,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, m.seekCmd(config.SeekStepSec)
 		}
+		if target, ok := d.keymap.seekTarget(msg); ok {
+			if m.activeTabIdx == settingsTabIx {
+				return m.tabs[settingsTabIx].Update(m, msg)
+			}
+			return m, m.seekToCmd(target)
+		}
 
 		if key.Matches(msg, d.keymap.pause) {
 			if m.activeTabIdx == settingsTabIx {
@@ -368,18 +537,101 @@ func (m *Model) toBrowseTab() {
 	m.delegate.keymap.toggleFavorite.SetEnabled(true)
 	m.delegate.keymap.toggleAutoplay.SetEnabled(false)
 	m.activeTabIdx = browseTabIx
+	m.tabs[browseTabIx].(*browseTab).regroup()
 }
 
 func (m *Model) toHistoryTab() {
 	m.activeTabIdx = historyTabIx
 }
 
+func (m *Model) toPodcastsTab() {
+	m.activeTabIdx = podcastsTabIx
+}
+
 func (m *Model) toSettingsTab() tea.Cmd {
 	m.activeTabIdx = settingsTabIx
 	st := m.tabs[settingsTabIx].(*settingsTab)
 	return st.onEnter()
 }
 
+// toSearchTab opens the pinned search tab at ix and re-runs its search.
+func (m *Model) toSearchTab(ix uiTabIndex) tea.Cmd {
+	m.delegate.keymap.toggleFavorite.SetEnabled(true)
+	m.delegate.keymap.toggleAutoplay.SetEnabled(false)
+	m.activeTabIdx = ix
+	return m.tabs[ix].(*searchTab).searchCmd(m)
+}
+
+// toTab opens the tab at ix, wrapping around the ends of the tabs.
+func (m *Model) toTab(ix int) tea.Cmd {
+	ix = (ix + len(m.tabs)) % len(m.tabs)
+	switch uiTabIndex(ix) {
+	case favoriteTabIx:
+		m.toFavoritesTab()
+	case browseTabIx:
+		m.toBrowseTab()
+	case historyTabIx:
+		m.toHistoryTab()
+	case podcastsTabIx:
+		m.toPodcastsTab()
+	case settingsTabIx:
+		return m.toSettingsTab()
+	default:
+		return m.toSearchTab(uiTabIndex(ix))
+	}
+	return nil
+}
+
+// syncSearchTabs shows a tab after the settings tab for each pinned saved
+// search.
+func (m *Model) syncSearchTabs() tea.Cmd {
+	existing := make(map[string]*searchTab)
+	for _, t := range m.tabs[settingsTabIx+1:] {
+		st := t.(*searchTab)
+		existing[st.name] = st
+	}
+	var active string
+	if m.activeTabIdx > settingsTabIx {
+		active = m.tabs[m.activeTabIdx].(*searchTab).name
+	}
+
+	var cmds []tea.Cmd
+	tabs := slices.Clip(m.tabs[:settingsTabIx+1])
+	infoModel := m.tabs[browseTabIx].(*browseTab).infoModel
+	for _, ss := range m.cfg.PinnedSearches() {
+		t, ok := existing[ss.Name]
+		if !ok {
+			t = newSearchTab(m.ctx, ss.Name, infoModel, m.style)
+			if m.ready {
+				cmds = append(cmds, t.Init(m))
+			}
+		}
+		tabs = append(tabs, t)
+	}
+	m.tabs = tabs
+
+	if active != "" {
+		ix := slices.IndexFunc(m.tabs, func(t uiTab) bool {
+			st, ok := t.(*searchTab)
+			return ok && st.name == active
+		})
+		if ix < 0 {
+			m.toBrowseTab()
+		} else {
+			m.activeTabIdx = uiTabIndex(ix)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// tabName is the header name of the tab at ix.
+func (m *Model) tabName(ix int) string {
+	if st, ok := m.tabs[ix].(*searchTab); ok {
+		return st.tabName()
+	}
+	return uiTabIndex(ix).String()
+}
+
 func (m *Model) updateStatus(msg string) {
 	slog.Info("updateStatus", "old", m.statusMsg, "new", msg)
 	m.statusMsg = msg
@@ -438,7 +690,11 @@ func (m *Model) headerView(width int) string {
 		status = m.style.StatusBarStyle.Render(strings.Repeat(" ", HeaderPadDist) + m.statusMsg)
 	}
 	res.WriteString(status)
-	appNameVers := m.style.StatusBarStyle.Render(fmt.Sprintf("sonicradio v%v  ", m.cfg.Version))
+	appNameVers := fmt.Sprintf("sonicradio v%v  ", m.cfg.Version)
+	if !m.browser.Online() {
+		appNameVers = "offline  " + appNameVers
+	}
+	appNameVers = m.style.StatusBarStyle.Render(appNameVers)
 	fill := max(0, width-lipgloss.Width(status)-lipgloss.Width(appNameVers)-2*HeaderPadDist)
 	res.WriteString(m.style.StatusBarStyle.Render(strings.Repeat(" ", fill)))
 	res.WriteString(appNameVers)
@@ -453,11 +709,11 @@ func (m *Model) headerView(width int) string {
 	renderedTabs = append(renderedTabs, m.style.TabGap.Render(strings.Repeat(" ", TabGapDistance)))
 	for i := range m.tabs {
 		if i == int(m.activeTabIdx) {
-			tabName := m.activeTabIdx.String()
+			tabName := m.tabName(i)
 			renderedTab := m.renderTabName(tabName, &m.style.ActiveTabInner, &m.style.ActiveTabInnerHighlight)
 			renderedTabs = append(renderedTabs, m.style.ActiveTabBorder.Render(renderedTab.String()))
 		} else {
-			tabName := uiTabIndex(i).String()
+			tabName := m.tabName(i)
 			renderedTab := m.renderTabName(tabName, &m.style.InactiveTabInner, &m.style.InactiveTabInnerHighlight)
 			renderedTabs = append(renderedTabs, m.style.InactiveTabBorder.Render(renderedTab.String()))
 		}
@@ -505,6 +761,9 @@ func (m *Model) metadataView(width int) string {
 		gap,
 	)
 	playTimeView := m.style.ItalicStyle.Render(playTime)
+	if scrubber := m.scrubberView(lipgloss.Width(playTime) - 2*len(gap)); scrubber != "" {
+		playTimeView += "\n" + gap + scrubber + gap
+	}
 	metadataParts[0] = playTimeView
 
 	volumeView := gap +
@@ -579,6 +838,19 @@ func (m *Model) metadataView(width int) string {
 	return metadataRows
 }
 
+// scrubberView renders the buffered range of the stream with the current playback position.
+func (m *Model) scrubberView(width int) string {
+	b := m.buffered
+	if b == nil || b.LiveSec <= b.StartSec || width < 2 {
+		return ""
+	}
+	pos := int64(m.playbackTime.Seconds())
+	x := int((pos - b.StartSec) * int64(width-1) / (b.LiveSec - b.StartSec))
+	x = min(max(x, 0), width-1)
+	return m.style.PrimaryColorStyle.Render(strings.Repeat(ScrubberPlayedChar, x)+ScrubberPosChar) +
+		m.style.SecondaryColorStyle.Render(strings.Repeat(ScrubberBufferedChar, width-1-x))
+}
+
 func (m Model) View() string {
 	if !m.ready {
 		return loadingMsg
@@ -604,6 +876,15 @@ func (m *Model) changeStationView() {
 	}
 }
 
+func (m *Model) changeNowPlaying(enabled bool) {
+	if enabled == m.nowPlaying.Enabled() {
+		return
+	}
+	m.nowPlaying.SetEnabled(enabled)
+	// the titles are forgotten when disabled
+	m.tabs[favoriteTabIx].(*favoritesTab).syncNowPlaying(m)
+}
+
 func (m *Model) changeTheme(themeIdx int) {
 	m.style.SetThemeIdx(themeIdx)
 	m.cfg.Theme = themeIdx
@@ -628,6 +909,9 @@ func (m *Model) changeTheme(themeIdx int) {
 					m.style.TextInputSyle(input, input.Prompt, input.Placeholder)
 					input.PromptStyle = m.style.PromptStyle
 				}
+				nameInput := &browse.searchModel.nameInput
+				m.style.TextInputSyle(nameInput, nameInput.Prompt, nameInput.Placeholder)
+				nameInput.PromptStyle = m.style.PromptStyle
 				browse.searchModel.help.Styles = helpStyle
 			} else if favorites, ok := t.(*favoritesTab); ok {
 				for iIdx := range favorites.customStationModel.textInputs {
@@ -638,6 +922,13 @@ func (m *Model) changeTheme(themeIdx int) {
 				favorites.customStationModel.help.Styles = helpStyle
 			}
 
+		} else if pt, ok := m.tabs[i].(*podcastsTab); ok {
+			m.style.TextInputSyle(&pt.list.FilterInput, stationsFilterPrompt, podcastsFilterPlaceholder)
+			m.style.TextInputSyle(&pt.input, podcastPrompt, podcastPlaceholder)
+			pt.list.Help.Styles = helpStyle
+			pt.list.Styles.HelpStyle = m.style.HelpStyle
+			pt.list.Styles.NoItems = m.style.NoItemsStyle
+
 		} else if ht, ok := m.tabs[i].(*historyTab); ok {
 			m.style.TextInputSyle(&ht.list.FilterInput, stationsFilterPrompt, historyFilterPlaceholder)
 			ht.list.Help.Styles = helpStyle
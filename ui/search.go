@@ -1,10 +1,13 @@
 package ui
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -12,25 +15,49 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dancnb/sonicradio/browser"
+	"github.com/dancnb/sonicradio/config"
 )
 
 type searchModel struct {
 	enabled bool
 
 	style *Style
+	cfg   *config.Value
 
-	browser   *browser.API
+	ctx context.Context
+	// cancelSearch aborts the in-flight search request
+	cancelSearch context.CancelFunc
+
+	browser   browser.Lists
+	directory browser.StationDirectory
 	countries []string
 	languages []string
+	codecs    []string
 
 	textInputs []FormElement
 	idx        inputIdx
+	// showAdvanced shows the inputs after limit
+	showAdvanced bool
 
 	orderOptions OptionList
 	oIdx         orderIx
 
 	reverse FormElement
 
+	// picks are the saved searches and the search history, browsed instead
+	// of the inputs while picking
+	picks       []searchPick
+	pickIdx     int
+	picking     bool
+	picksKeymap searchPicksKeymap
+
+	// naming prompts for the name the form is saved as
+	naming       bool
+	nameInput    textinput.Model
+	namingKeymap searchNamingKeymap
+	// savedName is the name of the saved search loaded in the form
+	savedName string
+
 	keymap searchKeymap
 	help   help.Model
 	width  int
@@ -45,6 +72,17 @@ const (
 	country
 	language
 	limit
+	// advanced filters
+	nameExact
+	tagsExact
+	countryExact
+	countryCode
+	codec
+	bitrateMin
+	bitrateMax
+	httpsOnly
+	hasGeoInfo
+	hasExtendedInfo
 )
 
 type orderIx uint8
@@ -92,7 +130,7 @@ var orderView = []OptionValue{
 	{IdxView: 0, NameView: "Random           "},
 }
 
-func newSearchModel(ctx context.Context, browser *browser.API, s *Style) *searchModel {
+func newSearchModel(ctx context.Context, cfg *config.Value, browser browser.Lists, directory browser.StationDirectory, s *Style) *searchModel {
 	k := newSearchKeymap()
 	inputs := []textinput.Model{
 		s.NewInputModel("Name          ", "leave empty for all", &k.prevSugg, &k.nextSugg, &k.acceptSugg, nil),
@@ -101,9 +139,24 @@ func newSearchModel(ctx context.Context, browser *browser.API, s *Style) *search
 		s.NewInputModel("Language      ", "---", &k.prevSugg, &k.nextSugg, &k.acceptSugg, nil),
 		s.NewInputModel("Limit         ", "---", &k.prevSugg, &k.nextSugg, &k.acceptSugg, NrInputValidator),
 	}
-	formElems := make([]FormElement, len(inputs))
+	advInputs := []textinput.Model{
+		s.NewInputModel("Country code  ", "e.g. DE", &k.prevSugg, &k.nextSugg, &k.acceptSugg, countryCodeValidator),
+		s.NewInputModel("Codec         ", "e.g. AAC,MP3", &k.prevSugg, &k.nextSugg, &k.acceptSugg, nil),
+		s.NewInputModel("Bitrate min   ", "kbps", &k.prevSugg, &k.nextSugg, &k.acceptSugg, NrInputValidator),
+		s.NewInputModel("Bitrate max   ", "kbps", &k.prevSugg, &k.nextSugg, &k.acceptSugg, NrInputValidator),
+	}
+	formElems := make([]FormElement, 0, hasExtendedInfo+1)
 	for ii := range inputs {
-		formElems[ii] = *NewFormElement(WithTextInput(&inputs[ii]))
+		formElems = append(formElems, *NewFormElement(WithTextInput(&inputs[ii])))
+	}
+	for _, label := range []string{"Exact name    ", "Exact tags    ", "Exact country "} {
+		formElems = append(formElems, *NewFormElement(WithCheckbox(NewCheckbox(label, false, s))))
+	}
+	for ii := range advInputs {
+		formElems = append(formElems, *NewFormElement(WithTextInput(&advInputs[ii])))
+	}
+	for _, label := range []string{"HTTPS only    ", "Has geo info  ", "Extended info "} {
+		formElems = append(formElems, *NewFormElement(WithCheckbox(NewCheckbox(label, false, s))))
 	}
 	h := help.New()
 	h.ShowAll = false
@@ -114,20 +167,27 @@ func newSearchModel(ctx context.Context, browser *browser.API, s *Style) *search
 	orderOpts.SetQuick(true)
 	reverseCheckbox := NewFormElement(WithCheckbox(NewCheckbox("Reverse       ", true, s)))
 	sm := &searchModel{
+		ctx:          ctx,
+		cfg:          cfg,
+		cancelSearch: func() {},
 		browser:      browser,
+		directory:    directory,
 		keymap:       k,
 		help:         h,
 		textInputs:   formElems,
 		orderOptions: orderOpts,
 		style:        s,
 		reverse:      *reverseCheckbox,
+		picksKeymap:  newSearchPicksKeymap(),
+		nameInput:    s.NewInputModel(searchNamePrompt, searchNameHint, nil, nil, nil, nil),
+		namingKeymap: newSearchNamingKeymap(),
 	}
-	go sm.getSuggestions()
+	go sm.getSuggestions(ctx)
 	return sm
 }
 
-func (s *searchModel) getSuggestions() {
-	countries, err := s.browser.GetCountries()
+func (s *searchModel) getSuggestions(ctx context.Context) {
+	countries, err := s.browser.GetCountries(ctx)
 	if err == nil && len(countries) > 0 {
 		for i := range countries {
 			s.countries = append(s.countries, countries[i].Name)
@@ -136,7 +196,7 @@ func (s *searchModel) getSuggestions() {
 		s.textInputs[country].TextInput().SetSuggestions(s.countries)
 	}
 
-	langs, err := s.browser.GetLanguages()
+	langs, err := s.browser.GetLanguages(ctx)
 	if err == nil && len(langs) > 0 {
 		for i := range langs {
 			s.languages = append(s.languages, langs[i].Name)
@@ -144,12 +204,34 @@ func (s *searchModel) getSuggestions() {
 		s.textInputs[language].TextInput().ShowSuggestions = true
 		s.textInputs[language].TextInput().SetSuggestions(s.languages)
 	}
+
+	codecs, err := s.browser.GetCodecs(ctx)
+	if err == nil && len(codecs) > 0 {
+		for i := range codecs {
+			s.codecs = append(s.codecs, codecs[i].Name)
+		}
+		s.textInputs[codec].TextInput().ShowSuggestions = true
+		s.textInputs[codec].TextInput().SetSuggestions(s.codecs)
+	}
+}
+
+func countryCodeValidator(s string) error {
+	if len(s) > 2 {
+		return fmt.Errorf("country code too long: %q", s)
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return fmt.Errorf("invalid country code: %q", s)
+		}
+	}
+	return nil
 }
 
 func (s *searchModel) Init() tea.Cmd {
 	s.setEnabled(true)
 	s.keymap.prevInput.SetHelp("↑/ctrl+k", "prev input")
 	s.keymap.nextInput.SetHelp("↓/ctrl+j", "next input")
+	s.loadPicks()
 	return s.textInputs[0].Focus()
 }
 
@@ -170,17 +252,32 @@ func (s *searchModel) setEnabled(v bool) {
 	s.idx = name
 	for i := range s.textInputs {
 		s.textInputs[i].Blur()
+		if s.textInputs[i].Checkbox() != nil {
+			s.textInputs[i].SetValue(false)
+			continue
+		}
 		s.textInputs[i].TextInput().Reset()
 	}
 	s.textInputs[limit].SetValue(fmt.Sprintf("%d", browser.DefLimit))
+	s.textInputs[tagsExact].SetValue(true)
+	s.showAdvanced = false
+	s.keymap.advanced.SetHelp("ctrl+t", "advanced filters")
 	if !v {
 		s.orderOptions.SetIdx(0)
 	}
 	s.oIdx = orderVotes
 	s.reverse.SetValue(true)
+	s.picking = false
+	s.naming = false
+	s.savedName = ""
+	s.pickIdx = 0
+	s.nameInput.Reset()
+	s.nameInput.Blur()
 	showAll := false
 	s.help.ShowAll = showAll
 	s.keymap.setEnable(v, showAll)
+	s.picksKeymap.setEnable(false)
+	s.namingKeymap.setEnable(false)
 }
 
 func (s *searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -193,10 +290,17 @@ func (s *searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		s.setSize(msg.Width, msg.Height)
+	if sizeMsg, ok := msg.(tea.WindowSizeMsg); ok {
+		s.setSize(sizeMsg.Width, sizeMsg.Height)
+	}
+	if s.naming {
+		return s, s.updateNaming(msg)
+	}
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && s.picking {
+		return s, s.updatePicks(keyMsg)
+	}
 
+	switch msg := msg.(type) {
 	case OptionMsg:
 		if msg.Done {
 			s.orderOptions.SetFocused(false)
@@ -230,49 +334,50 @@ func (s *searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, s.keymap.reverse):
 			s.reverse.Checkbox().Toggle()
 
+		case key.Matches(msg, s.keymap.picks):
+			return s, s.setPicking(true)
+
+		case key.Matches(msg, s.keymap.save):
+			return s, s.setNaming(true)
+
+		case key.Matches(msg, s.keymap.advanced):
+			s.showAdvanced = !s.showAdvanced
+			if s.showAdvanced {
+				s.keymap.advanced.SetHelp("ctrl+t", "basic filters")
+			} else {
+				s.keymap.advanced.SetHelp("ctrl+t", "advanced filters")
+				if s.idx > limit {
+					s.idx = limit
+					cmds = s.updateInputs(cmds)
+				}
+			}
+			return s, tea.Batch(cmds...)
+
+		case key.Matches(msg, s.keymap.toggle) && s.textInputs[s.idx].Checkbox() != nil:
+			s.textInputs[s.idx].Checkbox().Toggle()
+			return s, tea.Batch(cmds...)
+
 		case key.Matches(msg, s.keymap.cancel):
+			s.cancelSearch()
 			return s, func() tea.Msg {
 				s.setEnabled(false)
 				return searchRespMsg{cancelled: true}
 			}
 
 		case key.Matches(msg, s.keymap.submit):
-			return s, func() tea.Msg {
-				defer s.setEnabled(false)
-
-				params := browser.DefaultSearchParams()
-				params.Name = strings.TrimSpace(s.textInputs[name].Value())
-				params.TagList = strings.TrimSpace(s.textInputs[tags].Value())
-				params.Country = strings.Title(strings.TrimSpace(s.textInputs[country].Value()))
-				params.Language = strings.TrimSpace(s.textInputs[language].Value())
-				limit, err := strconv.Atoi(strings.TrimSpace(s.textInputs[limit].Value()))
-				if err == nil {
-					params.Limit = limit
-				}
-				params.Order = s.oIdx.toSearchOrder()
-				params.Reverse = s.reverse.Checkbox().Value()
-
-				stations, err := s.browser.Search(params)
-				res := searchRespMsg{stations: stations}
-				if err != nil {
-					res.statusMsg = statusMsg(err.Error())
-				} else if len(stations) == 0 {
-					res.viewMsg = noStationsFound
-				}
-				return res
-			}
+			return s, s.submit(s.formParams())
 
 		case key.Matches(msg, s.keymap.nextInput):
-			if msg.String() == "tab" && strings.TrimSpace(s.textInputs[s.idx].Value()) != "" && s.textInputs[s.idx].TextInput().ShowSuggestions {
-				s.textInputs[s.idx].SetValue(s.textInputs[s.idx].TextInput().CurrentSuggestion())
+			if ti := s.textInputs[s.idx].TextInput(); msg.String() == "tab" && ti != nil && strings.TrimSpace(ti.Value()) != "" && ti.ShowSuggestions {
+				s.textInputs[s.idx].SetValue(ti.CurrentSuggestion())
 				s.textInputs[s.idx].TextInput().CursorEnd()
 			}
 			s.idx++
-			s.idx = s.idx % inputIdx(len(s.textInputs))
+			s.idx = s.idx % s.inputsLen()
 			cmds = s.updateInputs(cmds)
 		case key.Matches(msg, s.keymap.prevInput):
 			if s.idx == 0 {
-				s.idx = limit
+				s.idx = s.inputsLen()
 			}
 			s.idx--
 			cmds = s.updateInputs(cmds)
@@ -280,6 +385,10 @@ func (s *searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	for i := range s.textInputs {
+		if s.textInputs[i].Checkbox() != nil {
+			// toggled only when focused, see keymap.toggle
+			continue
+		}
 		var cmd tea.Cmd
 		fEl, cmd := s.textInputs[i].Update(msg)
 		s.textInputs[i] = *fEl
@@ -289,6 +398,118 @@ func (s *searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return s, tea.Batch(cmds...)
 }
 
+// submit records the search in the history and runs it.
+func (s *searchModel) submit(params browser.SearchParams) tea.Cmd {
+	s.cfg.AddSearchHistory(encodeSearchParams(params))
+
+	s.cancelSearch()
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancelSearch = cancel
+	return func() tea.Msg {
+		defer cancel()
+		defer s.setEnabled(false)
+
+		stations, err := s.directory.Search(ctx, params)
+		if errors.Is(err, context.Canceled) {
+			return searchRespMsg{cancelled: true}
+		}
+		res := searchRespMsg{stations: stations}
+		if err != nil {
+			res.statusMsg = statusMsg(err.Error())
+		} else if len(stations) == 0 {
+			res.viewMsg = noStationsFound
+		} else {
+			res.params = &params
+			res.source = s.directory
+		}
+		return res
+	}
+}
+
+// formParams returns the search params of the form inputs.
+func (s *searchModel) formParams() browser.SearchParams {
+	params := browser.DefaultSearchParams()
+	params.Name = strings.TrimSpace(s.textInputs[name].Value())
+	params.TagList = strings.TrimSpace(s.textInputs[tags].Value())
+	params.Country = strings.Title(strings.TrimSpace(s.textInputs[country].Value()))
+	params.Language = strings.TrimSpace(s.textInputs[language].Value())
+	limit, err := strconv.Atoi(strings.TrimSpace(s.textInputs[limit].Value()))
+	if err == nil {
+		params.Limit = limit
+	}
+	params.Order = s.oIdx.toSearchOrder()
+	params.Reverse = s.reverse.Checkbox().Value()
+	s.setAdvancedParams(&params)
+	return params
+}
+
+// setParams fills the form inputs with the search params.
+func (s *searchModel) setParams(params browser.SearchParams) {
+	s.textInputs[name].SetValue(params.Name)
+	s.textInputs[tags].SetValue(params.TagList)
+	s.textInputs[country].SetValue(params.Country)
+	s.textInputs[language].SetValue(params.Language)
+	s.textInputs[limit].SetValue(strconv.Itoa(cmp.Or(params.Limit, browser.DefLimit)))
+	for ix, order := range searchOrder {
+		if order == params.Order {
+			s.oIdx = ix
+			s.orderOptions.SetIdx(int(ix))
+		}
+	}
+	s.reverse.SetValue(params.Reverse)
+
+	s.textInputs[nameExact].SetValue(params.NameExact)
+	s.textInputs[tagsExact].SetValue(params.TagExact)
+	s.textInputs[countryExact].SetValue(params.CountryExact)
+	s.textInputs[countryCode].SetValue(params.CountryCode)
+	s.textInputs[codec].SetValue(params.Codec)
+	s.textInputs[bitrateMin].SetValue("")
+	if params.BitrateMin > 0 {
+		s.textInputs[bitrateMin].SetValue(strconv.Itoa(params.BitrateMin))
+	}
+	s.textInputs[bitrateMax].SetValue("")
+	if params.BitrateMax > 0 {
+		s.textInputs[bitrateMax].SetValue(strconv.Itoa(params.BitrateMax))
+	}
+	s.textInputs[httpsOnly].SetValue(params.IsHTTPS)
+	s.textInputs[hasGeoInfo].SetValue(params.HasGeoInfo)
+	s.textInputs[hasExtendedInfo].SetValue(params.HasExtendedInfo)
+
+	advanced := params.NameExact || params.TagExact != browser.DefaultSearchParams().TagExact ||
+		params.CountryExact || params.CountryCode != "" || params.Codec != "" ||
+		params.BitrateMin > 0 || params.BitrateMax > 0 ||
+		params.IsHTTPS || params.HasGeoInfo || params.HasExtendedInfo
+	if advanced {
+		s.showAdvanced = true
+		s.keymap.advanced.SetHelp("ctrl+t", "basic filters")
+	}
+}
+
+// inputsLen returns the number of visible inputs.
+func (s *searchModel) inputsLen() inputIdx {
+	if s.showAdvanced {
+		return inputIdx(len(s.textInputs))
+	}
+	return limit + 1
+}
+
+func (s *searchModel) setAdvancedParams(params *browser.SearchParams) {
+	params.NameExact = s.textInputs[nameExact].Checkbox().Value()
+	params.TagExact = s.textInputs[tagsExact].Checkbox().Value()
+	params.CountryExact = s.textInputs[countryExact].Checkbox().Value()
+	params.CountryCode = strings.ToUpper(strings.TrimSpace(s.textInputs[countryCode].Value()))
+	params.Codec = strings.TrimSpace(s.textInputs[codec].Value())
+	if v, err := strconv.Atoi(strings.TrimSpace(s.textInputs[bitrateMin].Value())); err == nil {
+		params.BitrateMin = v
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(s.textInputs[bitrateMax].Value())); err == nil {
+		params.BitrateMax = v
+	}
+	params.IsHTTPS = s.textInputs[httpsOnly].Checkbox().Value()
+	params.HasGeoInfo = s.textInputs[hasGeoInfo].Checkbox().Value()
+	params.HasExtendedInfo = s.textInputs[hasExtendedInfo].Checkbox().Value()
+}
+
 func (s *searchModel) updateInputs(cmds []tea.Cmd) []tea.Cmd {
 	for i := range s.textInputs {
 		if !s.orderOptions.IsActive() && i == int(s.idx) {
@@ -302,7 +523,10 @@ func (s *searchModel) updateInputs(cmds []tea.Cmd) []tea.Cmd {
 
 func (s *searchModel) View() string {
 	var b strings.Builder
-	for i := range s.textInputs {
+	for i := range s.inputsLen() {
+		if i == nameExact {
+			b.WriteRune('\n')
+		}
 		b.WriteString(s.textInputs[i].View())
 		b.WriteRune('\n')
 	}
@@ -313,18 +537,32 @@ func (s *searchModel) View() string {
 	b.WriteRune('\n')
 
 	b.WriteString(s.reverse.View())
+	if s.naming {
+		b.WriteString("\n\n")
+		b.WriteString(s.nameInput.View())
+	}
 
 	availHeight := s.height
 	var help string
-	if !s.orderOptions.IsActive() {
-		help = s.style.HelpStyle.Render(s.help.View(&s.keymap))
-	} else {
+	switch {
+	case s.orderOptions.IsActive():
 		help = s.style.HelpStyle.Render(s.help.View(&s.orderOptions.Keymap))
+	case s.picking:
+		help = s.style.HelpStyle.Render(s.help.View(&s.picksKeymap))
+	case s.naming:
+		help = s.style.HelpStyle.Render(s.help.View(&s.namingKeymap))
+	default:
+		help = s.style.HelpStyle.Render(s.help.View(&s.keymap))
 	}
 	availHeight -= lipgloss.Height(help)
 
-	inputs := b.String()
-	inputsHeight := lipgloss.Height(inputs)
+	inputsHeight := lipgloss.Height(b.String())
+	if picksHeight := availHeight - inputsHeight - 2; picksHeight > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(s.picksView(picksHeight))
+	}
+
+	inputsHeight = lipgloss.Height(b.String())
 	for i := 0; i < availHeight-inputsHeight; i++ {
 		b.WriteString("\n")
 	}
@@ -338,6 +576,10 @@ type searchKeymap struct {
 	prevInput     key.Binding
 	order         key.Binding
 	reverse       key.Binding
+	advanced      key.Binding
+	picks         key.Binding
+	save          key.Binding
+	toggle        key.Binding
 	prevSugg      key.Binding
 	nextSugg      key.Binding
 	acceptSugg    key.Binding
@@ -371,6 +613,22 @@ func newSearchKeymap() searchKeymap {
 			key.WithKeys("ctrl+r"),
 			key.WithHelp("ctrl+r", "reverse"),
 		),
+		advanced: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "advanced filters"),
+		),
+		picks: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "quick picks"),
+		),
+		save: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "save search"),
+		),
+		toggle: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "toggle checkbox"),
+		),
 		prevSugg: key.NewBinding(
 			key.WithKeys("ctrl+p", "ctrl+up"),
 			key.WithHelp("ctrl+↑/ctrl+p", "prev suggestion"),
@@ -396,7 +654,7 @@ func newSearchKeymap() searchKeymap {
 }
 
 func (k *searchKeymap) ShortHelp() []key.Binding {
-	return []key.Binding{k.prevInput, k.nextInput, k.order, k.reverse, k.submit, k.cancel, k.showFullHelp}
+	return []key.Binding{k.prevInput, k.nextInput, k.order, k.reverse, k.advanced, k.picks, k.submit, k.cancel, k.showFullHelp}
 }
 
 func (k *searchKeymap) FullHelp() [][]key.Binding {
@@ -404,6 +662,8 @@ func (k *searchKeymap) FullHelp() [][]key.Binding {
 		{k.prevInput, k.nextInput},
 		{k.prevSugg, k.nextSugg, k.acceptSugg},
 		{k.order, k.reverse},
+		{k.advanced, k.toggle},
+		{k.picks, k.save},
 		{k.submit, k.cancel, k.closeFullHelp},
 	}
 }
@@ -415,6 +675,10 @@ func (k *searchKeymap) setEnable(enabled bool, showAll bool) {
 	k.nextInput.SetEnabled(enabled)
 	k.order.SetEnabled(enabled)
 	k.reverse.SetEnabled(enabled)
+	k.advanced.SetEnabled(enabled)
+	k.picks.SetEnabled(enabled)
+	k.save.SetEnabled(enabled)
+	k.toggle.SetEnabled(enabled)
 	k.prevSugg.SetEnabled(enabled)
 	k.nextSugg.SetEnabled(enabled)
 	k.acceptSugg.SetEnabled(enabled)
@@ -0,0 +1,336 @@
+package ui
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dancnb/sonicradio/browser"
+	"github.com/dancnb/sonicradio/config"
+)
+
+const (
+	savedSearchesTitle  = "Saved searches"
+	recentSearchesTitle = "Recent searches"
+	noSearchPicks       = "No saved or recent searches yet."
+	searchNamePrompt    = "Save as        "
+	searchNameHint      = "name"
+	pinnedSearchChar    = " [tab]"
+)
+
+// searchPick is a saved search, or a search of the history if name is empty.
+type searchPick struct {
+	name   string
+	pinned bool
+	params browser.SearchParams
+}
+
+// loadPicks lists the saved searches followed by the search history.
+func (s *searchModel) loadPicks() {
+	log := slog.With("method", "ui.searchModel.loadPicks")
+	s.picks = s.picks[:0]
+	for _, ss := range s.cfg.Searches {
+		p, err := decodeSearchParams(ss.Params)
+		if err != nil {
+			log.Info("invalid saved search", "name", ss.Name, "err", err)
+			continue
+		}
+		s.picks = append(s.picks, searchPick{name: ss.Name, pinned: ss.Pinned, params: p})
+	}
+	for _, raw := range s.cfg.SearchHistory {
+		p, err := decodeSearchParams(raw)
+		if err != nil {
+			log.Info("invalid search history entry", "err", err)
+			continue
+		}
+		s.picks = append(s.picks, searchPick{params: p})
+	}
+	s.pickIdx = min(s.pickIdx, max(len(s.picks)-1, 0))
+}
+
+func decodeSearchParams(raw json.RawMessage) (browser.SearchParams, error) {
+	p := browser.DefaultSearchParams()
+	err := json.Unmarshal(raw, &p)
+	return p, err
+}
+
+func encodeSearchParams(p browser.SearchParams) json.RawMessage {
+	p.Offset = 0
+	b, _ := json.Marshal(p)
+	return b
+}
+
+// setPicking shows the quick picks instead of the form inputs.
+func (s *searchModel) setPicking(v bool) tea.Cmd {
+	s.picking = v
+	s.keymap.setEnable(!v, s.help.ShowAll)
+	s.picksKeymap.setEnable(v)
+	if v {
+		s.loadPicks()
+		s.updatePicksKeymap()
+		for i := range s.textInputs {
+			s.textInputs[i].Blur()
+		}
+		return nil
+	}
+	return s.textInputs[s.idx].Focus()
+}
+
+// setNaming prompts for the name of the saved search.
+func (s *searchModel) setNaming(v bool) tea.Cmd {
+	s.naming = v
+	s.keymap.setEnable(!v, s.help.ShowAll)
+	s.namingKeymap.setEnable(v)
+	if v {
+		for i := range s.textInputs {
+			s.textInputs[i].Blur()
+		}
+		s.nameInput.Placeholder = searchNameHint
+		s.nameInput.SetValue(s.savedName)
+		s.nameInput.CursorEnd()
+		return s.nameInput.Focus()
+	}
+	s.nameInput.Blur()
+	return s.textInputs[s.idx].Focus()
+}
+
+func (s *searchModel) updatePicksKeymap() {
+	saved := len(s.picks) > 0 && s.picks[s.pickIdx].name != ""
+	s.picksKeymap.pin.SetEnabled(saved)
+	s.picksKeymap.remove.SetEnabled(saved)
+	s.picksKeymap.choose.SetEnabled(len(s.picks) > 0)
+	s.picksKeymap.edit.SetEnabled(len(s.picks) > 0)
+	if saved && s.picks[s.pickIdx].pinned {
+		s.picksKeymap.pin.SetHelp("p", "unpin tab")
+	} else {
+		s.picksKeymap.pin.SetHelp("p", "pin as tab")
+	}
+}
+
+func (s *searchModel) updatePicks(msg tea.KeyMsg) tea.Cmd {
+	k := s.picksKeymap
+	switch {
+	case key.Matches(msg, k.back):
+		return s.setPicking(false)
+
+	case key.Matches(msg, k.up):
+		if len(s.picks) > 0 {
+			s.pickIdx = (s.pickIdx - 1 + len(s.picks)) % len(s.picks)
+		}
+
+	case key.Matches(msg, k.down):
+		if len(s.picks) > 0 {
+			s.pickIdx = (s.pickIdx + 1) % len(s.picks)
+		}
+
+	case key.Matches(msg, k.choose, k.edit):
+		pick := s.picks[s.pickIdx]
+		s.setParams(pick.params)
+		s.savedName = pick.name
+		cmd := s.setPicking(false)
+		if key.Matches(msg, k.edit) {
+			return cmd
+		}
+		return s.submit(pick.params)
+
+	case key.Matches(msg, k.pin):
+		pick := s.picks[s.pickIdx]
+		s.cfg.PinSearch(pick.name, !pick.pinned)
+		s.loadPicks()
+		s.updatePicksKeymap()
+		return func() tea.Msg { return pinnedSearchesMsg{} }
+
+	case key.Matches(msg, k.remove):
+		pick := s.picks[s.pickIdx]
+		s.cfg.RemoveSearch(pick.name)
+		s.loadPicks()
+		s.updatePicksKeymap()
+		if pick.pinned {
+			return func() tea.Msg { return pinnedSearchesMsg{} }
+		}
+		return nil
+	}
+	s.updatePicksKeymap()
+	return nil
+}
+
+func (s *searchModel) updateNaming(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, s.namingKeymap.cancel):
+			return s.setNaming(false)
+
+		case key.Matches(msg, s.namingKeymap.save):
+			name := strings.TrimSpace(s.nameInput.Value())
+			if err := s.cfg.SaveSearch(name, encodeSearchParams(s.formParams())); err != nil {
+				s.nameInput.Placeholder = err.Error()
+				return nil
+			}
+			s.savedName = name
+			cmd := s.setNaming(false)
+			if ss := s.cfg.SavedSearch(name); ss != nil && ss.Pinned {
+				return tea.Batch(cmd, func() tea.Msg { return pinnedSearchesMsg{} })
+			}
+			return cmd
+		}
+	}
+	var cmd tea.Cmd
+	s.nameInput, cmd = s.nameInput.Update(msg)
+	return cmd
+}
+
+// picksView renders the quick picks around the selected one, within height
+// lines.
+func (s *searchModel) picksView(height int) string {
+	if len(s.picks) == 0 {
+		return s.style.SecondaryColorStyle.Render(noSearchPicks)
+	}
+
+	var lines []string
+	selLine := 0
+	for i, p := range s.picks {
+		if i == 0 && p.name != "" {
+			lines = append(lines, s.style.HistoryDescStyle.Render(savedSearchesTitle))
+		}
+		if p.name == "" && (i == 0 || s.picks[i-1].name != "") {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, s.style.HistoryDescStyle.Render(recentSearchesTitle))
+		}
+
+		itStyle := s.style.SecondaryColorStyle
+		if i == s.pickIdx {
+			selLine = len(lines)
+			if s.picking {
+				itStyle = s.style.HistorySelItemStyle
+			}
+		}
+		text := p.params.Summary()
+		if p.name != "" {
+			text = p.name
+			if p.pinned {
+				text += pinnedSearchChar
+			}
+			text += " · " + p.params.Summary()
+		}
+		lines = append(lines, s.style.PrefixStyle.Render("  ")+itStyle.MaxWidth(max(s.width-3, 0)).Render(text))
+	}
+
+	height = max(height, 1)
+	start := 0
+	if len(lines) > height {
+		start = min(max(selLine-height/2, 0), len(lines)-height)
+		lines = lines[start : start+height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type searchPicksKeymap struct {
+	up     key.Binding
+	down   key.Binding
+	choose key.Binding
+	edit   key.Binding
+	pin    key.Binding
+	remove key.Binding
+	back   key.Binding
+}
+
+func newSearchPicksKeymap() searchPicksKeymap {
+	return searchPicksKeymap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k", "ctrl+k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j", "ctrl+j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		choose: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "search"),
+		),
+		edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit"),
+		),
+		pin: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pin as tab"),
+		),
+		remove: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete saved search"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc", "ctrl+g"),
+			key.WithHelp("esc", "back to form"),
+		),
+	}
+}
+
+func (k *searchPicksKeymap) setEnable(v bool) {
+	k.up.SetEnabled(v)
+	k.down.SetEnabled(v)
+	k.choose.SetEnabled(v)
+	k.edit.SetEnabled(v)
+	k.pin.SetEnabled(v)
+	k.remove.SetEnabled(v)
+	k.back.SetEnabled(v)
+}
+
+func (k *searchPicksKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.up, k.down, k.choose, k.edit, k.pin, k.remove, k.back}
+}
+
+func (k *searchPicksKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+type searchNamingKeymap struct {
+	save   key.Binding
+	cancel key.Binding
+}
+
+func newSearchNamingKeymap() searchNamingKeymap {
+	return searchNamingKeymap{
+		save: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "save search"),
+		),
+		cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+	}
+}
+
+func (k *searchNamingKeymap) setEnable(v bool) {
+	k.save.SetEnabled(v)
+	k.cancel.SetEnabled(v)
+}
+
+func (k *searchNamingKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.save, k.cancel}
+}
+
+func (k *searchNamingKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// savedSearchParams returns the params of the saved search, false if it was
+// removed or can't be decoded.
+func savedSearchParams(cfg *config.Value, name string) (browser.SearchParams, bool) {
+	ss := cfg.SavedSearch(name)
+	if ss == nil {
+		return browser.SearchParams{}, false
+	}
+	p, err := decodeSearchParams(ss.Params)
+	if err != nil {
+		slog.Info("invalid saved search", "name", name, "err", err)
+		return browser.SearchParams{}, false
+	}
+	return p, true
+}
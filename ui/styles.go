@@ -16,11 +16,20 @@ const (
 	TabGapDistance = 2
 	HeaderPadDist  = 2
 
-	FavChar      = "  ★"
-	AutoplayChar = " Auto"
-	PlayChar     = "\u2877"
-	PauseChar    = "\u28FF"
-	LineChar     = "\u2847"
+	FavChar       = "  ★"
+	AutoplayChar  = " Auto"
+	WarningChar   = " ⚠"
+	VotedChar     = " ✓"
+	VariantChar   = "↳ "
+	VariantsFmt   = " +%d"
+	NowPlayingFmt = "♪ %s ┃ %s"
+	PlayChar      = "\u2877"
+	PauseChar     = "\u28FF"
+	LineChar      = "\u2847"
+
+	ScrubberPlayedChar   = "━"
+	ScrubberPosChar      = "●"
+	ScrubberBufferedChar = "─"
 )
 
 type Style struct {
@@ -25,6 +25,8 @@ func (t uiTabIndex) String() string {
 		return "  Browse  "
 	case historyTabIx:
 		return "  History  "
+	case podcastsTabIx:
+		return " Podcasts "
 	case settingsTabIx:
 		return " Settings "
 	}
@@ -35,6 +37,7 @@ const (
 	favoriteTabIx uiTabIndex = iota
 	browseTabIx
 	historyTabIx
+	podcastsTabIx
 	settingsTabIx
 )
 
@@ -54,6 +57,7 @@ type stationTab interface {
 	Stations() *stationsTabBase
 	IsSearchEnabled() bool
 	IsCustomStationEnabled() bool
+	IsCategoryEnabled() bool
 	IsInfoEnabled() bool
 	createList(delegate *stationDelegate, width int, height int) list.Model
 }
@@ -142,6 +146,10 @@ func (t *stationsTabBase) IsCustomStationEnabled() bool {
 	return false
 }
 
+func (t *stationsTabBase) IsCategoryEnabled() bool {
+	return false
+}
+
 func (t *stationsTabBase) IsInfoEnabled() bool {
 	return t.infoModel != nil && t.infoModel.enabled
 }
@@ -2,44 +2,94 @@ package ui
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dancnb/sonicradio/browser"
+	"github.com/dancnb/sonicradio/config"
 	"github.com/dancnb/sonicradio/model"
 )
 
+// pageThreshold is the distance from the end of the list at which the next
+// page of results is loaded.
+const pageThreshold = 5
+
+// recommendedLimit is the number of recommended stations.
+const recommendedLimit = 50
+
 type browseTab struct {
 	stationsTabBase
+	cfg            *config.Value
 	defTopStations []model.Station
 	searchModel    *searchModel
+	categoryModel  *categoryModel
+	// feed is the last loaded discovery feed
+	feed browser.Feed
+
+	// page is the params of the last loaded page, nil if there are no more
+	page *browser.SearchParams
+	// pageSource loads the next pages, from the source of the first one
+	pageSource  browser.StationDirectory
+	pageLoading bool
+	// pageGen discards the pages of previous results
+	pageGen int
+
+	// stations is the ungrouped list content
+	stations []model.Station
+	// grouping the list was built with
+	grouping config.Grouping
+	// expanded groups by the ID of their default station
+	expanded map[string]bool
 }
 
-func newBrowseTab(ctx context.Context, browser *browser.API, infoModel *infoModel, s *Style) *browseTab {
+func newBrowseTab(
+	ctx context.Context,
+	cfg *config.Value,
+	browser browser.Lists,
+	directory browser.StationDirectory,
+	providers []browser.Provider,
+	infoModel *infoModel,
+	s *Style,
+) *browseTab {
 	k := newListKeymap()
 
 	m := &browseTab{
 		stationsTabBase: newStationsTab(k, infoModel, s),
-		searchModel:     newSearchModel(ctx, browser, s),
+		cfg:             cfg,
+		grouping:        cfg.Grouping,
+		expanded:        make(map[string]bool),
+		searchModel:     newSearchModel(ctx, cfg, browser, directory, s),
+		categoryModel:   newCategoryModel(ctx, browser, directory, providers, s),
 	}
 	return m
 }
 
 func (t *browseTab) createList(delegate *stationDelegate, width int, height int) list.Model {
 	l := createList(delegate, width, height)
+	l.StatusMessageLifetime = time.Minute
 	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{t.listKeymap.search}
+		return []key.Binding{t.listKeymap.search, t.listKeymap.categories}
 	}
 	l.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			t.listKeymap.search,
+			t.listKeymap.categories,
+			t.listKeymap.nearby,
+			t.listKeymap.recommended,
+			t.listKeymap.nextFeed,
+			t.listKeymap.reloadFeed,
+			t.listKeymap.variants,
 			t.listKeymap.digitHelp,
 			t.listKeymap.toNowPlaying,
 			t.listKeymap.prevTab,
 			t.listKeymap.nextTab,
 			t.listKeymap.favoritesTab,
 			t.listKeymap.historyTab,
+			t.listKeymap.podcastsTab,
 			t.listKeymap.settingsTab,
 			t.listKeymap.stationView,
 		}
@@ -67,6 +117,18 @@ func (t *browseTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		sm, cmd := t.searchModel.Update(searchModelMsg)
 		t.searchModel = sm.(*searchModel)
 		cmds = append(cmds, cmd)
+	} else if t.IsCategoryEnabled() {
+		categoryModelMsg := msg
+		if sizeMsg, ok := msg.(tea.WindowSizeMsg); ok {
+			categoryModelMsg = t.newSizeMsg(sizeMsg, m)
+		}
+		var cmd tea.Cmd
+		t.categoryModel, cmd = t.categoryModel.Update(categoryModelMsg)
+		cmds = append(cmds, cmd)
+		if _, ok := msg.(tea.KeyMsg); ok {
+			// the key may have closed the categories
+			return m, tea.Batch(cmds...)
+		}
 	} else if t.IsInfoEnabled() {
 		infoModelMsg := msg
 		if sizeMsg, ok := msg.(tea.WindowSizeMsg); ok {
@@ -88,6 +150,7 @@ func (t *browseTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		copy(t.defTopStations, msg.stations)
 		cmd := t.setStations(msg.stations)
 		cmds = append(cmds, cmd)
+		t.setPage(msg.params, msg.source, len(msg.stations))
 
 	case playHistoryEntryMsg:
 		s, idx := t.getListStationByUUID(msg.uuid)
@@ -101,12 +164,30 @@ func (t *browseTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateStatus(string(msg.statusMsg))
 		t.viewMsg = string(msg.viewMsg)
 		if len(msg.stations) > 0 {
+			t.setPage(nil, nil, 0)
 			return m, tea.Sequence(
 				t.setStations(msg.stations),
 				m.playStationCmd(msg.stations[0]),
 			)
 		}
 
+	case categoryEntriesMsg:
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			m.updateStatus(msg.err.Error())
+		}
+		if !t.IsCategoryEnabled() {
+			// entries loaded after closing the categories
+			t.categoryModel, _ = t.categoryModel.Update(msg)
+		}
+
+	case opmlMenuMsg:
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			m.updateStatus(msg.err.Error())
+		}
+		if !t.IsCategoryEnabled() {
+			t.categoryModel, _ = t.categoryModel.Update(msg)
+		}
+
 	case searchRespMsg:
 		t.listKeymap.setEnabled(true)
 		if msg.cancelled {
@@ -116,8 +197,23 @@ func (t *browseTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 			t.viewMsg = string(msg.viewMsg)
 			cmd := t.setStations(msg.stations)
 			cmds = append(cmds, cmd)
+			t.setPage(msg.params, msg.source, len(msg.stations))
 		}
 
+	case pageRespMsg:
+		if msg.gen != t.pageGen {
+			return m, nil
+		}
+		t.pageLoading = false
+		t.list.SetShowStatusBar(false)
+		if msg.statusMsg != "" {
+			m.updateStatus(string(msg.statusMsg))
+			return m, nil
+		}
+		cmds = append(cmds, t.appendStations(msg.stations))
+		t.setPage(&msg.params, t.pageSource, len(msg.stations))
+		return m, tea.Batch(cmds...)
+
 	case toggleInfoMsg:
 		if msg.enable {
 			cmds = append(cmds, t.initInfoModel(m, msg))
@@ -127,7 +223,7 @@ func (t *browseTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
-		if t.IsSearchEnabled() || t.IsInfoEnabled() {
+		if t.IsSearchEnabled() || t.IsCategoryEnabled() || t.IsInfoEnabled() {
 			return m, tea.Batch(cmds...)
 		}
 
@@ -147,17 +243,73 @@ func (t *browseTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case key.Matches(msg, t.listKeymap.search):
+			// the configured directories are searched offline
+			if !m.browser.Online() && len(m.providers) == 0 {
+				m.updateStatus(searchOffline)
+				return m, nil
+			}
 			t.listKeymap.setEnabled(false)
 			t.searchModel.setSize(m.width, m.totHeight-m.headerHeight)
 			cmds = append(cmds, t.searchModel.Init())
 			return m, tea.Batch(cmds...)
 
+		case key.Matches(msg, t.listKeymap.categories):
+			if !m.browser.Online() && len(m.providers) == 0 {
+				m.updateStatus(categoriesOffline)
+				return m, nil
+			}
+			t.listKeymap.setEnabled(false)
+			t.categoryModel.setSize(m.width, m.totHeight-m.headerHeight)
+			cmds = append(cmds, t.categoryModel.Init())
+			return m, tea.Batch(cmds...)
+
+		case key.Matches(msg, t.listKeymap.nearby):
+			if !m.browser.Online() {
+				m.updateStatus(nearbyOffline)
+				return m, nil
+			}
+			p, err := m.cfg.Location.Point()
+			if err != nil {
+				m.updateStatus(err.Error())
+				return m, nil
+			}
+			t.viewMsg = loadingMsg
+			t.setPage(nil, nil, 0)
+			return m, m.nearbyStationsCmd(p)
+
+		case key.Matches(msg, t.listKeymap.recommended):
+			if !m.browser.Online() {
+				m.updateStatus(recommendOffline)
+				return m, nil
+			}
+			t.viewMsg = recommendingMsg
+			t.setPage(nil, nil, 0)
+			return m, m.recommendedCmd
+
+		case key.Matches(msg, t.listKeymap.nextFeed, t.listKeymap.reloadFeed):
+			if !m.browser.Online() {
+				m.updateStatus(feedsOffline)
+				return m, nil
+			}
+			if key.Matches(msg, t.listKeymap.nextFeed) {
+				t.feed = t.feed.Next()
+			}
+			t.viewMsg = loadingMsg
+			t.setPage(nil, nil, 0)
+			return m, m.feedCmd(t.feed)
+
+		case key.Matches(msg, t.listKeymap.variants):
+			return m, t.toggleVariants()
+
 		case key.Matches(msg, t.listKeymap.nextTab, t.listKeymap.historyTab):
 			m.toHistoryTab()
 
 		case key.Matches(msg, t.listKeymap.prevTab, t.listKeymap.favoritesTab):
 			m.toFavoritesTab()
 
+		case key.Matches(msg, t.listKeymap.podcastsTab):
+			m.toPodcastsTab()
+
 		case key.Matches(msg, t.listKeymap.settingsTab):
 			return m, m.toSettingsTab()
 
@@ -173,22 +325,133 @@ func (t *browseTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	t.list = newListModel
 	cmds = append(cmds, cmd)
 
+	if _, ok := msg.(tea.KeyMsg); ok {
+		cmds = append(cmds, t.nextPageCmd(m))
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+// setPage records the params and source of the loaded page, or stops the
+// paging if params is nil, not pageable, or the page wasn't full.
+func (t *browseTab) setPage(params *browser.SearchParams, source browser.StationDirectory, count int) {
+	t.pageGen++
+	t.pageLoading = false
+	t.list.SetShowStatusBar(false)
+	t.page, t.pageSource = nil, nil
+	if params != nil && source != nil && params.Pageable() && count >= params.Limit {
+		p := *params
+		t.page, t.pageSource = &p, source
+	}
+}
+
+// nextPageCmd loads the next page once the cursor gets near the end of the
+// unfiltered list.
+func (t *browseTab) nextPageCmd(m *Model) tea.Cmd {
+	if t.page == nil || t.pageLoading || t.list.FilterState() != list.Unfiltered ||
+		t.list.Index() < len(t.list.Items())-pageThreshold {
+		return nil
+	}
+	params := *t.page
+	params.Offset += params.Limit
+	source := t.pageSource
+	gen := t.pageGen
+	t.pageLoading = true
+	t.list.SetShowStatusBar(true)
+	statusCmd := t.list.NewStatusMessage(loadingPageMsg)
+	return tea.Batch(statusCmd, func() tea.Msg {
+		stations, err := source.Search(m.ctx, params)
+		res := pageRespMsg{stations: stations, params: params, gen: gen}
+		if err != nil {
+			res.statusMsg = statusMsg(err.Error())
+		}
+		return res
+	})
+}
+
+// appendStations adds the stations not already in the list.
+func (t *browseTab) appendStations(stations []model.Station) tea.Cmd {
+	seen := make(map[string]bool, len(t.stations))
+	for _, s := range t.stations {
+		seen[s.Stationuuid] = true
+	}
+	for _, s := range stations {
+		if !seen[s.Stationuuid] {
+			seen[s.Stationuuid] = true
+			t.stations = append(t.stations, s)
+		}
+	}
+	return t.list.SetItems(t.items())
+}
+
 func (t *browseTab) setStations(stations []model.Station) tea.Cmd {
-	items := make([]list.Item, len(stations))
-	for i := range stations {
-		items[i] = stations[i]
+	t.stations = slices.Clone(stations)
+	clear(t.expanded)
+	cmd := t.list.SetItems(t.items())
+	t.list.Select(0)
+	return cmd
+}
+
+// items returns the list items of the stations, with their variants grouped
+// if enabled.
+func (t *browseTab) items() []list.Item {
+	stations := t.stations
+	if t.grouping.Enabled {
+		stations = browser.GroupVariants(stations, t.grouping)
+	}
+	items := make([]list.Item, 0, len(stations))
+	for _, s := range stations {
+		items = append(items, s)
+		if !t.expanded[s.Stationuuid] {
+			continue
+		}
+		for _, v := range s.Variants {
+			if v.Stationuuid != s.Stationuuid {
+				v.VariantOf = s.Stationuuid
+				items = append(items, v)
+			}
+		}
+	}
+	return items
+}
+
+// regroup rebuilds the list if the grouping settings changed.
+func (t *browseTab) regroup() {
+	if t.grouping == t.cfg.Grouping {
+		return
 	}
-	cmd := t.list.SetItems(items)
+	t.grouping = t.cfg.Grouping
+	clear(t.expanded)
+	t.list.ResetFilter()
+	t.list.SetItems(t.items())
 	t.list.Select(0)
+}
+
+// toggleVariants expands or collapses the variants of the selected group.
+func (t *browseTab) toggleVariants() tea.Cmd {
+	s, ok := t.list.SelectedItem().(model.Station)
+	if !ok || !t.grouping.Enabled || t.list.FilterState() != list.Unfiltered {
+		return nil
+	}
+	id := s.Stationuuid
+	if s.VariantOf != "" {
+		id = s.VariantOf
+	} else if len(s.Variants) < 2 {
+		return nil
+	}
+	t.expanded[id] = !t.expanded[id]
+	cmd := t.list.SetItems(t.items())
+	if _, idx := t.getListStationByUUID(id); idx != nil {
+		t.list.Select(*idx)
+	}
 	return cmd
 }
 
 func (t *browseTab) View() string {
 	if t.IsSearchEnabled() {
 		return t.searchModel.View()
+	} else if t.IsCategoryEnabled() {
+		return t.categoryModel.View()
 	} else if t.IsInfoEnabled() {
 		return t.infoModel.View()
 	}
@@ -198,3 +461,7 @@ func (t *browseTab) View() string {
 func (t *browseTab) IsSearchEnabled() bool {
 	return t.searchModel.isEnabled()
 }
+
+func (t *browseTab) IsCategoryEnabled() bool {
+	return t.categoryModel.isEnabled()
+}
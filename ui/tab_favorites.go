@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -15,14 +17,16 @@ type favoritesTab struct {
 	stationsTabBase
 	customStationModel *customStationModel
 	cfg                *config.Value
+	// publishUUID is the custom station waiting for the publish confirmation
+	publishUUID string
 }
 
-func newFavoritesTab(cfg *config.Value, infoModel *infoModel, browser *browser.API, s *Style) *favoritesTab {
+func newFavoritesTab(ctx context.Context, cfg *config.Value, infoModel *infoModel, browser browser.Lists, s *Style) *favoritesTab {
 	k := newListKeymap()
 
 	m := &favoritesTab{
 		stationsTabBase:    newStationsTab(k, infoModel, s),
-		customStationModel: newCustomStationModel(browser, s),
+		customStationModel: newCustomStationModel(ctx, browser, s),
 		cfg:                cfg,
 	}
 	return m
@@ -42,9 +46,11 @@ func (t *favoritesTab) createList(delegate *stationDelegate, width int, height i
 			t.listKeymap.nextTab,
 			t.listKeymap.browseTab,
 			t.listKeymap.historyTab,
+			t.listKeymap.podcastsTab,
 			t.listKeymap.settingsTab,
 			t.listKeymap.stationView,
 			t.listKeymap.addCustomFavorite,
+			t.listKeymap.publishCustom,
 		}
 	}
 
@@ -61,6 +67,8 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	logTeaMsg(msg, "ui.favoritesTab.Update")
 
 	var cmds []tea.Cmd
+	// favoritesChanged by the delegate, once the list is updated
+	var favoritesChanged bool
 
 	if t.IsInfoEnabled() {
 		infoModelMsg := msg
@@ -115,11 +123,19 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateStatus(string(sm))
 		cmd := t.list.SetItems(items)
 		cmds = append(cmds, cmd)
+		cmds = append(cmds, t.syncNowPlaying(m))
 		if autoplayUUID != nil {
 			t.list.Select(autoplayIdx)
 			cmds = append(cmds, m.playStationCmd(*autoplayUUID))
 		}
 
+	case nowPlayingMsg:
+		if s, idx := t.getListStationByUUID(msg.uuid); idx != nil {
+			s.NowPlaying = msg.title
+			cmds = append(cmds, t.list.SetItem(*idx, *s))
+		}
+		return m, tea.Batch(cmds...)
+
 	case playHistoryEntryMsg:
 		s, idx := t.getListStationByUUID(msg.uuid)
 		if s != nil {
@@ -145,6 +161,7 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(t.list.Items()) == 0 {
 			t.viewMsg = noFavoritesAddedMsg
 		}
+		cmds = append(cmds, t.syncNowPlaying(m))
 
 	case customStationRespMsg:
 		t.listKeymap.setEnabled(true)
@@ -154,8 +171,20 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 			// add new custom station to favorites
 			t.cfg.AddFavorite(*msg.station)
 			cmd := t.list.InsertItem(len(t.list.Items()), *msg.station)
-			cmds = append(cmds, cmd)
+			cmds = append(cmds, cmd, t.syncNowPlaying(m))
+		}
+
+	case publishRespMsg:
+		if msg.err != nil {
+			m.updateStatus(msg.err.Error())
+			break
 		}
+		t.cfg.ReplaceFavorite(msg.customUUID, *msg.station)
+		if _, idx := t.getListStationByUUID(msg.customUUID); idx != nil {
+			cmds = append(cmds, t.list.SetItem(*idx, *msg.station))
+		}
+		cmds = append(cmds, t.syncNowPlaying(m))
+		m.updateStatus(fmt.Sprintf(published, msg.station.Stationuuid))
 
 	case toggleInfoMsg:
 		if msg.enable {
@@ -181,6 +210,10 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 			break
 		}
 
+		if !key.Matches(msg, t.listKeymap.publishCustom) {
+			t.publishUUID = ""
+		}
+
 		switch {
 		case key.Matches(msg, t.list.KeyMap.Quit, t.list.KeyMap.ForceQuit):
 			return m, tea.Quit
@@ -191,12 +224,35 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, t.customStationModel.Init())
 			return m, tea.Batch(cmds...)
 
+		case key.Matches(msg, t.listKeymap.publishCustom):
+			selStation, ok := t.list.SelectedItem().(model.Station)
+			if !ok {
+				break
+			}
+			if !selStation.IsCustom {
+				m.updateStatus(publishNotCustom)
+				break
+			}
+			if !m.browser.Online() {
+				m.updateStatus(publishOffline)
+				break
+			}
+			if t.publishUUID != selStation.Stationuuid {
+				t.publishUUID = selStation.Stationuuid
+				m.updateStatus(fmt.Sprintf(publishConfirm, selStation.Name))
+				break
+			}
+			t.publishUUID = ""
+			m.updateStatus(publishing)
+			return m, m.publishCmd(selStation)
+
 		case key.Matches(msg, m.delegate.keymap.delete):
 			selStation, ok := t.list.SelectedItem().(model.Station)
 			if !ok {
 				break
 			}
 			m.cfg.DeleteFavorite(selStation)
+			favoritesChanged = true
 			t.viewMsg = ""
 			if !m.cfg.HasFavorites() {
 				t.viewMsg = noFavoritesAddedMsg
@@ -211,6 +267,7 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 				idx++
 			}
 			m.cfg.InsertFavorite(*m.delegate.deleted, idx)
+			favoritesChanged = true
 			if m.cfg.HasFavorites() {
 				t.viewMsg = ""
 			}
@@ -221,6 +278,7 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			idx := t.list.Index()
 			m.cfg.InsertFavorite(*m.delegate.deleted, idx)
+			favoritesChanged = true
 			if m.cfg.HasFavorites() {
 				t.viewMsg = ""
 			}
@@ -235,7 +293,13 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, t.listKeymap.historyTab):
 			m.toHistoryTab()
 
-		case key.Matches(msg, t.listKeymap.prevTab, t.listKeymap.settingsTab):
+		case key.Matches(msg, t.listKeymap.podcastsTab):
+			m.toPodcastsTab()
+
+		case key.Matches(msg, t.listKeymap.prevTab):
+			return m, m.toTab(int(favoriteTabIx) - 1)
+
+		case key.Matches(msg, t.listKeymap.settingsTab):
 			return m, m.toSettingsTab()
 
 		case key.Matches(msg, t.listKeymap.stationView):
@@ -249,6 +313,9 @@ func (t *favoritesTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	newListModel, cmd := t.list.Update(msg)
 	t.list = newListModel
 	cmds = append(cmds, cmd)
+	if favoritesChanged {
+		cmds = append(cmds, t.syncNowPlaying(m))
+	}
 
 	return m, tea.Batch(cmds...)
 }
@@ -262,6 +329,25 @@ func (t *favoritesTab) View() string {
 	return t.stationsTabBase.View()
 }
 
+// syncNowPlaying polls the current favorites and shows their known song
+// titles.
+func (t *favoritesTab) syncNowPlaying(m *Model) tea.Cmd {
+	m.nowPlaying.SetStations(m.cfg.GetFavorites())
+	var cmds []tea.Cmd
+	for i, it := range t.list.Items() {
+		s, ok := it.(model.Station)
+		if !ok {
+			continue
+		}
+		title, _ := m.nowPlaying.Title(s.Stationuuid)
+		if s.NowPlaying != title {
+			s.NowPlaying = title
+			cmds = append(cmds, t.list.SetItem(i, s))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
 func (t *favoritesTab) IsCustomStationEnabled() bool {
 	return t.customStationModel != nil && t.customStationModel.isEnabled()
 }
@@ -53,6 +53,10 @@ func newHistoryTab(ctx context.Context, cfg *config.Value, s *Style) *historyTab
 				key.WithKeys("shift+tab"),
 				key.WithHelp("shift+tab", "go to prev tab"),
 			),
+			podcastsTab: key.NewBinding(
+				key.WithKeys("O"),
+				key.WithHelp("O", "go to podcasts tab"),
+			),
 			settingsTab: key.NewBinding(
 				key.WithKeys("S"),
 				key.WithHelp("S", "go to settings tab"),
@@ -195,6 +199,7 @@ func (t *historyTab) createList(width int, height int) {
 			t.keymap.nextTab,
 			t.keymap.favoritesTab,
 			t.keymap.browseTab,
+			t.keymap.podcastsTab,
 			t.keymap.settingsTab,
 		}
 	}
@@ -243,7 +248,10 @@ func (t *historyTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, t.keymap.digits...):
 			t.doJump(msg)
 
-		case key.Matches(msg, t.keymap.nextTab, t.keymap.settingsTab):
+		case key.Matches(msg, t.keymap.nextTab, t.keymap.podcastsTab):
+			m.toPodcastsTab()
+
+		case key.Matches(msg, t.keymap.settingsTab):
 			return m, m.toSettingsTab()
 
 		case key.Matches(msg, t.keymap.favoritesTab):
@@ -371,6 +379,7 @@ type historyKeymap struct {
 	prevTab      key.Binding
 	favoritesTab key.Binding
 	settingsTab  key.Binding
+	podcastsTab  key.Binding
 	browseTab    key.Binding
 	search       key.Binding
 	digits       []key.Binding
@@ -0,0 +1,568 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/podcast"
+)
+
+const (
+	podcastsFilterPlaceholder = "episode or podcast title"
+	podcastPrompt             = "Feed URL:     "
+	podcastPlaceholder        = "https://example.com/feed.xml"
+
+	// episodeEndMargin before the end, an episode counts as finished and
+	// starts over the next time
+	episodeEndMargin = 30 * time.Second
+)
+
+type podcastsTab struct {
+	ctx    context.Context
+	cfg    *config.Value
+	client *http.Client
+	style  *Style
+
+	// feeds by URL
+	feeds map[string]*podcast.Feed
+	// unsubscribe is the feed URL waiting for the confirmation press
+	unsubscribe string
+
+	adding bool
+	input  textinput.Model
+	// height of the list with its help, shrunk by the input while adding
+	height int
+
+	viewMsg string
+	jump    JumpInfo
+	list    list.Model
+	keymap  podcastsKeymap
+}
+
+func newPodcastsTab(ctx context.Context, cfg *config.Value, s *Style) *podcastsTab {
+	t := &podcastsTab{
+		ctx:    ctx,
+		cfg:    cfg,
+		client: cfg.NewHTTPClient(),
+		style:  s,
+		feeds:  make(map[string]*podcast.Feed),
+		input:  textinput.New(),
+		keymap: podcastsKeymap{
+			play: key.NewBinding(
+				key.WithKeys("enter", "l"),
+				key.WithHelp("enter/l", "play"),
+			),
+			subscribe: key.NewBinding(
+				key.WithKeys("a"),
+				key.WithHelp("a", "subscribe"),
+			),
+			unsubscribe: key.NewBinding(
+				key.WithKeys("d"),
+				key.WithHelp("d", "unsubscribe"),
+			),
+			reload: key.NewBinding(
+				key.WithKeys("r"),
+				key.WithHelp("r", "reload feeds"),
+			),
+			confirm: key.NewBinding(
+				key.WithKeys("enter"),
+				key.WithHelp("enter", "subscribe"),
+			),
+			cancel: key.NewBinding(
+				key.WithKeys("esc"),
+				key.WithHelp("esc", "cancel"),
+			),
+			nextTab: key.NewBinding(
+				key.WithKeys("tab"),
+				key.WithHelp("tab", "go to next tab"),
+			),
+			prevTab: key.NewBinding(
+				key.WithKeys("shift+tab"),
+				key.WithHelp("shift+tab", "go to prev tab"),
+			),
+			favoritesTab: key.NewBinding(
+				key.WithKeys("F"),
+				key.WithHelp("F", "go to favorites tab"),
+			),
+			browseTab: key.NewBinding(
+				key.WithKeys("B"),
+				key.WithHelp("B", "go to browse tab"),
+			),
+			historyTab: key.NewBinding(
+				key.WithKeys("H"),
+				key.WithHelp("H", "go to history tab"),
+			),
+			settingsTab: key.NewBinding(
+				key.WithKeys("S"),
+				key.WithHelp("S", "go to settings tab"),
+			),
+			digits: []key.Binding{
+				key.NewBinding(key.WithKeys("1")),
+				key.NewBinding(key.WithKeys("2")),
+				key.NewBinding(key.WithKeys("3")),
+				key.NewBinding(key.WithKeys("4")),
+				key.NewBinding(key.WithKeys("5")),
+				key.NewBinding(key.WithKeys("6")),
+				key.NewBinding(key.WithKeys("7")),
+				key.NewBinding(key.WithKeys("8")),
+				key.NewBinding(key.WithKeys("9")),
+				key.NewBinding(key.WithKeys("0")),
+			},
+			digitHelp: key.NewBinding(
+				key.WithKeys("#"),
+				key.WithHelp("1..", "go to number #"),
+			),
+		},
+	}
+	s.TextInputSyle(&t.input, podcastPrompt, podcastPlaceholder)
+	return t
+}
+
+func (t *podcastsTab) Init(m *Model) tea.Cmd {
+	t.createList(m.width, m.totHeight-m.headerHeight)
+	if len(t.cfg.Podcasts) == 0 {
+		t.viewMsg = noPodcastsMsg
+		return nil
+	}
+	t.viewMsg = loadingPodcastsMsg
+	return t.reloadCmd()
+}
+
+// reloadCmd fetches all the subscribed feeds; the failing ones are reported
+// but don't hide the others.
+func (t *podcastsTab) reloadCmd() tea.Cmd {
+	urls := append([]string(nil), t.cfg.Podcasts...)
+	return func() tea.Msg {
+		log := slog.With("method", "ui.podcastsTab.reloadCmd")
+		feeds := make([]*podcast.Feed, len(urls))
+		errs := make([]error, len(urls))
+		var wg sync.WaitGroup
+		for i := range urls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				feeds[i], errs[i] = podcast.Fetch(t.ctx, t.client, urls[i])
+				if errs[i] != nil {
+					log.Error("", "feed", urls[i], "error", errs[i])
+					errs[i] = fmt.Errorf("%s: %w", urls[i], errs[i])
+				}
+			}()
+		}
+		wg.Wait()
+		return podcastFeedsMsg{feeds: feeds, err: errors.Join(errs...)}
+	}
+}
+
+func (t *podcastsTab) subscribeCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		feed, err := podcast.Fetch(t.ctx, t.client, url)
+		return podcastSubscribeMsg{url: url, feed: feed, err: err}
+	}
+}
+
+// setEpisodes lists the episodes of all the feeds, newest first.
+func (t *podcastsTab) setEpisodes() tea.Cmd {
+	var episodes []podcast.Episode
+	for _, url := range t.cfg.Podcasts {
+		if f, ok := t.feeds[url]; ok {
+			episodes = append(episodes, f.Episodes...)
+		}
+	}
+	podcast.SortEpisodes(episodes)
+	items := make([]list.Item, len(episodes))
+	for i := range episodes {
+		items[i] = episodes[i]
+	}
+	t.viewMsg = ""
+	if len(t.cfg.Podcasts) == 0 {
+		t.viewMsg = noPodcastsMsg
+	} else if len(episodes) == 0 {
+		t.viewMsg = noEpisodesMsg
+	}
+	return t.list.SetItems(items)
+}
+
+func (t *podcastsTab) episode(id string) (podcast.Episode, bool) {
+	for _, it := range t.list.Items() {
+		if e, ok := it.(podcast.Episode); ok && e.ID == id {
+			return e, true
+		}
+	}
+	return podcast.Episode{}, false
+}
+
+// savePosition remembers where the episode was left, forgetting it once
+// the episode is about to end.
+func (t *podcastsTab) savePosition(id string, pos time.Duration) {
+	if pos <= 0 {
+		return
+	}
+	if e, ok := t.episode(id); ok && e.Duration > 0 && pos >= e.Duration-episodeEndMargin {
+		t.cfg.SetEpisodePosition(id, 0)
+		return
+	}
+	t.cfg.SetEpisodePosition(id, int64(pos.Seconds()))
+}
+
+func (t *podcastsTab) createList(width int, height int) {
+	delegate := episodeDelegate{
+		defaultDelegate: list.NewDefaultDelegate(),
+		cfg:             t.cfg,
+		keymap:          &t.keymap,
+		style:           t.style,
+	}
+	l := list.New([]list.Item{}, &delegate, 0, 0)
+	l.InfiniteScrolling = true
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowPagination(false)
+	l.SetShowFilter(true)
+	l.Filter = list.UnsortedFilter
+	l.SetStatusBarItemName("episode", "episodes")
+	l.Styles.NoItems = t.style.NoItemsStyle
+	l.FilterInput.ShowSuggestions = true
+	l.KeyMap.Quit.SetKeys("q")
+	l.KeyMap.PrevPage.SetKeys("pgup", "ctrl+b")
+	l.KeyMap.PrevPage.SetHelp("ctrl+b/pgup", "prev page")
+	l.KeyMap.NextPage.SetKeys("pgdown", "ctrl+f")
+	l.KeyMap.NextPage.SetHelp("ctrl+f/pgdn", "next page")
+	h, v := t.style.DocStyle.GetFrameSize()
+	l.SetSize(width-h, height-v)
+	t.height = height - v
+
+	l.Help.ShortSeparator = "   "
+	l.Help.Styles = t.style.HelpStyles()
+	l.Styles.HelpStyle = t.style.HelpStyle
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{t.keymap.subscribe}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			t.keymap.subscribe,
+			t.keymap.reload,
+			t.keymap.digitHelp,
+			t.keymap.prevTab,
+			t.keymap.nextTab,
+			t.keymap.favoritesTab,
+			t.keymap.browseTab,
+			t.keymap.historyTab,
+			t.keymap.settingsTab,
+		}
+	}
+
+	t.style.TextInputSyle(&l.FilterInput, stationsFilterPrompt, podcastsFilterPlaceholder)
+
+	t.list = l
+}
+
+func (t *podcastsTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	logTeaMsg(msg, "ui.podcastsTab.Update")
+
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := t.style.DocStyle.GetFrameSize()
+		t.list.SetWidth(msg.Width - h)
+		t.height = msg.Height - m.headerHeight - v
+		t.setAdding(t.adding)
+
+	case podcastFeedsMsg:
+		for _, f := range msg.feeds {
+			if f != nil {
+				t.feeds[f.URL] = f
+			}
+		}
+		if msg.err != nil {
+			m.updateStatus(msg.err.Error())
+		}
+		return m, t.setEpisodes()
+
+	case podcastSubscribeMsg:
+		if msg.err != nil {
+			m.updateStatus(msg.err.Error())
+			return m, nil
+		}
+		if err := t.cfg.AddPodcast(msg.url); err != nil {
+			m.updateStatus(err.Error())
+			return m, nil
+		}
+		t.feeds[msg.url] = msg.feed
+		m.updateStatus(fmt.Sprintf(podcastSubscribed, msg.feed.Title))
+		return m, t.setEpisodes()
+
+	case tea.KeyMsg:
+		if t.adding {
+			return m, t.updateInput(m, msg)
+		}
+		if t.list.FilterState() == list.Filtering {
+			break
+		}
+		if !key.Matches(msg, t.keymap.unsubscribe) {
+			t.unsubscribe = ""
+		}
+
+		switch {
+		case key.Matches(msg, t.list.KeyMap.Quit, t.list.KeyMap.ForceQuit):
+			return m, tea.Quit
+
+		case key.Matches(msg, t.keymap.play):
+			if e, ok := t.list.SelectedItem().(podcast.Episode); ok {
+				return m, m.playEpisodeCmd(e)
+			}
+			return m, nil
+
+		case key.Matches(msg, t.keymap.subscribe):
+			t.setAdding(true)
+			t.input.SetValue("")
+			return m, t.input.Focus()
+
+		case key.Matches(msg, t.keymap.unsubscribe):
+			e, ok := t.list.SelectedItem().(podcast.Episode)
+			if !ok {
+				return m, nil
+			}
+			if t.unsubscribe != e.FeedURL {
+				t.unsubscribe = e.FeedURL
+				m.updateStatus(fmt.Sprintf(podcastUnsubscribeConfirm, e.FeedTitle))
+				return m, nil
+			}
+			t.unsubscribe = ""
+			t.cfg.RemovePodcast(e.FeedURL)
+			delete(t.feeds, e.FeedURL)
+			m.updateStatus(fmt.Sprintf(podcastUnsubscribed, e.FeedTitle))
+			return m, t.setEpisodes()
+
+		case key.Matches(msg, t.keymap.reload):
+			if len(t.cfg.Podcasts) == 0 {
+				return m, nil
+			}
+			m.updateStatus(podcastsReloading)
+			return m, t.reloadCmd()
+
+		case key.Matches(msg, t.keymap.digits...):
+			t.doJump(msg)
+
+		case key.Matches(msg, t.keymap.nextTab, t.keymap.settingsTab):
+			return m, m.toSettingsTab()
+
+		case key.Matches(msg, t.keymap.prevTab, t.keymap.historyTab):
+			m.toHistoryTab()
+
+		case key.Matches(msg, t.keymap.favoritesTab):
+			m.toFavoritesTab()
+
+		case key.Matches(msg, t.keymap.browseTab):
+			m.toBrowseTab()
+		}
+	}
+
+	newListModel, cmd := t.list.Update(msg)
+	t.list = newListModel
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+func (t *podcastsTab) updateInput(m *Model, msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, t.keymap.cancel):
+		t.setAdding(false)
+		t.input.Blur()
+		return nil
+	case key.Matches(msg, t.keymap.confirm):
+		url := strings.TrimSpace(t.input.Value())
+		if url == "" {
+			return nil
+		}
+		t.setAdding(false)
+		t.input.Blur()
+		m.updateStatus(podcastSubscribing)
+		return t.subscribeCmd(url)
+	}
+	var cmd tea.Cmd
+	t.input, cmd = t.input.Update(msg)
+	return cmd
+}
+
+// setAdding shows the feed URL input and its help below the list, in place
+// of the list help.
+func (t *podcastsTab) setAdding(v bool) {
+	t.adding = v
+	t.list.SetShowHelp(!v)
+	if v {
+		t.list.SetHeight(max(t.height-2, 0))
+	} else {
+		t.list.SetHeight(t.height)
+	}
+}
+
+func (t *podcastsTab) doJump(msg tea.KeyMsg) {
+	digit, _ := strconv.Atoi(msg.String())
+	jumpIdx := t.jump.NewPosition(digit)
+	if jumpIdx > 0 && jumpIdx <= len(t.list.Items()) {
+		t.list.Select(jumpIdx - 1)
+	}
+}
+
+// IsFiltering is also true while typing a feed URL, for the keys to reach
+// the input.
+func (t *podcastsTab) IsFiltering() bool {
+	return t.adding || t.list.FilterState() == list.Filtering
+}
+
+func (t *podcastsTab) View() string {
+	var sections []string
+	if t.viewMsg != "" {
+		availHeight := t.list.Height()
+		help := t.list.Styles.HelpStyle.Render(t.list.Help.View(t.list))
+		if t.adding {
+			help = ""
+		}
+		availHeight -= lipgloss.Height(help)
+		sections = append(sections, t.style.ViewStyle.Height(availHeight).Render(t.viewMsg))
+		if help != "" {
+			sections = append(sections, help)
+		}
+	} else {
+		sections = append(sections, t.list.View())
+	}
+	if t.adding {
+		// the feed URL input replaces the list help
+		sections = append(sections,
+			t.style.PrefixStyle.Render("   ")+t.input.View(),
+			t.list.Styles.HelpStyle.Render(t.list.Help.ShortHelpView([]key.Binding{t.keymap.confirm, t.keymap.cancel})),
+		)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+type episodeDelegate struct {
+	defaultDelegate list.DefaultDelegate
+	cfg             *config.Value
+	keymap          *podcastsKeymap
+	style           *Style
+}
+
+func (d *episodeDelegate) ShortHelp() []key.Binding {
+	return []key.Binding{d.keymap.play}
+}
+
+func (d *episodeDelegate) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{d.keymap.play, d.keymap.unsubscribe}}
+}
+
+func (d *episodeDelegate) Height() int { return d.defaultDelegate.Height() }
+
+func (d *episodeDelegate) Spacing() int { return d.defaultDelegate.Spacing() }
+
+func (d *episodeDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
+
+func (d *episodeDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	e, ok := item.(podcast.Episode)
+	if !ok {
+		return
+	}
+	isSel := index == m.Index()
+	var res strings.Builder
+
+	prefix := fmt.Sprintf("%d. ", index+1)
+	if index+1 < 10 {
+		prefix = fmt.Sprintf("   %s", prefix)
+	} else if index+1 < 100 {
+		prefix = fmt.Sprintf("  %s", prefix)
+	} else if index+1 < 1000 {
+		prefix = fmt.Sprintf(" %s", prefix)
+	}
+	listWidth := m.Width()
+	prefixRender := d.style.PrefixStyle.Render(prefix)
+	res.WriteString(prefixRender)
+	maxWidth := max(listWidth-lipgloss.Width(prefixRender)-HeaderPadDist, 0)
+
+	itStyle := d.style.SecondaryColorStyle
+	descStyle := d.style.HistoryDescStyle
+	if isSel {
+		itStyle = d.style.HistorySelItemStyle
+		descStyle = d.style.HistorySelDescStyle
+	}
+
+	title := e.Title
+	for lipgloss.Width(itStyle.Render(title)) > maxWidth && len(title) > 0 {
+		title = title[:len(title)-1]
+	}
+	nameRender := itStyle.Render(title)
+	res.WriteString(nameRender)
+	hFill := max(listWidth-lipgloss.Width(prefixRender)-lipgloss.Width(nameRender)-HeaderPadDist, 0)
+	res.WriteString(itStyle.Render(strings.Repeat(" ", hFill)))
+	res.WriteString("\n")
+
+	res.WriteString(d.style.PrefixStyle.Render(strings.Repeat(" ", utf8.RuneCountInString(prefix))))
+	desc := d.description(e)
+	for lipgloss.Width(descStyle.Render(desc)) > maxWidth && len(desc) > 0 {
+		desc = desc[:len(desc)-1]
+	}
+	descRender := descStyle.Render(desc)
+	res.WriteString(descRender)
+	hFill = max(listWidth-lipgloss.Width(prefixRender)-lipgloss.Width(descRender)-HeaderPadDist, 0)
+	res.WriteString(descStyle.Render(strings.Repeat(" ", hFill)))
+
+	_, _ = fmt.Fprint(w, res.String())
+}
+
+// description shows the podcast, the publish date, the duration and the
+// resume position of the episode.
+func (d *episodeDelegate) description(e podcast.Episode) string {
+	parts := []string{e.FeedTitle}
+	if !e.Published.IsZero() {
+		parts = append(parts, e.Published.Local().Format(time.DateOnly))
+	}
+	if e.Duration > 0 {
+		parts = append(parts, formatPosition(e.Duration))
+	}
+	if sec, ok := d.cfg.EpisodePosition(e.ID); ok {
+		parts = append(parts, fmt.Sprintf(episodeResumeAt, formatPosition(time.Duration(sec)*time.Second)))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatPosition formats d as H:MM:SS, or MM:SS under an hour.
+func formatPosition(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+type podcastsKeymap struct {
+	play         key.Binding
+	subscribe    key.Binding
+	unsubscribe  key.Binding
+	reload       key.Binding
+	confirm      key.Binding
+	cancel       key.Binding
+	nextTab      key.Binding
+	prevTab      key.Binding
+	favoritesTab key.Binding
+	browseTab    key.Binding
+	historyTab   key.Binding
+	settingsTab  key.Binding
+	digits       []key.Binding
+	digitHelp    key.Binding
+}
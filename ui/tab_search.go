@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"context"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchTabNameMax is the maximum length of a pinned search tab name.
+const searchTabNameMax = 16
+
+// searchTab shows the results of a saved search pinned as a tab, re-run each
+// time the tab is opened.
+type searchTab struct {
+	stationsTabBase
+	name string
+	ctx  context.Context
+	// cancelSearch aborts the previous run
+	cancelSearch context.CancelFunc
+}
+
+func newSearchTab(ctx context.Context, name string, infoModel *infoModel, s *Style) *searchTab {
+	return &searchTab{
+		stationsTabBase: newStationsTab(newListKeymap(), infoModel, s),
+		name:            name,
+		ctx:             ctx,
+		cancelSearch:    func() {},
+	}
+}
+
+func (t *searchTab) createList(delegate *stationDelegate, width int, height int) list.Model {
+	l := createList(delegate, width, height)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{t.listKeymap.search}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			t.listKeymap.search,
+			t.listKeymap.digitHelp,
+			t.listKeymap.toNowPlaying,
+			t.listKeymap.prevTab,
+			t.listKeymap.nextTab,
+			t.listKeymap.favoritesTab,
+			t.listKeymap.browseTab,
+			t.listKeymap.historyTab,
+			t.listKeymap.podcastsTab,
+			t.listKeymap.settingsTab,
+			t.listKeymap.stationView,
+		}
+	}
+	return l
+}
+
+func (t *searchTab) Init(m *Model) tea.Cmd {
+	t.viewMsg = loadingMsg
+	t.list = t.createList(m.delegate, m.width, m.totHeight-m.headerHeight)
+	return nil
+}
+
+// tabName is the name shown in the header, shortened to searchTabNameMax.
+func (t *searchTab) tabName() string {
+	name := []rune(t.name)
+	if len(name) > searchTabNameMax {
+		name = append(name[:searchTabNameMax-1], '…')
+	}
+	return " " + string(name) + " "
+}
+
+// searchCmd re-runs the saved search.
+func (t *searchTab) searchCmd(m *Model) tea.Cmd {
+	params, ok := savedSearchParams(m.cfg, t.name)
+	if !ok {
+		return nil
+	}
+	t.cancelSearch()
+	ctx, cancel := context.WithCancel(t.ctx)
+	t.cancelSearch = cancel
+	t.viewMsg = loadingMsg
+	name := t.name
+	return func() tea.Msg {
+		defer cancel()
+		stations, err := m.directory.Search(ctx, params)
+		res := savedSearchRespMsg{name: name, stations: stations}
+		if err != nil {
+			res.statusMsg = statusMsg(err.Error())
+			res.viewMsg = noStationsFound
+		} else if len(stations) == 0 {
+			res.viewMsg = noStationsFound
+		}
+		return res
+	}
+}
+
+func (t *searchTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	logTeaMsg(msg, "ui.searchTab.Update")
+
+	var cmds []tea.Cmd
+
+	if t.IsInfoEnabled() {
+		infoModelMsg := msg
+		if sizeMsg, ok := msg.(tea.WindowSizeMsg); ok {
+			infoModelMsg = t.newSizeMsg(sizeMsg, m)
+		}
+		im, cmd := t.infoModel.Update(infoModelMsg)
+		t.infoModel = im
+		cmds = append(cmds, cmd)
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := t.style.DocStyle.GetFrameSize()
+		t.list.SetSize(msg.Width-h, msg.Height-m.headerHeight-v)
+
+	case savedSearchRespMsg:
+		if msg.statusMsg != "" {
+			m.updateStatus(string(msg.statusMsg))
+		}
+		t.viewMsg = string(msg.viewMsg)
+		items := make([]list.Item, len(msg.stations))
+		for i := range msg.stations {
+			items[i] = msg.stations[i]
+		}
+		cmds = append(cmds, t.list.SetItems(items))
+		t.list.Select(0)
+		return m, tea.Batch(cmds...)
+
+	case toggleInfoMsg:
+		if msg.enable {
+			cmds = append(cmds, t.initInfoModel(m, msg))
+			return m, tea.Batch(cmds...)
+		} else {
+			t.listKeymap.setEnabled(true)
+		}
+
+	case tea.KeyMsg:
+		if t.IsInfoEnabled() {
+			return m, tea.Batch(cmds...)
+		}
+
+		if key.Matches(msg, t.listKeymap.toNowPlaying) {
+			newListModel, cmd := t.list.Update(msg)
+			t.list = newListModel
+			cmds = append(cmds, cmd)
+			t.toNowPlaying(m)
+		}
+
+		if t.IsFiltering() {
+			break
+		}
+
+		ix := slices.Index(m.tabs, uiTab(t))
+		switch {
+		case key.Matches(msg, t.list.KeyMap.Quit, t.list.KeyMap.ForceQuit):
+			return m, tea.Quit
+
+		case key.Matches(msg, t.listKeymap.search):
+			m.toBrowseTab()
+			return m.tabs[browseTabIx].Update(m, msg)
+
+		case key.Matches(msg, t.listKeymap.nextTab):
+			return m, m.toTab(ix + 1)
+
+		case key.Matches(msg, t.listKeymap.prevTab):
+			return m, m.toTab(ix - 1)
+
+		case key.Matches(msg, t.listKeymap.favoritesTab):
+			m.toFavoritesTab()
+
+		case key.Matches(msg, t.listKeymap.browseTab):
+			m.toBrowseTab()
+
+		case key.Matches(msg, t.listKeymap.historyTab):
+			m.toHistoryTab()
+
+		case key.Matches(msg, t.listKeymap.podcastsTab):
+			m.toPodcastsTab()
+
+		case key.Matches(msg, t.listKeymap.settingsTab):
+			return m, m.toSettingsTab()
+
+		case key.Matches(msg, t.listKeymap.stationView):
+			m.changeStationView()
+
+		case key.Matches(msg, t.listKeymap.digits...):
+			t.doJump(msg)
+		}
+	}
+
+	newListModel, cmd := t.list.Update(msg)
+	t.list = newListModel
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+func (t *searchTab) View() string {
+	if t.IsInfoEnabled() {
+		return t.infoModel.View()
+	}
+	return t.stationsTabBase.View()
+}
@@ -14,11 +14,14 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dancnb/sonicradio/config"
+	"github.com/dancnb/sonicradio/geo"
 )
 
 type settingsTab struct {
 	cfg           *config.Value
 	changeThemeFn func(int)
+	// changeNowPlayingFn starts or stops polling the favorites song titles
+	changeNowPlayingFn func(bool)
 
 	style  *Style
 	keymap settingsKeymap
@@ -38,6 +41,16 @@ const (
 	themesIdx
 	playerTypeIdx
 	internalBufferSecIdx
+	internalTimeshiftMinIdx
+	internalPrebufferSecIdx
+	deadAirActionIdx
+	deadAirSilenceSecIdx
+	proxyIdx
+	locationIdx
+	groupVariantsIdx
+	variantPreferIdx
+	preferredCodecIdx
+	nowPlayingIdx
 	mpdHostIdx
 	mpdPortIdx
 	mpdPassIdx
@@ -50,6 +63,16 @@ var (
 		"Select a backend player (only those in PATH are shown: Mpv, FFplay, VLC, MPlayer, MPD), or use the experimental Internal player.\nChanges take effect after restart.\n",
 		"Duration in seconds of the internal player's buffered samples (up to 5 minutes, but will increase memory usage). Set to 0 to disable buffering and seeking.\nChanges take effect after restart.",
 		"If enabled, it will retrieve favorite station metadata on each start.\nBy default, it will use the metadata cached in the local playlist file (see $XDG_CONFIG_HOME/sonicRadio/favorites.pls).",
+		"Action taken by the internal player when a station keeps the connection open but sends only silence, or stops sending audio data: show a warning, reconnect to the same station, or skip to the next favorite.",
+		"Duration in seconds of continuous silence after which the dead air action is taken (internal player only).",
+		"Duration in minutes of the internal player's disk-backed timeshift (up to 24 hours). The compressed stream is kept in a temporary file until the station changes, allowing long pauses and rewinds with low memory usage. When enabled, it replaces the in-memory buffer. Set to 0 to disable.\nChanges take effect after restart.",
+		"Seconds of stream data the internal player reads ahead before playback starts, and again after the network could not keep up, to avoid audible dropouts (up to 30 seconds). Set to 0 to start playing immediately.\nChanges take effect after restart.",
+		"HTTP or SOCKS5 proxy used for the station directory and stream connections, e.g. socks5://127.0.0.1:1080. If empty, the HTTP_PROXY, HTTPS_PROXY, NO_PROXY and ALL_PROXY environment variables are used. MPlayer and MPD connect directly; mpv and FFplay support only HTTP proxies.\nChanges take effect after restart.",
+		`Location of the stations near me ("n" in the "Browse" tab): a city such as "Berlin, DE", or a lat,long pair in decimal degrees such as 52.52,13.405.`,
+		`If enabled, the variants of the same station in the "Browse" tab (the same name and homepage, with other bitrates, codecs or stream URLs) are grouped in a single entry; press "x" to expand or collapse its variants.`,
+		"Variant of a grouped station played by default: the highest bitrate one, one with the preferred codec, or one with an HTTPS stream URL. If none matches, the highest bitrate one is played.",
+		"Codec preferred when choosing the variant of a grouped station, e.g. AAC, MP3, OGG or OPUS.",
+		`If enabled, the song currently playing on each station in the "Favorites" tab is shown under its name. The stations are polled in the background with short stream connections, a few at a time, every 2 minutes (see nowPlaying.intervalSeconds in the config file).`,
 	}
 	ffplayDesc  = "\nFFplay does not allow changing the volume during playback or seeking backward/forward."
 	vlcDesc     = "\nFor VLC, pausing or seeking backward/forward may result in an invalid song title being displayed."
@@ -65,6 +88,7 @@ func newSettingsTab(
 	s *Style,
 	availablePlayerTypes []config.PlayerType,
 	changeThemeFn func(int),
+	changeNowPlayingFn func(bool),
 ) *settingsTab {
 	h := help.New()
 	h.ShowAll = false
@@ -107,6 +131,46 @@ func newSettingsTab(
 
 	// internal player settings
 	internalBufferSec := s.NewInputModel("Internal buffer (seconds)", "0", nil, nil, nil, bufferDurationValidator)
+	internalTimeshiftMin := s.NewInputModel("Timeshift (minutes)", "0", nil, nil, nil, timeshiftDurationValidator)
+	internalPrebufferSec := s.NewInputModel("Prebuffer (seconds)", "---", nil, nil, nil, prebufferDurationValidator)
+
+	deadAirOpts := make([]OptionValue, len(config.DeadAirActions))
+	for i := range config.DeadAirActions {
+		deadAirOpts[i] = OptionValue{IdxView: i + 1, NameView: config.DeadAirActions[i].String()}
+	}
+	deadAirList := NewOptionList("Dead air action", deadAirOpts, int(cfg.Internal.DeadAir.Action), s)
+	deadAirList.SetQuick(true)
+	deadAirList.DoneCallbackFn = func(i int) {
+		cfg.Internal.DeadAir.Action = config.DeadAirActions[i]
+		slog.Info("change dead air action", "i", i, "new action", cfg.Internal.DeadAir.Action.String())
+	}
+	deadAirSilenceSec := s.NewInputModel("Dead air silence (seconds)", "---", nil, nil, nil, NrInputValidator)
+
+	proxy := s.NewInputModel("Proxy", "none", nil, nil, nil, proxyValidator)
+
+	location := s.NewInputModel("Location", "none", nil, nil, nil, nil)
+	location.ShowSuggestions = true
+	var cities []string
+	for _, c := range geo.Cities() {
+		cities = append(cities, c.String())
+	}
+	location.SetSuggestions(cities)
+
+	groupVariants := NewCheckbox("Group station variants", cfg.Grouping.Enabled, s)
+
+	variantOpts := make([]OptionValue, len(config.VariantPreferences))
+	for i := range config.VariantPreferences {
+		variantOpts[i] = OptionValue{IdxView: i + 1, NameView: config.VariantPreferences[i].String()}
+	}
+	variantList := NewOptionList("Preferred variant", variantOpts, int(cfg.Grouping.Prefer), s)
+	variantList.SetQuick(true)
+	variantList.DoneCallbackFn = func(i int) {
+		cfg.Grouping.Prefer = config.VariantPreferences[i]
+		slog.Info("change variant preference", "i", i, "new preference", cfg.Grouping.Prefer.String())
+	}
+	preferredCodec := s.NewInputModel("Preferred codec", config.DefGroupingCodec, nil, nil, nil, nil)
+
+	nowPlaying := NewCheckbox("What's on now", cfg.NowPlaying.Enabled, s)
 
 	inputs := []*FormElement{
 		NewFormElement(
@@ -124,6 +188,36 @@ func newSettingsTab(
 		NewFormElement(
 			WithTextInput(&internalBufferSec),
 			WithDescription(descriptions[3])),
+		NewFormElement(
+			WithTextInput(&internalTimeshiftMin),
+			WithDescription(descriptions[7])),
+		NewFormElement(
+			WithTextInput(&internalPrebufferSec),
+			WithDescription(descriptions[8])),
+		NewFormElement(
+			WithOptionList(&deadAirList),
+			WithDescription(descriptions[5])),
+		NewFormElement(
+			WithTextInput(&deadAirSilenceSec),
+			WithDescription(descriptions[6])),
+		NewFormElement(
+			WithTextInput(&proxy),
+			WithDescription(descriptions[9])),
+		NewFormElement(
+			WithTextInput(&location),
+			WithDescription(descriptions[10])),
+		NewFormElement(
+			WithCheckbox(groupVariants),
+			WithDescription(descriptions[11])),
+		NewFormElement(
+			WithOptionList(&variantList),
+			WithDescription(descriptions[12])),
+		NewFormElement(
+			WithTextInput(&preferredCodec),
+			WithDescription(descriptions[13])),
+		NewFormElement(
+			WithCheckbox(nowPlaying),
+			WithDescription(descriptions[14])),
 	}
 	if slices.Contains(availablePlayerTypes, config.MPD) {
 		mpdHost := s.NewInputModel("MPD hostname", "127.0.0.1", nil, nil, nil, nil)
@@ -145,12 +239,13 @@ func newSettingsTab(
 	}
 
 	st := &settingsTab{
-		cfg:           cfg,
-		changeThemeFn: changeThemeFn,
-		style:         s,
-		inputs:        inputs,
-		keymap:        newSettingsKeymap(),
-		help:          h,
+		cfg:                cfg,
+		changeThemeFn:      changeThemeFn,
+		changeNowPlayingFn: changeNowPlayingFn,
+		style:              s,
+		inputs:             inputs,
+		keymap:             newSettingsKeymap(),
+		help:               h,
 	}
 
 	st.loadConfig()
@@ -191,8 +286,23 @@ func (s *settingsTab) loadConfig() {
 	s.inputs[historySaveMaxIdx].SetValue(fmt.Sprintf("%d", *s.cfg.HistorySaveMax))
 
 	s.inputs[internalBufferSecIdx].SetValue(fmt.Sprintf("%d", s.cfg.Internal.BufferSeconds))
+	s.inputs[internalTimeshiftMinIdx].SetValue(fmt.Sprintf("%d", s.cfg.Internal.TimeshiftMinutes))
+	s.inputs[internalPrebufferSecIdx].SetValue(fmt.Sprintf("%d", s.cfg.Internal.GetPrebufferSeconds()))
+
+	s.inputs[deadAirActionIdx].SetValue(int(s.cfg.Internal.DeadAir.Action))
+	s.inputs[deadAirSilenceSecIdx].SetValue(fmt.Sprintf("%d", s.cfg.Internal.DeadAir.GetSilenceSeconds()))
+
+	s.inputs[proxyIdx].SetValue(s.cfg.Proxy)
+
+	s.inputs[locationIdx].SetValue(s.cfg.Location.String())
+
+	s.inputs[groupVariantsIdx].SetValue(s.cfg.Grouping.Enabled)
+	s.inputs[variantPreferIdx].SetValue(int(s.cfg.Grouping.Prefer))
+	s.inputs[preferredCodecIdx].SetValue(s.cfg.Grouping.Codec)
 
-	if len(s.inputs) > int(internalBufferSecIdx)+1 {
+	s.inputs[nowPlayingIdx].SetValue(s.cfg.NowPlaying.Enabled)
+
+	if len(s.inputs) > int(mpdHostIdx) {
 		s.inputs[mpdHostIdx].SetValue(s.cfg.MpdHost)
 		s.inputs[mpdPortIdx].SetValue(fmt.Sprintf("%d", s.cfg.MpdPort))
 		if s.cfg.MpdPassword != nil {
@@ -255,7 +365,61 @@ func (s *settingsTab) updateConfig() {
 		s.cfg.Internal.BufferSeconds = bIntVal
 	}
 
-	if len(s.inputs) > int(internalBufferSecIdx)+1 {
+	internalTimeshiftMinVal := s.inputs[internalTimeshiftMinIdx].Value()
+	tsIntVal, err := strconv.Atoi(internalTimeshiftMinVal)
+	if err != nil {
+		log.Info(fmt.Sprintf("invalid internalTimeshiftMin input value: %v", err))
+	} else {
+		s.cfg.Internal.TimeshiftMinutes = tsIntVal
+	}
+
+	internalPrebufferSecVal := s.inputs[internalPrebufferSecIdx].Value()
+	pbIntVal, err := strconv.Atoi(internalPrebufferSecVal)
+	if err != nil {
+		log.Info(fmt.Sprintf("invalid internalPrebufferSec input value: %v", err))
+	} else {
+		s.cfg.Internal.PrebufferSeconds = &pbIntVal
+	}
+
+	deadAirSilenceVal := s.inputs[deadAirSilenceSecIdx].Value()
+	silenceIntVal, err := strconv.Atoi(deadAirSilenceVal)
+	if err != nil || silenceIntVal <= 0 {
+		log.Info(fmt.Sprintf("invalid deadAirSilenceSec input value: %q", deadAirSilenceVal))
+	} else {
+		s.cfg.Internal.DeadAir.SilenceSeconds = silenceIntVal
+	}
+
+	proxyVal := strings.TrimSpace(s.inputs[proxyIdx].Value())
+	if err := proxyValidator(proxyVal); err != nil {
+		log.Info(fmt.Sprintf("invalid proxy input value: %v", err))
+	} else {
+		s.cfg.Proxy = proxyVal
+	}
+
+	locationVal := s.inputs[locationIdx].Value()
+	if err := s.cfg.Location.SetFromString(locationVal); err != nil {
+		log.Info(fmt.Sprintf("invalid location input value: %v", err))
+	}
+
+	groupVariantsVal := s.inputs[groupVariantsIdx].Value()
+	groupVariantsBoolVal, err := strconv.ParseBool(groupVariantsVal)
+	if err != nil {
+		log.Info(fmt.Sprintf("invalid value for config Grouping.Enabled (%v) err: %v", groupVariantsVal, err))
+	} else {
+		s.cfg.Grouping.Enabled = groupVariantsBoolVal
+	}
+	s.cfg.Grouping.Codec = strings.ToUpper(strings.TrimSpace(s.inputs[preferredCodecIdx].Value()))
+
+	nowPlayingVal := s.inputs[nowPlayingIdx].Value()
+	nowPlayingBoolVal, err := strconv.ParseBool(nowPlayingVal)
+	if err != nil {
+		log.Info(fmt.Sprintf("invalid value for config NowPlaying.Enabled (%v) err: %v", nowPlayingVal, err))
+	} else {
+		s.cfg.NowPlaying.Enabled = nowPlayingBoolVal
+		s.changeNowPlayingFn(nowPlayingBoolVal)
+	}
+
+	if len(s.inputs) > int(mpdHostIdx) {
 		mpdHost := strings.TrimSpace(s.inputs[mpdHostIdx].Value())
 		s.cfg.MpdHost = mpdHost
 
@@ -322,15 +486,21 @@ func (s *settingsTab) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		// 	s.onExit()
 		// 	m.toBrowseTab()
 		// 	return m.tabs[browseTabIx].Update(m, msg)
-		case key.Matches(msg, s.keymap.nextTab, s.keymap.favoritesTab):
+		case key.Matches(msg, s.keymap.nextTab):
+			s.onExit()
+			return m, m.toTab(int(settingsTabIx) + 1)
+		case key.Matches(msg, s.keymap.favoritesTab):
 			s.onExit()
 			m.toFavoritesTab()
 		case key.Matches(msg, s.keymap.browseTab):
 			s.onExit()
 			m.toBrowseTab()
-		case key.Matches(msg, s.keymap.prevTab, s.keymap.historyTab):
+		case key.Matches(msg, s.keymap.historyTab):
 			s.onExit()
 			m.toHistoryTab()
+		case key.Matches(msg, s.keymap.prevTab, s.keymap.podcastsTab):
+			s.onExit()
+			m.toPodcastsTab()
 
 		case key.Matches(msg, s.keymap.nextInput):
 			s.idx++
@@ -375,7 +545,32 @@ func (s *settingsTab) resetSettings() {
 	bVal := strconv.Itoa(config.DefInternalBufferSeconds)
 	s.inputs[internalBufferSecIdx].SetValue(bVal)
 
-	if len(s.inputs) > int(internalBufferSecIdx)+1 {
+	s.cfg.Internal.TimeshiftMinutes = config.DefInternalTimeshiftMinutes
+	s.inputs[internalTimeshiftMinIdx].SetValue(strconv.Itoa(config.DefInternalTimeshiftMinutes))
+
+	s.cfg.Internal.PrebufferSeconds = nil
+	s.inputs[internalPrebufferSecIdx].SetValue(strconv.Itoa(config.DefInternalPrebufferSeconds))
+
+	s.cfg.Internal.DeadAir = config.DeadAir{}
+	s.inputs[deadAirActionIdx].SetValue(int(config.DeadAirWarn))
+	s.inputs[deadAirSilenceSecIdx].SetValue(strconv.Itoa(config.DefDeadAirSilenceSeconds))
+
+	s.cfg.Proxy = ""
+	s.inputs[proxyIdx].SetValue("")
+
+	s.cfg.Location = config.Location{}
+	s.inputs[locationIdx].SetValue("")
+
+	s.cfg.Grouping = config.Grouping{}
+	s.inputs[groupVariantsIdx].SetValue(false)
+	s.inputs[variantPreferIdx].SetValue(int(config.VariantHighestBitrate))
+	s.inputs[preferredCodecIdx].SetValue("")
+
+	s.cfg.NowPlaying.Enabled = false
+	s.inputs[nowPlayingIdx].SetValue(false)
+	s.changeNowPlayingFn(false)
+
+	if len(s.inputs) > int(mpdHostIdx) {
 		s.cfg.MpdHost = config.DefMpdHost
 		s.inputs[mpdHostIdx].SetValue(config.DefMpdHost)
 
@@ -448,6 +643,7 @@ type settingsKeymap struct {
 	favoritesTab  key.Binding
 	browseTab     key.Binding
 	historyTab    key.Binding
+	podcastsTab   key.Binding
 	showFullHelp  key.Binding
 	closeFullHelp key.Binding
 	quit          key.Binding
@@ -483,6 +679,10 @@ func newSettingsKeymap() settingsKeymap {
 			key.WithKeys("H"),
 			key.WithHelp("H", "go to history tab"),
 		),
+		podcastsTab: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "go to podcasts tab"),
+		),
 		favoritesTab: key.NewBinding(
 			key.WithKeys("F"),
 			key.WithHelp("F", "go to favorites tab"),
@@ -516,6 +716,7 @@ func (k *settingsKeymap) setEnable(v bool, showAll bool) {
 	k.favoritesTab.SetEnabled(v)
 	k.browseTab.SetEnabled(v)
 	k.historyTab.SetEnabled(v)
+	k.podcastsTab.SetEnabled(v)
 	if v {
 		k.showFullHelp.SetEnabled(!showAll)
 		k.closeFullHelp.SetEnabled(showAll)
@@ -533,11 +734,41 @@ func (k *settingsKeymap) ShortHelp() []key.Binding {
 func (k *settingsKeymap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.prevInput, k.nextInput, k.enterInput, k.reset},
-		{k.prevTab, k.nextTab, k.favoritesTab, k.browseTab, k.historyTab},
+		{k.prevTab, k.nextTab, k.favoritesTab, k.browseTab, k.historyTab, k.podcastsTab},
 		{k.quit, k.closeFullHelp},
 	}
 }
 
+func timeshiftDurationValidator(s string) error {
+	val, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if val < 0 || val > 24*60 {
+		return fmt.Errorf("timeshift duration out of bonds: %d", val)
+	}
+	return nil
+}
+
+func prebufferDurationValidator(s string) error {
+	val, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if val < 0 || val > 30 {
+		return fmt.Errorf("prebuffer duration out of bonds: %d", val)
+	}
+	return nil
+}
+
+func proxyValidator(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	_, err := config.ParseProxy(s)
+	return err
+}
+
 func bufferDurationValidator(s string) error {
 	val, err := strconv.Atoi(s)
 	if err != nil {